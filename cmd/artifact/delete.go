@@ -16,6 +16,7 @@ var deleteCmd = &cobra.Command{
 	Use:               "delete [flags] <filename...>",
 	Aliases:           []string{"remove", "rm"},
 	Short:             "delete artifacts by their <filename>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "repository:write"},
 	ValidArgsFunction: deleteValidArgs,
 	Args:              cobra.MinimumNArgs(1),
 	RunE:              deleteProcess,
@@ -16,6 +16,7 @@ var downloadCmd = &cobra.Command{
 	Use:               "download [flags] <filename...>",
 	Aliases:           []string{"get", "fetch"},
 	Short:             "download artifacts by their <filename>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "repository"},
 	ValidArgsFunction: downloadValidArgs,
 	Args:              cobra.MinimumNArgs(1),
 	RunE:              getProcess,
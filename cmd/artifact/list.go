@@ -13,10 +13,11 @@ import (
 )
 
 var listCmd = &cobra.Command{
-	Use:   "list",
-	Short: "list all projects",
-	Args:  cobra.NoArgs,
-	RunE:  listProcess,
+	Use:         "list",
+	Short:       "list all projects",
+	Annotations: map[string]string{profile.ScopesAnnotation: "repository"},
+	Args:        cobra.NoArgs,
+	RunE:        listProcess,
 }
 
 var listOptions struct {
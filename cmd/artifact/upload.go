@@ -13,11 +13,12 @@ import (
 )
 
 var uploadCmd = &cobra.Command{
-	Use:     "upload [flags] <filename...>",
-	Aliases: []string{"add", "create"},
-	Short:   "upload artifacts",
-	Args:    cobra.MinimumNArgs(1),
-	RunE:    uploadProcess,
+	Use:         "upload [flags] <filename...>",
+	Aliases:     []string{"add", "create"},
+	Short:       "upload artifacts",
+	Annotations: map[string]string{profile.ScopesAnnotation: "repository:write"},
+	Args:        cobra.MinimumNArgs(1),
+	RunE:        uploadProcess,
 }
 
 var uploadOptions struct {
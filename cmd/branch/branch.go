@@ -54,7 +54,12 @@ var columns = common.Columns[Branch]{
 }
 
 // GetCurrentBranch gets the current branch from Git repository
+//
+// When running in Bitbucket Pipelines, the branch of the build is used as the clone is in detached HEAD
 func GetCurrentBranch() (*Branch, error) {
+	if name := common.GetPipelinesBranch(); len(name) > 0 {
+		return &Branch{Name: name}, nil
+	}
 	repo, err := git.PlainOpenWithOptions(".", &git.PlainOpenOptions{DetectDotGit: true})
 	if err != nil {
 		return nil, err
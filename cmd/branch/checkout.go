@@ -0,0 +1,149 @@
+package branch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// CheckoutOptions describes how a remote branch is checked out locally
+type CheckoutOptions struct {
+	// RemoteName is the name of a configured remote, if empty RemoteURL is fetched with a temporary remote
+	RemoteName string
+	// RemoteURL is the URL of the remote when it is not configured (forks)
+	RemoteURL string
+	// Branch is the name of the branch in the remote
+	Branch string
+	// LocalBranch is the name of the local branch, defaults to Branch
+	LocalBranch string
+	// Detach checks out the commit without creating a local branch
+	Detach bool
+	// Force resets the local branch if it diverged and discards local changes
+	Force bool
+	// Auth is used to fetch from the remote
+	Auth transport.AuthMethod
+}
+
+// Checkout fetches a remote branch and checks it out
+//
+// The local branch tracks the remote branch. When the remote is not configured, the local branch tracks the remote URL.
+// An existing local branch is fast-forwarded, or reset with options.Force if it diverged.
+func Checkout(ctx context.Context, repo *git.Repository, options CheckoutOptions) (hash plumbing.Hash, err error) {
+	log := logger.Must(logger.FromContext(ctx)).Child("branch", "checkout")
+
+	if len(options.Branch) == 0 {
+		return plumbing.ZeroHash, errors.ArgumentMissing.With("branch")
+	}
+	if len(options.LocalBranch) == 0 {
+		options.LocalBranch = options.Branch
+	}
+	if dirty, err := IsWorktreeDirty(repo); err != nil {
+		return plumbing.ZeroHash, err
+	} else if dirty && !options.Force {
+		return plumbing.ZeroHash, errors.Errorf("The worktree has uncommitted changes, commit or stash them first (or use --force to discard them)")
+	}
+
+	if hash, err = FetchBranch(ctx, repo, options.RemoteName, options.RemoteURL, options.Branch, options.Auth); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if options.Detach {
+		log.Infof("Checking out %s in detached HEAD", hash)
+		return hash, worktree.Checkout(&git.CheckoutOptions{Hash: hash, Force: options.Force})
+	}
+
+	localRef := plumbing.NewBranchReferenceName(options.LocalBranch)
+	if local, err := repo.Reference(localRef, true); err == nil && local.Hash() != hash && !options.Force {
+		if behind, err := isAncestor(repo, local.Hash(), hash); err != nil {
+			return plumbing.ZeroHash, err
+		} else if behind {
+			log.Infof("Fast-forwarding local branch %s from %s to %s", options.LocalBranch, local.Hash(), hash)
+		} else if ahead, err := isAncestor(repo, hash, local.Hash()); err != nil {
+			return plumbing.ZeroHash, err
+		} else if ahead {
+			log.Infof("Local branch %s is ahead of %s, keeping it", options.LocalBranch, options.Branch)
+			hash = local.Hash()
+		} else {
+			return plumbing.ZeroHash, errors.Errorf("The local branch %s diverged from %s, use --force to reset it or --branch to use another local branch", options.LocalBranch, options.Branch)
+		}
+	}
+	if err = repo.Storer.SetReference(plumbing.NewHashReference(localRef, hash)); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	remoteName := options.RemoteName
+	if len(remoteName) == 0 {
+		remoteName = options.RemoteURL // git accepts a URL as the remote of a branch
+	}
+	_ = repo.DeleteBranch(options.LocalBranch)
+	if err = repo.CreateBranch(&config.Branch{Name: options.LocalBranch, Remote: remoteName, Merge: plumbing.NewBranchReferenceName(options.Branch)}); err != nil {
+		log.Warnf("Failed to configure the upstream of branch %s: %s", options.LocalBranch, err)
+	}
+
+	log.Infof("Checking out branch %s at %s", options.LocalBranch, hash)
+	return hash, worktree.Checkout(&git.CheckoutOptions{Branch: localRef, Force: options.Force})
+}
+
+// FetchBranch fetches a branch of a remote and gets its commit
+//
+// If remoteName is empty, remoteURL is fetched with a temporary remote (forks). The remote tracking branch of the remote is updated,
+// with a temporary remote the fetched commits stay in the repository but no reference is kept.
+func FetchBranch(ctx context.Context, repo *git.Repository, remoteName, remoteURL, branchName string, auth transport.AuthMethod) (hash plumbing.Hash, err error) {
+	log := logger.Must(logger.FromContext(ctx)).Child("branch", "fetch_branch")
+
+	var remote *git.Remote
+	var trackingRef plumbing.ReferenceName
+	if len(remoteName) > 0 {
+		if remote, err = repo.Remote(remoteName); err != nil {
+			return plumbing.ZeroHash, errors.Join(errors.Errorf("Cannot find remote %s", remoteName), err)
+		}
+		trackingRef = plumbing.NewRemoteReferenceName(remoteName, branchName)
+	} else {
+		if len(remoteURL) == 0 {
+			return plumbing.ZeroHash, errors.ArgumentMissing.With("remote")
+		}
+		log.Infof("Using a temporary remote for %s", remoteURL)
+		if remote, err = repo.CreateRemoteAnonymous(&config.RemoteConfig{Name: "anonymous", URLs: []string{remoteURL}}); err != nil {
+			return plumbing.ZeroHash, err
+		}
+		trackingRef = plumbing.ReferenceName(fmt.Sprintf("refs/bb/%s", branchName))
+		defer func() { _ = repo.Storer.RemoveReference(trackingRef) }()
+	}
+
+	log.Infof("Fetching branch %s into %s", branchName, trackingRef)
+	err = remote.FetchContext(ctx, &git.FetchOptions{
+		RefSpecs: []config.RefSpec{config.RefSpec(fmt.Sprintf("+%s:%s", plumbing.NewBranchReferenceName(branchName), trackingRef))},
+		Auth:     auth,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return plumbing.ZeroHash, errors.Join(errors.Errorf("Failed to fetch branch %s", branchName), err)
+	}
+	fetched, err := repo.Reference(trackingRef, true)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return fetched.Hash(), nil
+}
+
+// isAncestor tells if the commit ancestor is an ancestor of (or the same as) the commit descendant
+func isAncestor(repo *git.Repository, ancestor, descendant plumbing.Hash) (bool, error) {
+	ancestorCommit, err := repo.CommitObject(ancestor)
+	if err != nil {
+		return false, err
+	}
+	descendantCommit, err := repo.CommitObject(descendant)
+	if err != nil {
+		return false, err
+	}
+	return ancestorCommit.IsAncestor(descendantCommit)
+}
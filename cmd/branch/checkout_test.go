@@ -0,0 +1,81 @@
+package branch_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gildas/bitbucket-cli/cmd/branch"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func (suite *BranchSuite) commitFile(repo *git.Repository, filename, content string) plumbing.Hash {
+	worktree, err := repo.Worktree()
+	suite.Require().NoError(err)
+	err = os.WriteFile(filepath.Join(worktree.Filesystem.Root(), filename), []byte(content), 0644)
+	suite.Require().NoError(err)
+	_, err = worktree.Add(filename)
+	suite.Require().NoError(err)
+	hash, err := worktree.Commit("update "+filename, &git.CommitOptions{
+		Author: &object.Signature{Name: "John Doe", Email: "john@acme.com", When: time.Now()},
+	})
+	suite.Require().NoError(err)
+	return hash
+}
+
+func (suite *BranchSuite) TestCanCheckoutRemoteBranch() {
+	ctx := suite.Logger.ToContext(context.Background())
+	upstreamPath := suite.T().TempDir()
+	upstream, err := git.PlainInit(upstreamPath, false)
+	suite.Require().NoError(err)
+	suite.commitFile(upstream, "README.md", "hello")
+	upstreamWorktree, err := upstream.Worktree()
+	suite.Require().NoError(err)
+	err = upstreamWorktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true})
+	suite.Require().NoError(err)
+	featureHash := suite.commitFile(upstream, "feature.txt", "feature")
+
+	local, err := git.PlainClone(suite.T().TempDir(), false, &git.CloneOptions{URL: upstreamPath})
+	suite.Require().NoError(err)
+
+	hash, err := branch.Checkout(ctx, local, branch.CheckoutOptions{RemoteName: "origin", Branch: "feature"})
+	suite.Require().NoError(err, "Failed to check out the branch from the configured remote")
+	suite.Assert().Equal(featureHash, hash)
+	head, err := local.Head()
+	suite.Require().NoError(err)
+	suite.Assert().Equal("feature", head.Name().Short())
+	config, err := local.Branch("feature")
+	suite.Require().NoError(err)
+	suite.Assert().Equal("origin", config.Remote)
+
+	// A fork is fetched with a temporary remote
+	hash, err = branch.Checkout(ctx, local, branch.CheckoutOptions{RemoteURL: upstreamPath, Branch: "feature", LocalBranch: "pr/1"})
+	suite.Require().NoError(err, "Failed to check out the branch from a remote URL")
+	suite.Assert().Equal(featureHash, hash)
+	head, err = local.Head()
+	suite.Require().NoError(err)
+	suite.Assert().Equal("pr/1", head.Name().Short())
+	_, err = local.Reference(plumbing.ReferenceName("refs/bb/feature"), false)
+	suite.Assert().ErrorIs(err, plumbing.ErrReferenceNotFound, "The temporary reference should be removed")
+	references, err := local.References()
+	suite.Require().NoError(err)
+	_ = references.ForEach(func(reference *plumbing.Reference) error {
+		suite.Assert().False(strings.HasPrefix(reference.Name().String(), "refs/bb/"), "The temporary reference %s should be removed", reference.Name())
+		return nil
+	})
+
+	// A dirty worktree is refused
+	localWorktree, err := local.Worktree()
+	suite.Require().NoError(err)
+	err = os.WriteFile(filepath.Join(localWorktree.Filesystem.Root(), "feature.txt"), []byte("local change"), 0644)
+	suite.Require().NoError(err)
+	dirty, err := branch.IsWorktreeDirty(local)
+	suite.Require().NoError(err)
+	suite.Assert().True(dirty)
+	_, err = branch.Checkout(ctx, local, branch.CheckoutOptions{RemoteName: "origin", Branch: "feature", Detach: true})
+	suite.Assert().Error(err, "A dirty worktree should be refused")
+}
@@ -10,10 +10,11 @@ import (
 )
 
 var listCmd = &cobra.Command{
-	Use:   "list",
-	Short: "list all branches",
-	Args:  cobra.NoArgs,
-	RunE:  listProcess,
+	Use:         "list",
+	Short:       "list all branches",
+	Annotations: map[string]string{profile.ScopesAnnotation: "repository"},
+	Args:        cobra.NoArgs,
+	RunE:        listProcess,
 }
 
 var listOptions struct {
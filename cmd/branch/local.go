@@ -0,0 +1,205 @@
+package branch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// OpenLocalRepository opens the git repository of the current folder
+func OpenLocalRepository() (*git.Repository, error) {
+	repo, err := git.PlainOpenWithOptions(".", &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, errors.Join(errors.Errorf("The current folder is not a git repository"), err)
+	}
+	return repo, nil
+}
+
+// IsWorktreeDirty tells if the worktree has uncommitted changes to tracked files
+//
+// Untracked files are ignored, like git checkout does
+func IsWorktreeDirty(repo *git.Repository) (bool, error) {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return false, err
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return false, err
+	}
+	for _, file := range status {
+		if file.Staging == git.Untracked && file.Worktree == git.Untracked {
+			continue
+		}
+		if file.Staging != git.Unmodified || file.Worktree != git.Unmodified {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ResolveLocalBranch gets the commit of a branch of the local repository
+//
+// The remote tracking branch of the given remote is preferred as it is what Bitbucket knows, then the local branch
+func ResolveLocalBranch(repo *git.Repository, remoteName, branchName string) (plumbing.Hash, error) {
+	if len(remoteName) > 0 {
+		if reference, err := repo.Reference(plumbing.NewRemoteReferenceName(remoteName, branchName), true); err == nil {
+			return reference.Hash(), nil
+		}
+	}
+	if reference, err := repo.Reference(plumbing.NewBranchReferenceName(branchName), true); err == nil {
+		return reference.Hash(), nil
+	}
+	return plumbing.ZeroHash, errors.NotFound.With("branch", branchName)
+}
+
+// IsPushed tells if the local branch is at the same commit as its remote tracking branch
+func IsPushed(repo *git.Repository, remoteName, branchName string) bool {
+	local, err := repo.Reference(plumbing.NewBranchReferenceName(branchName), true)
+	if err != nil {
+		return true // nothing to push
+	}
+	remote, err := repo.Reference(plumbing.NewRemoteReferenceName(remoteName, branchName), true)
+	if err != nil {
+		return false
+	}
+	return local.Hash() == remote.Hash()
+}
+
+// GetRemoteBranchHash gets the commit of the remote tracking branch of a branch
+//
+// Returns plumbing.ZeroHash if the branch has no remote tracking branch
+func GetRemoteBranchHash(repo *git.Repository, remoteName, branchName string) plumbing.Hash {
+	if reference, err := repo.Reference(plumbing.NewRemoteReferenceName(remoteName, branchName), true); err == nil {
+		return reference.Hash()
+	}
+	return plumbing.ZeroHash
+}
+
+// Push pushes a local branch to the given remote
+//
+// If the branch has no upstream yet, the remote branch becomes its upstream
+func Push(ctx context.Context, repo *git.Repository, remoteName, branchName string, auth transport.AuthMethod) error {
+	return push(ctx, repo, remoteName, branchName, plumbing.ZeroHash, auth)
+}
+
+// ForcePush pushes a local branch to the given remote, even if the remote branch is not an ancestor (e.g. after a rebase)
+//
+// The push is rejected if the remote branch is not at the lease commit anymore, i.e. someone else pushed to it.
+// The lease is the commit of the remote tracking branch before the local branch was rewritten, see GetRemoteBranchHash.
+// If the lease is plumbing.ZeroHash, the remote branch did not exist and the branch is pushed without force
+func ForcePush(ctx context.Context, repo *git.Repository, remoteName, branchName string, lease plumbing.Hash, auth transport.AuthMethod) error {
+	return push(ctx, repo, remoteName, branchName, lease, auth)
+}
+
+func push(ctx context.Context, repo *git.Repository, remoteName, branchName string, lease plumbing.Hash, auth transport.AuthMethod) error {
+	log := logger.Must(logger.FromContext(ctx)).Child("branch", "push")
+
+	reference := plumbing.NewBranchReferenceName(branchName)
+	options := &git.PushOptions{
+		RemoteName: remoteName,
+		RefSpecs:   []config.RefSpec{config.RefSpec(fmt.Sprintf("%s:%s", reference, reference))},
+		Auth:       auth,
+	}
+	if !lease.IsZero() {
+		options.ForceWithLease = &git.ForceWithLease{RefName: reference, Hash: lease}
+	}
+	log.Infof("Pushing branch %s to %s (lease: %s)", branchName, remoteName, lease)
+	err := repo.PushContext(ctx, options)
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		if !lease.IsZero() && strings.Contains(err.Error(), "non-fast-forward") {
+			return errors.Join(errors.Errorf("Branch %s on %s is not at %s anymore, someone else pushed to it, fetch and rebase again", branchName, remoteName, lease), err)
+		}
+		return errors.Join(errors.Errorf("Failed to push branch %s to %s", branchName, remoteName), err)
+	}
+	if _, err := repo.Branch(branchName); errors.Is(err, git.ErrBranchNotFound) {
+		log.Infof("Setting the upstream of branch %s to %s/%s", branchName, remoteName, branchName)
+		if err = repo.CreateBranch(&config.Branch{Name: branchName, Remote: remoteName, Merge: reference}); err != nil {
+			log.Warnf("Failed to set the upstream of branch %s: %s", branchName, err)
+		}
+	}
+	return nil
+}
+
+// Fetch fetches the branches of the given remote
+func Fetch(ctx context.Context, repo *git.Repository, remoteName string, auth transport.AuthMethod) error {
+	log := logger.Must(logger.FromContext(ctx)).Child("branch", "fetch")
+
+	log.Infof("Fetching %s", remoteName)
+	err := repo.FetchContext(ctx, &git.FetchOptions{RemoteName: remoteName, Auth: auth})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return errors.Join(errors.Errorf("Failed to fetch %s", remoteName), err)
+	}
+	return nil
+}
+
+// Rebase rebases a local branch with the git command, as go-git cannot rebase
+//
+// If upstream is empty, the branch is rebased onto onto (git rebase onto branch),
+// otherwise the commits of the branch after upstream are replayed onto onto (git rebase --onto onto upstream branch).
+// The branch is checked out when the rebase is done.
+func Rebase(ctx context.Context, branchName, onto, upstream string) error {
+	log := logger.Must(logger.FromContext(ctx)).Child("branch", "rebase")
+
+	args := []string{"rebase", onto, branchName}
+	if len(upstream) > 0 {
+		args = []string{"rebase", "--onto", onto, upstream, branchName}
+	}
+	log.Infof("Running git %s", strings.Join(args, " "))
+	command := exec.CommandContext(ctx, "git", args...)
+	command.Stdout = os.Stderr
+	command.Stderr = os.Stderr
+	if err := command.Run(); err != nil {
+		return errors.Join(errors.Errorf("Failed to rebase branch %s onto %s, resolve the conflicts and run git rebase --continue (or git rebase --abort)", branchName, onto), err)
+	}
+	return nil
+}
+
+// Switch checks out the given local branch
+func Switch(repo *git.Repository, branchName string) error {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	return worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(branchName)})
+}
+
+// GetCommitsSince gets the commits reachable from head that are not reachable from base, newest first
+//
+// This is the equivalent of git log base..head
+func GetCommitsSince(repo *git.Repository, head, base plumbing.Hash) (commits []*object.Commit, err error) {
+	headCommit, err := repo.CommitObject(head)
+	if err != nil {
+		return nil, err
+	}
+	baseCommit, err := repo.CommitObject(base)
+	if err != nil {
+		return nil, err
+	}
+	mergeBases, err := headCommit.MergeBase(baseCommit)
+	if err != nil {
+		return nil, err
+	}
+	ignore := make([]plumbing.Hash, 0, len(mergeBases))
+	for _, mergeBase := range mergeBases {
+		ignore = append(ignore, mergeBase.Hash)
+	}
+	err = object.NewCommitPreorderIter(headCommit, nil, ignore).ForEach(func(commit *object.Commit) error {
+		if !slices.Contains(ignore, commit.Hash) {
+			commits = append(commits, commit)
+		}
+		return nil
+	})
+	return commits, err
+}
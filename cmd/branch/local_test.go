@@ -0,0 +1,98 @@
+package branch_test
+
+import (
+	"context"
+
+	"github.com/gildas/bitbucket-cli/cmd/branch"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func (suite *BranchSuite) TestCanPushLocalBranch() {
+	ctx := suite.Logger.ToContext(context.Background())
+	upstreamPath := suite.T().TempDir()
+	upstream, err := git.PlainInit(upstreamPath, false)
+	suite.Require().NoError(err)
+	suite.commitFile(upstream, "README.md", "hello")
+	mainBranch, err := upstream.Head()
+	suite.Require().NoError(err)
+
+	local, err := git.PlainClone(suite.T().TempDir(), false, &git.CloneOptions{URL: upstreamPath})
+	suite.Require().NoError(err)
+	worktree, err := local.Worktree()
+	suite.Require().NoError(err)
+	err = worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true})
+	suite.Require().NoError(err)
+	first := suite.commitFile(local, "feature.txt", "feature")
+	second := suite.commitFile(local, "feature.txt", "feature, again")
+	suite.Assert().False(branch.IsPushed(local, "origin", "feature"))
+
+	base, err := branch.ResolveLocalBranch(local, "origin", mainBranch.Name().Short())
+	suite.Require().NoError(err)
+	suite.Assert().Equal(mainBranch.Hash(), base)
+	commits, err := branch.GetCommitsSince(local, second, base)
+	suite.Require().NoError(err)
+	suite.Require().Len(commits, 2)
+	suite.Assert().Equal(second, commits[0].Hash)
+	suite.Assert().Equal(first, commits[1].Hash)
+
+	err = branch.Push(ctx, local, "origin", "feature", nil)
+	suite.Require().NoError(err, "Failed to push the branch")
+	pushed, err := upstream.Reference(plumbing.NewBranchReferenceName("feature"), true)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(second, pushed.Hash())
+	suite.Assert().True(branch.IsPushed(local, "origin", "feature"))
+	config, err := local.Branch("feature")
+	suite.Require().NoError(err, "The pushed branch should have an upstream")
+	suite.Assert().Equal("origin", config.Remote)
+
+	err = branch.Push(ctx, local, "origin", "feature", nil)
+	suite.Assert().NoError(err, "Pushing an up-to-date branch should not fail")
+}
+
+func (suite *BranchSuite) TestShouldNotForcePushOverOtherCommits() {
+	ctx := suite.Logger.ToContext(context.Background())
+	upstreamPath := suite.T().TempDir()
+	upstream, err := git.PlainInit(upstreamPath, false)
+	suite.Require().NoError(err)
+	base := suite.commitFile(upstream, "README.md", "hello")
+
+	local, err := git.PlainClone(suite.T().TempDir(), false, &git.CloneOptions{URL: upstreamPath})
+	suite.Require().NoError(err)
+	worktree, err := local.Worktree()
+	suite.Require().NoError(err)
+	err = worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true})
+	suite.Require().NoError(err)
+	suite.commitFile(local, "feature.txt", "feature")
+	suite.Require().NoError(branch.Push(ctx, local, "origin", "feature", nil))
+	lease := branch.GetRemoteBranchHash(local, "origin", "feature")
+	suite.Require().False(lease.IsZero(), "The pushed branch should have a remote tracking branch")
+
+	// Someone else pushes to the branch
+	other, err := git.PlainClone(suite.T().TempDir(), false, &git.CloneOptions{URL: upstreamPath})
+	suite.Require().NoError(err)
+	otherWorktree, err := other.Worktree()
+	suite.Require().NoError(err)
+	err = otherWorktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Hash: lease, Create: true})
+	suite.Require().NoError(err)
+	theirs := suite.commitFile(other, "other.txt", "other")
+	suite.Require().NoError(branch.Push(ctx, other, "origin", "feature", nil))
+
+	// The local branch is rewritten, like after a rebase
+	err = worktree.Reset(&git.ResetOptions{Commit: base, Mode: git.HardReset})
+	suite.Require().NoError(err)
+	rewritten := suite.commitFile(local, "feature.txt", "feature, rewritten")
+
+	err = branch.ForcePush(ctx, local, "origin", "feature", lease, nil)
+	suite.Require().Error(err, "The force push should be rejected as the remote branch moved")
+	pushed, err := upstream.Reference(plumbing.NewBranchReferenceName("feature"), true)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(theirs, pushed.Hash(), "The commits of the other clone should still be on the remote branch")
+
+	suite.Require().NoError(branch.Fetch(ctx, local, "origin", nil))
+	err = branch.ForcePush(ctx, local, "origin", "feature", branch.GetRemoteBranchHash(local, "origin", "feature"), nil)
+	suite.Require().NoError(err, "The force push should succeed with the current lease")
+	pushed, err = upstream.Reference(plumbing.NewBranchReferenceName("feature"), true)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(rewritten, pushed.Hash())
+}
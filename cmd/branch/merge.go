@@ -0,0 +1,310 @@
+package branch
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gildas/go-errors"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// TrialMerge is the result of merging two commits in memory
+type TrialMerge struct {
+	Strategy       string          `json:"strategy"`
+	Destination    string          `json:"destination"`
+	Source         string          `json:"source"`
+	MergeBase      string          `json:"merge_base"`
+	CanFastForward bool            `json:"can_fast_forward"`
+	Conflicts      []MergeConflict `json:"conflicts"`
+}
+
+// MergeConflict is a file that cannot be merged
+type MergeConflict struct {
+	Path   string         `json:"path"`
+	Reason string         `json:"reason"` // content, add/add, modify/delete, delete/modify, or binary
+	Hunks  []ConflictHunk `json:"hunks,omitempty"`
+}
+
+// ConflictHunk is a part of a file that was changed differently by both branches
+//
+// The line numbers start at 1, the lines keep their end of line
+type ConflictHunk struct {
+	BaseLine        int      `json:"base_line"`
+	DestinationLine int      `json:"destination_line"`
+	SourceLine      int      `json:"source_line"`
+	Base            []string `json:"base"`
+	Destination     []string `json:"destination"`
+	Source          []string `json:"source"`
+}
+
+// mergeChange is a change of one side of a 3-way merge: the lines base[start:end] are replaced by lines
+type mergeChange struct {
+	start, end int
+	lines      []string
+	source     bool
+}
+
+// PredictMerge merges the source commit into the destination commit in memory, without touching the worktree or the references
+//
+// Both commits must already be in the repository, e.g. with FetchBranch, which updates the remote tracking branches.
+// The files changed by both commits since their merge base are merged line by line, like git does.
+// When there are several merge bases, the first one is used.
+// With the fast_forward strategy, the destination must be an ancestor of the source.
+func PredictMerge(repo *git.Repository, destination, source plumbing.Hash, strategy string) (*TrialMerge, error) {
+	destinationCommit, err := repo.CommitObject(destination)
+	if err != nil {
+		return nil, errors.Join(errors.Errorf("Cannot find the destination commit %s", destination), err)
+	}
+	sourceCommit, err := repo.CommitObject(source)
+	if err != nil {
+		return nil, errors.Join(errors.Errorf("Cannot find the source commit %s", source), err)
+	}
+	mergeBases, err := destinationCommit.MergeBase(sourceCommit)
+	if err != nil {
+		return nil, err
+	}
+	if len(mergeBases) == 0 {
+		return nil, errors.Errorf("The commits %s and %s have no common ancestor", destination, source)
+	}
+	base := mergeBases[0]
+	result := &TrialMerge{
+		Strategy:       strategy,
+		Destination:    destination.String(),
+		Source:         source.String(),
+		MergeBase:      base.Hash.String(),
+		CanFastForward: base.Hash == destination,
+		Conflicts:      []MergeConflict{},
+	}
+	if base.Hash == destination || base.Hash == source {
+		return result, nil // one branch contains the other
+	}
+
+	baseTree, err := base.Tree()
+	if err != nil {
+		return nil, err
+	}
+	destinationChanges, err := getTreeChanges(baseTree, destinationCommit)
+	if err != nil {
+		return nil, err
+	}
+	sourceChanges, err := getTreeChanges(baseTree, sourceCommit)
+	if err != nil {
+		return nil, err
+	}
+
+	for path, sourceChange := range sourceChanges {
+		destinationChange, found := destinationChanges[path]
+		if !found || destinationChange.To.TreeEntry.Hash == sourceChange.To.TreeEntry.Hash {
+			continue // changed on one side only, or changed the same way
+		}
+		conflict, err := mergeFile(repo, path, destinationChange, sourceChange)
+		if err != nil {
+			return nil, err
+		}
+		if conflict != nil {
+			result.Conflicts = append(result.Conflicts, *conflict)
+		}
+	}
+	sort.Slice(result.Conflicts, func(i, j int) bool { return result.Conflicts[i].Path < result.Conflicts[j].Path })
+	return result, nil
+}
+
+// IsClean tells if the merge can be done without conflicts with its strategy
+func (merge TrialMerge) IsClean() bool {
+	if merge.Strategy == "fast_forward" && !merge.CanFastForward {
+		return false
+	}
+	return len(merge.Conflicts) == 0
+}
+
+// getTreeChanges gets the files changed between the base tree and the tree of the commit, by path
+func getTreeChanges(baseTree *object.Tree, commit *object.Commit) (map[string]*object.Change, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := object.DiffTree(baseTree, tree)
+	if err != nil {
+		return nil, errors.Join(errors.Errorf("Cannot get the changes of commit %s", commit.Hash), err)
+	}
+	byPath := make(map[string]*object.Change, len(changes))
+	for _, change := range changes {
+		path := change.To.Name
+		if len(path) == 0 {
+			path = change.From.Name
+		}
+		byPath[path] = change
+	}
+	return byPath, nil
+}
+
+// mergeFile merges a file changed by both sides, returns nil if there is no conflict
+func mergeFile(repo *git.Repository, path string, destination, source *object.Change) (*MergeConflict, error) {
+	switch {
+	case destination.To.TreeEntry.Hash.IsZero():
+		return &MergeConflict{Path: path, Reason: "delete/modify"}, nil
+	case source.To.TreeEntry.Hash.IsZero():
+		return &MergeConflict{Path: path, Reason: "modify/delete"}, nil
+	}
+
+	base, err := readBlob(repo, source.From.TreeEntry.Hash)
+	if err != nil {
+		return nil, err
+	}
+	destinationContent, err := readBlob(repo, destination.To.TreeEntry.Hash)
+	if err != nil {
+		return nil, err
+	}
+	sourceContent, err := readBlob(repo, source.To.TreeEntry.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if isBinary(base) || isBinary(destinationContent) || isBinary(sourceContent) {
+		return &MergeConflict{Path: path, Reason: "binary"}, nil
+	}
+
+	hunks := MergeLines(splitLines(string(base)), splitLines(string(destinationContent)), splitLines(string(sourceContent)))
+	if len(hunks) == 0 {
+		return nil, nil
+	}
+	reason := "content"
+	if source.From.TreeEntry.Hash.IsZero() {
+		reason = "add/add"
+	}
+	return &MergeConflict{Path: path, Reason: reason, Hunks: hunks}, nil
+}
+
+// MergeLines merges the lines changed by the destination and the source since the base, and returns the conflicting hunks
+//
+// Like git, changes of both sides that overlap or touch each other conflict, unless they are the same.
+func MergeLines(base, destination, source []string) (hunks []ConflictHunk) {
+	changes := append(getLineChanges(base, destination, false), getLineChanges(base, source, true)...)
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].start != changes[j].start {
+			return changes[i].start < changes[j].start
+		}
+		return changes[i].end < changes[j].end
+	})
+
+	destinationDelta, sourceDelta := 0, 0
+	for index := 0; index < len(changes); {
+		start, end := changes[index].start, changes[index].end
+		next := index + 1
+		for next < len(changes) && changes[next].start <= end {
+			end = max(end, changes[next].end)
+			next++
+		}
+		region := changes[index:next]
+		index = next
+
+		var destinationRegion, sourceRegion []mergeChange
+		for _, change := range region {
+			if change.source {
+				sourceRegion = append(sourceRegion, change)
+			} else {
+				destinationRegion = append(destinationRegion, change)
+			}
+		}
+		destinationLines := applyLineChanges(base, start, end, destinationRegion)
+		sourceLines := applyLineChanges(base, start, end, sourceRegion)
+		if len(destinationRegion) > 0 && len(sourceRegion) > 0 && !slices.Equal(destinationLines, sourceLines) {
+			hunks = append(hunks, ConflictHunk{
+				BaseLine:        start + 1,
+				DestinationLine: start + destinationDelta + 1,
+				SourceLine:      start + sourceDelta + 1,
+				Base:            base[start:end],
+				Destination:     destinationLines,
+				Source:          sourceLines,
+			})
+		}
+		destinationDelta += len(destinationLines) - (end - start)
+		sourceDelta += len(sourceLines) - (end - start)
+	}
+	return hunks
+}
+
+// getLineChanges gets the changes to go from the base lines to the side lines
+func getLineChanges(base, side []string, source bool) (changes []mergeChange) {
+	var current *mergeChange
+	index := 0
+
+	for _, difference := range diff.Do(strings.Join(base, ""), strings.Join(side, "")) {
+		lines := splitLines(difference.Text)
+		switch difference.Type {
+		case diffmatchpatch.DiffEqual:
+			if current != nil {
+				changes = append(changes, *current)
+				current = nil
+			}
+			index += len(lines)
+		case diffmatchpatch.DiffDelete:
+			if current == nil {
+				current = &mergeChange{start: index, end: index, source: source}
+			}
+			current.end += len(lines)
+			index += len(lines)
+		case diffmatchpatch.DiffInsert:
+			if current == nil {
+				current = &mergeChange{start: index, end: index, source: source}
+			}
+			current.lines = append(current.lines, lines...)
+		}
+	}
+	if current != nil {
+		changes = append(changes, *current)
+	}
+	return changes
+}
+
+// applyLineChanges applies the changes of one side to the base lines from start to end
+func applyLineChanges(base []string, start, end int, changes []mergeChange) []string {
+	lines := []string{}
+	position := start
+	for _, change := range changes {
+		lines = append(lines, base[position:change.start]...)
+		lines = append(lines, change.lines...)
+		position = change.end
+	}
+	return append(lines, base[position:end]...)
+}
+
+// splitLines splits a text in lines, keeping their end of line
+func splitLines(text string) []string {
+	if len(text) == 0 {
+		return []string{}
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// readBlob reads the content of a blob, an empty hash is an empty content
+func readBlob(repo *git.Repository, hash plumbing.Hash) ([]byte, error) {
+	if hash.IsZero() {
+		return []byte{}, nil
+	}
+	blob, err := repo.BlobObject(hash)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// isBinary tells if the content looks binary, like git does: it contains a NUL byte in its first 8000 bytes
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), 8000)], 0) >= 0
+}
@@ -0,0 +1,72 @@
+package branch_test
+
+import (
+	"github.com/gildas/bitbucket-cli/cmd/branch"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func (suite *BranchSuite) TestCanMergeLines() {
+	base := []string{"one\n", "two\n", "three\n", "four\n", "five\n"}
+
+	// Changes far from each other merge cleanly
+	hunks := branch.MergeLines(base, []string{"ONE\n", "two\n", "three\n", "four\n", "five\n"}, []string{"one\n", "two\n", "three\n", "four\n", "FIVE\n"})
+	suite.Assert().Empty(hunks)
+
+	// The same change on both sides is not a conflict
+	hunks = branch.MergeLines(base, []string{"one\n", "TWO\n", "three\n", "four\n", "five\n"}, []string{"one\n", "TWO\n", "three\n", "four\n", "five\n"})
+	suite.Assert().Empty(hunks)
+
+	// Different changes of the same lines conflict
+	hunks = branch.MergeLines(base,
+		[]string{"zero\n", "one\n", "two\n", "3\n", "four\n", "five\n"},
+		[]string{"one\n", "two\n", "THREE\n", "four\n", "five\n"},
+	)
+	suite.Require().Len(hunks, 1)
+	suite.Assert().Equal(3, hunks[0].BaseLine)
+	suite.Assert().Equal(4, hunks[0].DestinationLine, "The line added on top moves the hunk in the destination")
+	suite.Assert().Equal(3, hunks[0].SourceLine)
+	suite.Assert().Equal([]string{"three\n"}, hunks[0].Base)
+	suite.Assert().Equal([]string{"3\n"}, hunks[0].Destination)
+	suite.Assert().Equal([]string{"THREE\n"}, hunks[0].Source)
+}
+
+func (suite *BranchSuite) TestCanPredictMergeConflicts() {
+	repo, err := git.PlainInit(suite.T().TempDir(), false)
+	suite.Require().NoError(err)
+	suite.commitFile(repo, "main.go", "package main\n\nfunc main() {\n\tprintln(\"hello\")\n}\n")
+	suite.commitFile(repo, "README.md", "# Title\n")
+	head, err := repo.Head()
+	suite.Require().NoError(err)
+	worktree, err := repo.Worktree()
+	suite.Require().NoError(err)
+
+	destination := suite.commitFile(repo, "main.go", "package main\n\nfunc main() {\n\tprintln(\"hello, world\")\n}\n")
+	err = worktree.Checkout(&git.CheckoutOptions{Hash: head.Hash(), Branch: plumbing.NewBranchReferenceName("feature"), Create: true})
+	suite.Require().NoError(err)
+	suite.commitFile(repo, "README.md", "# Title\n\nSome documentation\n")
+	clean := suite.commitFile(repo, "other.go", "package main\n")
+	conflicting := suite.commitFile(repo, "main.go", "package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n")
+
+	result, err := branch.PredictMerge(repo, destination, clean, "merge_commit")
+	suite.Require().NoError(err)
+	suite.Assert().True(result.IsClean())
+	suite.Assert().Equal(head.Hash().String(), result.MergeBase)
+
+	result, err = branch.PredictMerge(repo, destination, conflicting, "squash")
+	suite.Require().NoError(err)
+	suite.Assert().False(result.IsClean())
+	suite.Require().Len(result.Conflicts, 1)
+	suite.Assert().Equal("main.go", result.Conflicts[0].Path)
+	suite.Assert().Equal("content", result.Conflicts[0].Reason)
+	suite.Require().Len(result.Conflicts[0].Hunks, 1)
+	suite.Assert().Equal(4, result.Conflicts[0].Hunks[0].DestinationLine)
+	suite.Assert().Equal([]string{"\tprintln(\"hi\")\n"}, result.Conflicts[0].Hunks[0].Source)
+
+	result, err = branch.PredictMerge(repo, destination, clean, "fast_forward")
+	suite.Require().NoError(err)
+	suite.Assert().False(result.IsClean(), "The destination moved, it cannot be fast-forwarded")
+	result, err = branch.PredictMerge(repo, head.Hash(), clean, "fast_forward")
+	suite.Require().NoError(err)
+	suite.Assert().True(result.IsClean())
+}
@@ -0,0 +1,206 @@
+package browse
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gildas/bitbucket-cli/cmd/branch"
+	"github.com/gildas/bitbucket-cli/cmd/commit"
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/bitbucket-cli/cmd/issue"
+	"github.com/gildas/bitbucket-cli/cmd/pipeline"
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/gildas/bitbucket-cli/cmd/pullrequest"
+	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/spf13/cobra"
+)
+
+// Command represents this folder's command
+var Command = &cobra.Command{
+	Use:   "browse [flags] [entity] [id]",
+	Short: "open an entity in the Bitbucket web UI",
+	Long: `Open an entity in the Bitbucket web UI.
+The entity can be one of: repository, pullrequest, issue, pipeline, commit, branch.
+Without an entity, the pullrequest of the current branch is opened, or the current branch, or the repository.
+Without an id, the current pullrequest, branch, or commit is used.`,
+	Annotations:       map[string]string{profile.ScopesAnnotation: "repository"},
+	Args:              cobra.MaximumNArgs(2),
+	ValidArgsFunction: browseValidArgs,
+	RunE:              browseProcess,
+}
+
+var browseOptions struct {
+	Print bool
+}
+
+// entities are the entities that can be browsed with their aliases
+var entities = map[string][]string{
+	"repository":  {"repo"},
+	"pullrequest": {"pr", "pull-request"},
+	"issue":       {},
+	"pipeline":    {"pp"},
+	"commit":      {},
+	"branch":      {},
+}
+
+func init() {
+	Command.Flags().BoolVar(&browseOptions.Print, "print", false, "Print the URL instead of opening it in the web browser (e.g. over SSH or in WSL)")
+}
+
+func browseValidArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names := make([]string, 0, len(entities))
+	for name := range entities {
+		names = append(names, name)
+	}
+	return common.FilterValidArgs(names, args, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func browseProcess(cmd *cobra.Command, args []string) (err error) {
+	log := logger.Must(logger.FromContext(cmd.Context())).Child("browse", "browse")
+	ctx := log.ToContext(cmd.Context())
+
+	profile, err := profile.GetProfileFromCommand(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	entity := ""
+	if len(args) > 0 {
+		if entity = findEntity(args[0]); len(entity) == 0 {
+			return errors.ArgumentInvalid.With("entity", args[0])
+		}
+		args = args[1:]
+	}
+
+	if entity == "repository" && len(args) > 0 {
+		repo, err := repository.GetRepositoryBySlugOrID(ctx, cmd, args[0])
+		if err != nil {
+			return errors.Join(errors.Errorf("Failed to get repository %s", args[0]), err)
+		}
+		return common.Browse(ctx, cmd, repo.Links.HTML, browseOptions.Print)
+	}
+
+	repo, err := repository.GetRepository(ctx, cmd)
+	if err != nil {
+		return errors.Join(errors.Errorf("Cannot find the repository to browse"), err)
+	}
+	link, err := getLink(ctx, cmd, profile, repo, entity, args)
+	if err != nil {
+		return err
+	}
+	return common.Browse(ctx, cmd, link, browseOptions.Print)
+}
+
+// findEntity finds the entity name from its name or one of its aliases
+func findEntity(name string) string {
+	for entity, aliases := range entities {
+		if strings.EqualFold(entity, name) {
+			return entity
+		}
+		for _, alias := range aliases {
+			if strings.EqualFold(alias, name) {
+				return entity
+			}
+		}
+	}
+	return ""
+}
+
+// getLink gets the web link of the entity
+//
+// When no entity is given, the pullrequest of the current branch is used, then the current branch, then the repository
+func getLink(ctx context.Context, cmd *cobra.Command, profile *profile.Profile, repo *repository.Repository, entity string, args []string) (*common.Link, error) {
+	log := logger.Must(logger.FromContext(ctx)).Child("browse", "link")
+
+	if repo.Links.HTML == nil {
+		return nil, errors.NotFound.With("web page of repository", repo.FullName)
+	}
+	repositoryLink := repo.Links.HTML.HREF
+
+	switch entity {
+	case "":
+		current, err := branch.GetCurrentBranch()
+		if err != nil {
+			log.Debugf("No current branch, browsing repository %s: %s", repo.FullName, err)
+			return repo.Links.HTML, nil
+		}
+		if pullrequest, err := pullrequest.GetOpenPullRequestForBranch(ctx, cmd, repo, current.Name); err == nil {
+			log.Infof("Browsing pullrequest %d of branch %s", pullrequest.ID, current.Name)
+			return pullrequest.Links.HTML, nil
+		} else if !errors.Is(err, errors.NotFound) {
+			return nil, err
+		}
+		if current.Name == repo.MainBranch {
+			return repo.Links.HTML, nil
+		}
+		log.Infof("Browsing branch %s", current.Name)
+		return &common.Link{HREF: *repositoryLink.JoinPath("branch", current.Name)}, nil
+	case "repository":
+		return repo.Links.HTML, nil
+	case "pullrequest":
+		var pullRequestID string
+		var err error
+		if len(args) == 0 {
+			if current, err := branch.GetCurrentBranch(); err == nil {
+				if pullrequest, err := pullrequest.GetOpenPullRequestForBranch(ctx, cmd, repo, current.Name); err == nil {
+					return pullrequest.Links.HTML, nil
+				}
+			}
+		}
+		if pullRequestID, err = pullrequest.GetPullRequestIDFromArgs(ctx, cmd, repo, args); err != nil {
+			return nil, err
+		}
+		var pullrequest pullrequest.PullRequest
+		if err := profile.Get(ctx, cmd, repo.GetPath("pullrequests", pullRequestID), &pullrequest); err != nil {
+			return nil, errors.Join(errors.Errorf("Failed to get pullrequest %s", pullRequestID), err)
+		}
+		return pullrequest.Links.HTML, nil
+	case "issue":
+		if len(args) == 0 {
+			return &common.Link{HREF: *repositoryLink.JoinPath("issues")}, nil
+		}
+		var issue issue.Issue
+		if err := profile.Get(ctx, cmd, repo.GetPath("issues", args[0]), &issue); err != nil {
+			return nil, errors.Join(errors.Errorf("Failed to get issue %s", args[0]), err)
+		}
+		return issue.Links.HTML, nil
+	case "pipeline":
+		if len(args) == 0 {
+			return &common.Link{HREF: *repositoryLink.JoinPath("pipelines")}, nil
+		}
+		var pipeline pipeline.Pipeline
+		if err := profile.Get(ctx, cmd, repo.GetPath("pipelines", args[0]), &pipeline); err != nil {
+			return nil, errors.Join(errors.Errorf("Failed to get pipeline %s", args[0]), err)
+		}
+		if pipeline.Repository.Links.HTML == nil {
+			pipeline.Repository.Links.HTML = repo.Links.HTML
+		}
+		return pipeline.GetHTMLLink(), nil
+	case "commit":
+		hash := ""
+		if len(args) > 0 {
+			hash = args[0]
+		} else if latest, err := commit.GetLatestCommit(ctx, cmd); err == nil {
+			hash = latest.Hash
+		} else {
+			return nil, errors.Join(errors.Errorf("Cannot find the current commit, please provide its hash"), err)
+		}
+		return &common.Link{HREF: *repositoryLink.JoinPath("commits", hash)}, nil
+	case "branch":
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
+		} else if current, err := branch.GetCurrentBranch(); err == nil {
+			name = current.Name
+		} else {
+			return nil, errors.Join(errors.Errorf("Cannot find the current branch, please provide its name"), err)
+		}
+		return &common.Link{HREF: *repositoryLink.JoinPath("branch", name)}, nil
+	}
+	return nil, errors.ArgumentInvalid.With("entity", entity)
+}
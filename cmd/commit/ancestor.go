@@ -13,6 +13,7 @@ import (
 var ancestorCmd = &cobra.Command{
 	Use:               "ancestor <commit-hash> <commit-hash>",
 	Short:             "show the ancestor commit of two commits",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "repository"},
 	Args:              cobra.ExactArgs(2),
 	ValidArgsFunction: validAncestorArgs,
 	RunE:              ancestorProcess,
@@ -102,3 +102,30 @@ func (suite *CommitSuite) TestCanMarshalCommitReference() {
 	suite.Require().NoError(err)
 	suite.Assert().JSONEq(string(expected), string(data))
 }
+
+func (suite *CommitSuite) TestCanUnmarshalStatus() {
+	payload := `{
+		"type": "build",
+		"key": "PIPELINE-1234",
+		"name": "Pipeline #42 for main",
+		"state": "SUCCESSFUL",
+		"url": "https://bitbucket.org/workspace/repo/addon/pipelines/home#!/results/42",
+		"created_on": "2024-01-15T10:30:00.000000+00:00",
+		"updated_on": "2024-01-15T10:32:30.000000+00:00"
+	}`
+	var status commit.Status
+	err := json.Unmarshal([]byte(payload), &status)
+	suite.Require().NoError(err)
+	suite.Assert().Equal("PIPELINE-1234", status.Key)
+	suite.Assert().True(status.IsFinished())
+	suite.Assert().True(status.IsSuccessful())
+	suite.Assert().Equal(150*time.Second, status.GetDuration())
+}
+
+func (suite *CommitSuite) TestCanGetStatusesState() {
+	suite.Assert().Equal("", commit.Statuses{}.State())
+	suite.Assert().Equal(commit.StatusSuccessful, commit.Statuses{{State: "SUCCESSFUL"}, {State: "SUCCESSFUL"}}.State())
+	suite.Assert().Equal(commit.StatusInProgress, commit.Statuses{{State: "SUCCESSFUL"}, {State: "INPROGRESS"}}.State())
+	suite.Assert().Equal(commit.StatusFailed, commit.Statuses{{State: "INPROGRESS"}, {State: "FAILED"}}.State())
+	suite.Assert().Equal(commit.StatusFailed, commit.Statuses{{State: "STOPPED"}}.State())
+}
@@ -2,12 +2,11 @@ package commit
 
 import (
 	"fmt"
-	"io"
-	"os"
 
 	"github.com/gildas/bitbucket-cli/cmd/common"
 	"github.com/gildas/bitbucket-cli/cmd/profile"
 	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/gildas/go-errors"
 	"github.com/gildas/go-logger"
 	"github.com/spf13/cobra"
 )
@@ -15,19 +14,18 @@ import (
 var diffCmd = &cobra.Command{
 	Use:               "diff [flags] <commit-hash> [<commit-hash>]",
 	Short:             "show the diff of a commit or between two commits",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "repository"},
 	Args:              cobra.RangeArgs(1, 2),
 	ValidArgsFunction: validDiffArgs,
 	RunE:              diffProcess,
 }
 
-var diffOptions struct {
-	Stat bool
-}
+var diffOptions common.DiffOptions
 
 func init() {
 	Command.AddCommand(diffCmd)
 
-	diffCmd.Flags().BoolVar(&diffOptions.Stat, "stat", false, "show only the diffstat")
+	diffOptions.AddFlags(diffCmd)
 }
 
 func validDiffArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -73,11 +71,17 @@ func diffProcess(cmd *cobra.Command, args []string) error {
 		uripath = repository.GetPath("diffstat", spec)
 	}
 
+	query, err := diffOptions.GetQuery()
+	if err != nil {
+		return errors.Join(errors.Errorf("Path patterns are not supported with --stat"), err)
+	}
+	if len(query) > 0 {
+		uripath += "?" + query.Encode()
+	}
+
 	diff, err := profile.GetRaw(log.ToContext(cmd.Context()), cmd, uripath)
 	if err != nil {
 		return err
 	}
-
-	_, err = io.Copy(os.Stdout, diff)
-	return err
+	return diffOptions.Print(log.ToContext(cmd.Context()), diff)
 }
@@ -15,6 +15,7 @@ var getCmd = &cobra.Command{
 	Use:               "get [flags] <commit-hash>",
 	Aliases:           []string{"show", "describe"},
 	Short:             "get a commit",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "repository"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: getValidAdrgs,
 	RunE:              getProcess,
@@ -22,6 +23,7 @@ var getCmd = &cobra.Command{
 
 var getOptions struct {
 	Columns *flags.EnumSliceFlag
+	Web     bool
 }
 
 func init() {
@@ -29,6 +31,7 @@ func init() {
 
 	getOptions.Columns = flags.NewEnumSliceFlag(columns.Columns()...)
 	getCmd.Flags().Var(getOptions.Columns, "columns", "Comma-separated list of columns to display")
+	getCmd.Flags().BoolVar(&getOptions.Web, "web", false, "Open the commit in the web browser instead of displaying it")
 	_ = getCmd.RegisterFlagCompletionFunc(getOptions.Columns.CompletionFunc("columns"))
 }
 
@@ -86,5 +89,8 @@ func getProcess(cmd *cobra.Command, args []string) (err error) {
 		return
 	}
 	log.Record("commit", commits.Values[0]).Debugf("Commit %s retrieved successfully", commit)
+	if getOptions.Web {
+		return common.Browse(log.ToContext(cmd.Context()), cmd, commits.Values[0].Links.HTML, false)
+	}
 	return profile.Print(cmd.Context(), cmd, commits.Values[0])
 }
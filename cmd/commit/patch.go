@@ -15,6 +15,7 @@ import (
 var patchCmd = &cobra.Command{
 	Use:               "patch <commit-hash> <commit-hash>",
 	Short:             "show the patch between two commits",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "repository"},
 	Args:              cobra.ExactArgs(2),
 	ValidArgsFunction: validPatchArgs,
 	RunE:              patchProcess,
@@ -0,0 +1,159 @@
+package commit
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/gildas/go-core"
+	"github.com/spf13/cobra"
+)
+
+// Status is the status of a build (or any other check) reported on a commit
+type Status struct {
+	Type        string       `json:"type"                  mapstructure:"type"`
+	Key         string       `json:"key"                   mapstructure:"key"`
+	Name        string       `json:"name,omitempty"        mapstructure:"name"`
+	State       string       `json:"state"                 mapstructure:"state"`
+	Description string       `json:"description,omitempty" mapstructure:"description"`
+	URL         string       `json:"url,omitempty"         mapstructure:"url"`
+	RefName     string       `json:"refname,omitempty"     mapstructure:"refname"`
+	CreatedOn   time.Time    `json:"created_on"            mapstructure:"created_on"`
+	UpdatedOn   time.Time    `json:"updated_on"            mapstructure:"updated_on"`
+	Links       common.Links `json:"links"                 mapstructure:"links"`
+}
+
+// Statuses is a list of commit statuses
+type Statuses []Status
+
+const (
+	// StatusSuccessful is the state of a successful build
+	StatusSuccessful = "SUCCESSFUL"
+	// StatusFailed is the state of a failed build
+	StatusFailed = "FAILED"
+	// StatusInProgress is the state of a running build
+	StatusInProgress = "INPROGRESS"
+	// StatusStopped is the state of a build that was stopped
+	StatusStopped = "STOPPED"
+)
+
+// GetType gets the type of this status
+//
+// implements core.TypeCarrier
+func (status Status) GetType() string {
+	return "build"
+}
+
+// IsFinished tells if the build of this status is finished
+func (status Status) IsFinished() bool {
+	return status.State != StatusInProgress
+}
+
+// IsSuccessful tells if the build of this status succeeded
+func (status Status) IsSuccessful() bool {
+	return status.State == StatusSuccessful
+}
+
+// GetDuration gets the duration of the build, until now if it is still running
+func (status Status) GetDuration() time.Duration {
+	if status.CreatedOn.IsZero() {
+		return 0
+	}
+	if !status.IsFinished() {
+		return time.Since(status.CreatedOn).Truncate(time.Second)
+	}
+	return status.UpdatedOn.Sub(status.CreatedOn).Truncate(time.Second)
+}
+
+// GetHeaders gets the header for a table
+//
+// implements common.Tableable
+func (status Status) GetHeaders(cmd *cobra.Command) []string {
+	if cmd != nil && cmd.Flag("columns") != nil && cmd.Flag("columns").Changed {
+		if columns, err := cmd.Flags().GetStringSlice("columns"); err == nil {
+			return core.Map(columns, func(column string) string { return strings.ReplaceAll(column, "_", " ") })
+		}
+	}
+	return []string{"Key", "Name", "State", "Duration", "URL"}
+}
+
+// GetRow gets the row for a table
+//
+// implements common.Tableable
+func (status Status) GetRow(headers []string) []string {
+	var row []string
+
+	for _, header := range headers {
+		switch strings.ToLower(header) {
+		case "key":
+			row = append(row, status.Key)
+		case "name":
+			row = append(row, status.Name)
+		case "state":
+			row = append(row, status.State)
+		case "description":
+			row = append(row, status.Description)
+		case "duration":
+			row = append(row, status.GetDuration().String())
+		case "url":
+			row = append(row, status.URL)
+		case "created on", "created_on", "created-on":
+			row = append(row, status.CreatedOn.Format("2006-01-02 15:04:05"))
+		case "updated on", "updated_on", "updated-on":
+			row = append(row, status.UpdatedOn.Format("2006-01-02 15:04:05"))
+		}
+	}
+	return row
+}
+
+// State gets the overall state of the statuses
+//
+// FAILED if any build failed or was stopped, INPROGRESS if any build is still running, SUCCESSFUL if all builds succeeded,
+// and an empty string if there are no builds
+func (statuses Statuses) State() string {
+	if len(statuses) == 0 {
+		return ""
+	}
+	state := StatusSuccessful
+	for _, status := range statuses {
+		switch {
+		case !status.IsFinished():
+			state = StatusInProgress
+		case !status.IsSuccessful():
+			return StatusFailed
+		}
+	}
+	return state
+}
+
+// GetHeaders gets the header for a table
+//
+// implements common.Tableables
+func (statuses Statuses) GetHeaders(cmd *cobra.Command) []string {
+	return Status{}.GetHeaders(cmd)
+}
+
+// GetRowAt gets the row for a table
+//
+// implements common.Tableables
+func (statuses Statuses) GetRowAt(index int, headers []string) []string {
+	if index < 0 || index >= len(statuses) {
+		return []string{}
+	}
+	return statuses[index].GetRow(headers)
+}
+
+// Size gets the number of elements
+//
+// implements common.Tableables
+func (statuses Statuses) Size() int {
+	return len(statuses)
+}
+
+// GetStatuses gets the statuses (builds, etc.) reported on a commit of a repository
+func GetStatuses(ctx context.Context, cmd *cobra.Command, repository *repository.Repository, hash string) (Statuses, error) {
+	return profile.GetAll[Status](ctx, cmd, repository.GetPath("commit", hash, "statuses"))
+}
@@ -0,0 +1,98 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gildas/go-core"
+	"github.com/gildas/go-errors"
+)
+
+// BBQL is a Bitbucket Query Language expression made of conditions that are ANDed together
+//
+// See https://developer.atlassian.com/cloud/bitbucket/rest/intro/#filtering
+type BBQL []string
+
+// Equals adds a condition where the field equals the given value
+func (query *BBQL) Equals(field, value string) *BBQL {
+	*query = append(*query, fmt.Sprintf("%s = %s", field, QuoteBBQL(value)))
+	return query
+}
+
+// Contains adds a condition where the field contains the given value, case insensitive
+func (query *BBQL) Contains(field, value string) *BBQL {
+	*query = append(*query, fmt.Sprintf("%s ~ %s", field, QuoteBBQL(value)))
+	return query
+}
+
+// Is adds a condition where the boolean field has the given value
+func (query *BBQL) Is(field string, value bool) *BBQL {
+	*query = append(*query, fmt.Sprintf("%s = %t", field, value))
+	return query
+}
+
+// After adds a condition where the date field is after the given time
+func (query *BBQL) After(field string, value time.Time) *BBQL {
+	*query = append(*query, fmt.Sprintf("%s > %s", field, value.UTC().Format(time.RFC3339)))
+	return query
+}
+
+// Before adds a condition where the date field is before the given time
+func (query *BBQL) Before(field string, value time.Time) *BBQL {
+	*query = append(*query, fmt.Sprintf("%s < %s", field, value.UTC().Format(time.RFC3339)))
+	return query
+}
+
+// Raw adds a condition written in BBQL, it is added as is between parentheses
+func (query *BBQL) Raw(expression string) *BBQL {
+	if expression = strings.TrimSpace(expression); len(expression) > 0 {
+		*query = append(*query, "("+expression+")")
+	}
+	return query
+}
+
+// IsEmpty tells if the query has no condition
+func (query BBQL) IsEmpty() bool {
+	return len(query) == 0
+}
+
+// String gets the BBQL expression
+//
+// implements fmt.Stringer
+func (query BBQL) String() string {
+	return strings.Join(query, " AND ")
+}
+
+// QuoteBBQL quotes a string value for BBQL, escaping the backslashes and double quotes
+func QuoteBBQL(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// ParseBBQLTime parses a time for a BBQL date condition
+//
+// The value can be a date (2025-12-31), a date and time (2025-12-31T12:00:00Z), today, yesterday,
+// or a duration before now (72h, 7d, 2w, P1M)
+func ParseBBQLTime(value string, now time.Time) (time.Time, error) {
+	if parsed, err := core.ParseTimeIn(value, now.Location()); err == nil {
+		return parsed.AsTime(), nil
+	}
+	duration, err := core.ParseDuration(value)
+	if err != nil {
+		if duration, err = core.ParseDuration("P" + strings.ToUpper(value)); err != nil {
+			return time.Time{}, errors.ArgumentInvalid.With("time", value)
+		}
+	}
+	return now.Add(-duration), nil
+}
+
+// ParseBBQLEndTime parses the end of a period for a BBQL date condition
+//
+// Same as ParseBBQLTime, but a date (2025-12-31) means the end of that day,
+// so the pull requests of that day are included
+func ParseBBQLEndTime(value string, now time.Time) (time.Time, error) {
+	if date, err := time.ParseInLocation(time.DateOnly, value, now.Location()); err == nil {
+		return date.AddDate(0, 0, 1), nil
+	}
+	return ParseBBQLTime(value, now)
+}
@@ -0,0 +1,61 @@
+package common_test
+
+import (
+	"time"
+
+	"github.com/gildas/bitbucket-cli/cmd/common"
+)
+
+func (suite *CommonSuite) TestCanBuildBBQL() {
+	var query common.BBQL
+	suite.True(query.IsEmpty())
+
+	createdAfter := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+	query.Equals("author.uuid", "{c32f719b-6c8a-4c87-93e2-9ba8f5cd90dd}").
+		Is("draft", true).
+		Contains("title", `fix "quotes" and \ slashes`).
+		After("created_on", createdAfter).
+		Raw("  ").
+		Raw(`state = "OPEN" OR state = "MERGED"`)
+	suite.False(query.IsEmpty())
+	expected := `author.uuid = "{c32f719b-6c8a-4c87-93e2-9ba8f5cd90dd}" AND draft = true AND title ~ "fix \"quotes\" and \\ slashes" AND created_on > 2025-12-31T00:00:00Z AND (state = "OPEN" OR state = "MERGED")`
+	suite.Equal(expected, query.String())
+}
+
+func (suite *CommonSuite) TestCanParseBBQLTime() {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	parsed, err := common.ParseBBQLTime("2025-12-31", now)
+	suite.Require().NoError(err)
+	suite.Equal(time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC), parsed)
+
+	parsed, err = common.ParseBBQLTime("72h", now)
+	suite.Require().NoError(err)
+	suite.Equal(now.Add(-72*time.Hour), parsed)
+
+	parsed, err = common.ParseBBQLTime("7d", now)
+	suite.Require().NoError(err)
+	suite.Equal(now.AddDate(0, 0, -7), parsed)
+
+	_, err = common.ParseBBQLTime("last tuesday", now)
+	suite.Require().Error(err)
+}
+
+func (suite *CommonSuite) TestCanParseBBQLEndTime() {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	parsed, err := common.ParseBBQLEndTime("2025-12-31", now)
+	suite.Require().NoError(err)
+	suite.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), parsed)
+
+	parsed, err = common.ParseBBQLEndTime("2025-12-31T12:00:00Z", now)
+	suite.Require().NoError(err)
+	suite.Equal(time.Date(2025, 12, 31, 12, 0, 0, 0, time.UTC), parsed)
+
+	parsed, err = common.ParseBBQLEndTime("7d", now)
+	suite.Require().NoError(err)
+	suite.Equal(now.AddDate(0, 0, -7), parsed)
+
+	_, err = common.ParseBBQLEndTime("last tuesday", now)
+	suite.Require().Error(err)
+}
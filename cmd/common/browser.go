@@ -0,0 +1,78 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/spf13/cobra"
+	"gopkg.in/ini.v1"
+)
+
+// Browse opens the HTML link of an entity in the default web browser
+//
+// If printOnly is true, or if the browser cannot be opened (e.g. in an SSH session), the URL is printed instead
+func Browse(ctx context.Context, cmd *cobra.Command, link *Link, printOnly bool) error {
+	log := logger.Must(logger.FromContext(ctx)).Child("common", "browse")
+
+	if link == nil || len(link.HREF.String()) == 0 {
+		return errors.NotFound.With("web page")
+	}
+	if printOnly {
+		fmt.Fprintln(cmd.OutOrStdout(), link.HREF.String())
+		return nil
+	}
+	log.Infof("Opening %s", link.HREF.String())
+	if err := OpenBrowser(link.HREF); err != nil {
+		log.Warnf("Failed to open browser: %s", err.Error())
+		fmt.Fprintf(cmd.ErrOrStderr(), "Cannot open a browser (%s), please open the following URL:\n", err)
+		fmt.Fprintln(cmd.OutOrStdout(), link.HREF.String())
+	}
+	return nil
+}
+
+// OpenBrowser opens the specified URL in the default web browser
+func OpenBrowser(url url.URL) error {
+	var cmd string
+	var args []string
+
+	switch runtime.GOOS {
+	case "linux":
+		cmd = "xdg-open"
+		if _, exists := os.LookupEnv("SSH_CONNECTION"); exists {
+			return errors.New("Cannot open browser in SSH session")
+		}
+		if IsWSL() {
+			// If the flag interop=true is not set in /etc/wsl.conf, return an error
+			if content, err := os.ReadFile("/etc/wsl.conf"); err == nil {
+				if data, err := ini.Load(content); err == nil {
+					if section, err := data.GetSection("interop"); err == nil {
+						if key, err := section.GetKey("enabled"); err == nil {
+							if strings.ToLower(key.String()) != "true" {
+								return errors.New("Cannot open browser in WSL without interop enabled")
+							}
+						}
+					}
+				}
+			}
+			cmd = "cmd.exe"
+			args = append(args, "/C", "start")
+		}
+	case "windows":
+		cmd = "rundll32"
+		args = append(args, "url.dll,FileProtocolHandler")
+	case "darwin":
+		cmd = "open"
+	default:
+		return fmt.Errorf("unsupported platform")
+	}
+
+	args = append(args, `"`+url.String()+`"`)
+	return exec.Command(cmd, args...).Start()
+}
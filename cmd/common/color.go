@@ -0,0 +1,59 @@
+package common
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// Color is an ANSI color (SGR parameter)
+type Color string
+
+const (
+	// ColorBold is the bold attribute
+	ColorBold Color = "1"
+	// ColorRed is the red foreground color
+	ColorRed Color = "31"
+	// ColorGreen is the green foreground color
+	ColorGreen Color = "32"
+	// ColorYellow is the yellow foreground color
+	ColorYellow Color = "33"
+	// ColorBlue is the blue foreground color
+	ColorBlue Color = "34"
+	// ColorCyan is the cyan foreground color
+	ColorCyan Color = "36"
+	// ColorGray is the gray (bright black) foreground color
+	ColorGray Color = "90"
+)
+
+// Colorize wraps the text with the ANSI codes of the given color
+func Colorize(color Color, text string) string {
+	if len(text) == 0 {
+		return text
+	}
+	return "\x1b[" + string(color) + "m" + text + "\x1b[0m"
+}
+
+// CanColor tells if the standard output can display colors
+//
+// Colors are disabled when the standard output is not a terminal or when the NO_COLOR environment variable is set (see https://no-color.org)
+func CanColor() bool {
+	if _, found := os.LookupEnv("NO_COLOR"); found {
+		return false
+	}
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// UseColor tells if colors should be used for the given mode of a --color flag: always, never, or auto
+//
+// auto (or any other value) uses CanColor
+func UseColor(mode string) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	default:
+		return CanColor()
+	}
+}
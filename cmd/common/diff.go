@@ -0,0 +1,239 @@
+package common
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gildas/go-errors"
+)
+
+// Diff is a parsed unified diff, as returned by the diff endpoints of Bitbucket
+type Diff []DiffFile
+
+// DiffFile is the diff of one file
+type DiffFile struct {
+	OldPath  string
+	NewPath  string
+	Header   []string
+	Hunks    []DiffHunk
+	IsBinary bool
+}
+
+// DiffHunk is a hunk of a DiffFile
+type DiffHunk struct {
+	Header   string
+	OldStart uint64
+	OldLines uint64
+	NewStart uint64
+	NewLines uint64
+	Lines    []DiffLine
+}
+
+// DiffLine is a line of a DiffHunk
+//
+// Kind is ' ' for context lines, '+' for added lines, '-' for removed lines, and '\' for "\ No newline at end of file"
+type DiffLine struct {
+	Kind      byte
+	Text      string
+	OldNumber uint64
+	NewNumber uint64
+}
+
+// ParseDiff parses a unified diff (git format)
+func ParseDiff(reader io.Reader) (diff Diff, err error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var file *DiffFile
+	var hunk *DiffHunk
+	var oldNumber, newNumber uint64
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			diff = append(diff, DiffFile{Header: []string{line}})
+			file = &diff[len(diff)-1]
+			hunk = nil
+			file.OldPath, file.NewPath = parseDiffGitLine(line)
+		case file == nil:
+			// Anything before the first file is ignored
+		case hunk == nil && strings.HasPrefix(line, "--- "):
+			file.Header = append(file.Header, line)
+			file.OldPath = parseDiffPath(line[4:], "a/")
+		case hunk == nil && strings.HasPrefix(line, "+++ "):
+			file.Header = append(file.Header, line)
+			file.NewPath = parseDiffPath(line[4:], "b/")
+		case strings.HasPrefix(line, "@@ "):
+			file.Hunks = append(file.Hunks, DiffHunk{Header: line})
+			hunk = &file.Hunks[len(file.Hunks)-1]
+			if err := hunk.parseHeader(); err != nil {
+				return nil, err
+			}
+			oldNumber, newNumber = hunk.OldStart, hunk.NewStart
+		case hunk == nil:
+			file.Header = append(file.Header, line)
+			switch {
+			case strings.HasPrefix(line, "new file mode "):
+				file.OldPath = ""
+			case strings.HasPrefix(line, "deleted file mode "):
+				file.NewPath = ""
+			case strings.HasPrefix(line, "rename from "):
+				file.OldPath = strings.TrimPrefix(line, "rename from ")
+			case strings.HasPrefix(line, "rename to "):
+				file.NewPath = strings.TrimPrefix(line, "rename to ")
+			case strings.HasPrefix(line, "Binary files "), strings.HasPrefix(line, "GIT binary patch"):
+				file.IsBinary = true
+			}
+		case len(line) == 0 || line[0] == ' ':
+			text := ""
+			if len(line) > 0 {
+				text = line[1:]
+			}
+			hunk.Lines = append(hunk.Lines, DiffLine{Kind: ' ', Text: text, OldNumber: oldNumber, NewNumber: newNumber})
+			oldNumber++
+			newNumber++
+		case line[0] == '-':
+			hunk.Lines = append(hunk.Lines, DiffLine{Kind: '-', Text: line[1:], OldNumber: oldNumber})
+			oldNumber++
+		case line[0] == '+':
+			hunk.Lines = append(hunk.Lines, DiffLine{Kind: '+', Text: line[1:], NewNumber: newNumber})
+			newNumber++
+		case line[0] == '\\':
+			hunk.Lines = append(hunk.Lines, DiffLine{Kind: '\\', Text: line})
+		default:
+			// Not part of the hunk anymore (e.g. extended headers of a malformed diff)
+			hunk = nil
+			file.Header = append(file.Header, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.RuntimeError.Wrap(err)
+	}
+	return diff, nil
+}
+
+// Path gets the path of the file, the old path if the file was deleted
+func (file DiffFile) Path() string {
+	if len(file.NewPath) == 0 {
+		return file.OldPath
+	}
+	return file.NewPath
+}
+
+// Status gets the status of the file: added, removed, renamed, or modified
+func (file DiffFile) Status() string {
+	switch {
+	case len(file.OldPath) == 0:
+		return "added"
+	case len(file.NewPath) == 0:
+		return "removed"
+	case file.OldPath != file.NewPath:
+		return "renamed"
+	default:
+		return "modified"
+	}
+}
+
+// Stat gets the number of added and removed lines of the file
+func (file DiffFile) Stat() (added, removed int) {
+	for _, hunk := range file.Hunks {
+		for _, line := range hunk.Lines {
+			switch line.Kind {
+			case '+':
+				added++
+			case '-':
+				removed++
+			}
+		}
+	}
+	return
+}
+
+// String gets a string representation of this DiffFile
+//
+// implements fmt.Stringer
+func (file DiffFile) String() string {
+	if file.Status() == "renamed" {
+		return fmt.Sprintf("%s => %s", file.OldPath, file.NewPath)
+	}
+	return file.Path()
+}
+
+// parseHeader parses the header of the hunk: @@ -oldStart,oldLines +newStart,newLines @@ section
+func (hunk *DiffHunk) parseHeader() (err error) {
+	fields := strings.Fields(hunk.Header)
+	if len(fields) < 4 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return errors.ArgumentInvalid.With("hunk", hunk.Header)
+	}
+	if hunk.OldStart, hunk.OldLines, err = parseHunkRange(fields[1][1:]); err != nil {
+		return errors.ArgumentInvalid.With("hunk", hunk.Header)
+	}
+	if hunk.NewStart, hunk.NewLines, err = parseHunkRange(fields[2][1:]); err != nil {
+		return errors.ArgumentInvalid.With("hunk", hunk.Header)
+	}
+	return nil
+}
+
+// parseHunkRange parses a range like 12,5 or 12 (which means 12,1)
+func parseHunkRange(value string) (start, count uint64, err error) {
+	startValue, countValue, found := strings.Cut(value, ",")
+	if start, err = strconv.ParseUint(startValue, 10, 64); err != nil {
+		return
+	}
+	if !found {
+		return start, 1, nil
+	}
+	count, err = strconv.ParseUint(countValue, 10, 64)
+	return
+}
+
+// parseDiffGitLine gets the paths from a "diff --git a/path b/path" line
+//
+// The ---/+++ lines, if any, are more reliable and override these paths
+func parseDiffGitLine(line string) (oldPath, newPath string) {
+	paths := strings.TrimPrefix(line, "diff --git ")
+	if index := strings.Index(paths, " b/"); index >= 0 {
+		return strings.TrimPrefix(paths[:index], "a/"), paths[index+3:]
+	}
+	return "", ""
+}
+
+// parseDiffPath parses the path of a ---/+++ line, /dev/null becomes an empty path
+func parseDiffPath(value, prefix string) string {
+	value, _, _ = strings.Cut(value, "\t")
+	if value == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(strings.Trim(value, `"`), prefix)
+}
+
+// FindFile finds the file of the diff with the given path, old or new
+func (diff Diff) FindFile(path string) (*DiffFile, bool) {
+	for index, file := range diff {
+		if file.NewPath == path || file.OldPath == path {
+			return &diff[index], true
+		}
+	}
+	return nil, false
+}
+
+// AnchorContext gets the lines of the file around an inline comment anchor, and the index of the anchored line among them
+//
+// Bitbucket anchors a comment on a line of the new version with To, or on a removed line of the old version with From.
+// If the anchored line is not part of the diff, no line is returned.
+func (file DiffFile) AnchorContext(anchor FileAnchor, around int) (lines []DiffLine, target int) {
+	for _, hunk := range file.Hunks {
+		for index, line := range hunk.Lines {
+			if (anchor.To > 0 && line.Kind != '-' && line.NewNumber == anchor.To) || (anchor.To == 0 && anchor.From > 0 && line.Kind != '+' && line.OldNumber == anchor.From) {
+				start := max(0, index-around)
+				end := min(len(hunk.Lines), index+around+1)
+				return hunk.Lines[start:end], index - start
+			}
+		}
+	}
+	return nil, -1
+}
@@ -0,0 +1,109 @@
+package common
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-flags"
+	"github.com/spf13/cobra"
+)
+
+// DiffOptions are the options of the commands that show a diff
+type DiffOptions struct {
+	Stat       bool
+	NameOnly   bool
+	SideBySide bool
+	NoPager    bool
+	Paths      []string
+	Color      *flags.EnumFlag
+}
+
+// AddFlags adds the diff flags to the given command
+func (options *DiffOptions) AddFlags(command *cobra.Command) {
+	options.Color = flags.NewEnumFlag("always", "+auto", "never")
+	command.Flags().BoolVar(&options.Stat, "stat", false, "show only the diffstat")
+	command.Flags().BoolVar(&options.NameOnly, "name-only", false, "show only the names of the changed files")
+	command.Flags().BoolVar(&options.SideBySide, "side-by-side", false, "show the old and new versions side by side")
+	command.Flags().BoolVar(&options.NoPager, "no-pager", false, "do not send the output through the pager")
+	command.Flags().StringSliceVar(&options.Paths, "path", []string{}, "show only the files matching the given path or glob pattern. Can be repeated")
+	command.Flags().Var(options.Color, "color", "colorize the diff: always, auto, or never")
+	command.MarkFlagsMutuallyExclusive("stat", "name-only", "side-by-side")
+	_ = command.RegisterFlagCompletionFunc(options.Color.CompletionFunc("color"))
+}
+
+// GetQuery gets the query to send to the diff endpoints
+//
+// Bitbucket filters exact paths, the glob patterns are filtered locally
+func (options DiffOptions) GetQuery() (url.Values, error) {
+	query := url.Values{}
+	for _, path := range options.Paths {
+		if options.hasPatterns() {
+			if options.Stat {
+				return nil, errors.ArgumentInvalid.With("path", path)
+			}
+			return query, nil
+		}
+		query.Add("path", path)
+	}
+	return query, nil
+}
+
+// Print prints the diff read from the given reader, as requested by the options
+func (options DiffOptions) Print(ctx context.Context, reader io.Reader) error {
+	if options.Stat {
+		_, err := io.Copy(os.Stdout, reader)
+		return err
+	}
+
+	diff, err := ParseDiff(reader)
+	if err != nil {
+		return err
+	}
+	diff = diff.Filter(options.Paths...)
+
+	if options.NameOnly {
+		for _, name := range diff.Names() {
+			if _, err := io.WriteString(os.Stdout, name+"\n"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var builder strings.Builder
+	renderOptions := DiffRenderOptions{
+		Color:      options.useColor(),
+		SideBySide: options.SideBySide,
+		Width:      GetTerminalWidth(160),
+	}
+	if err := diff.Render(&builder, renderOptions); err != nil {
+		return err
+	}
+	if options.NoPager {
+		_, err := io.WriteString(os.Stdout, builder.String())
+		return err
+	}
+	return Page(ctx, builder.String())
+}
+
+// hasPatterns tells if at least one path is a glob pattern or a folder
+func (options DiffOptions) hasPatterns() bool {
+	for _, path := range options.Paths {
+		if strings.ContainsAny(path, "*?[") || strings.HasSuffix(path, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// useColor tells if the diff should be colored
+func (options DiffOptions) useColor() bool {
+	if options.Color == nil {
+		return CanColor()
+	}
+	return UseColor(options.Color.String())
+}
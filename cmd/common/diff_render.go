@@ -0,0 +1,322 @@
+package common
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// DiffRenderOptions tells how to render a Diff
+type DiffRenderOptions struct {
+	// Color tells if the diff is colored
+	Color bool
+	// SideBySide tells if the old and new versions are rendered in columns
+	SideBySide bool
+	// Width is the width of the output, used by SideBySide
+	Width int
+}
+
+const (
+	// colorReverse highlights the changes within a line
+	colorReverse Color = "7"
+	// diffTabWidth is the number of spaces tabs are expanded to in side by side diffs
+	diffTabWidth = 4
+	// diffMinWidth is the minimum width of side by side diffs
+	diffMinWidth = 40
+)
+
+// Filter gets the files of the diff whose path matches at least one of the given glob patterns
+//
+// The patterns are matched against the old and new paths of the files, and against their base names if the pattern has no slash
+func (diff Diff) Filter(patterns ...string) Diff {
+	if len(patterns) == 0 {
+		return diff
+	}
+	filtered := Diff{}
+	for _, file := range diff {
+		if file.Matches(patterns...) {
+			filtered = append(filtered, file)
+		}
+	}
+	return filtered
+}
+
+// Matches tells if the path of the file matches at least one of the given glob patterns
+func (file DiffFile) Matches(patterns ...string) bool {
+	for _, pattern := range patterns {
+		for _, filepath := range []string{file.OldPath, file.NewPath} {
+			if len(filepath) == 0 {
+				continue
+			}
+			if matched, _ := path.Match(pattern, filepath); matched {
+				return true
+			}
+			if !strings.Contains(pattern, "/") {
+				if matched, _ := path.Match(pattern, path.Base(filepath)); matched {
+					return true
+				}
+			}
+			// a directory pattern matches the files it contains
+			if strings.HasPrefix(filepath, strings.TrimSuffix(pattern, "/")+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Names gets the paths of the files of the diff
+func (diff Diff) Names() []string {
+	names := make([]string, 0, len(diff))
+	for _, file := range diff {
+		names = append(names, file.Path())
+	}
+	return names
+}
+
+// Render writes the diff to the given writer
+func (diff Diff) Render(writer io.Writer, options DiffRenderOptions) (err error) {
+	for _, file := range diff {
+		if options.SideBySide {
+			err = file.renderSideBySide(writer, options)
+		} else {
+			err = file.renderUnified(writer, options)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderUnified writes the file as a unified diff
+func (file DiffFile) renderUnified(writer io.Writer, options DiffRenderOptions) error {
+	colorize := diffColorizer(options.Color)
+	var builder strings.Builder
+
+	for _, line := range file.Header {
+		builder.WriteString(colorize(ColorBold, line))
+		builder.WriteString("\n")
+	}
+	for _, hunk := range file.Hunks {
+		builder.WriteString(colorize(ColorCyan, hunk.Header))
+		builder.WriteString("\n")
+		for index := 0; index < len(hunk.Lines); {
+			switch hunk.Lines[index].Kind {
+			case ' ':
+				builder.WriteString(" " + hunk.Lines[index].Text + "\n")
+				index++
+			case '-', '+':
+				end := index
+				for end < len(hunk.Lines) && hunk.Lines[end].Kind != ' ' {
+					end++
+				}
+				builder.WriteString(renderChanges(hunk.Lines[index:end], options.Color))
+				index = end
+			default:
+				builder.WriteString(colorize(ColorGray, hunk.Lines[index].Text) + "\n")
+				index++
+			}
+		}
+	}
+	_, err := io.WriteString(writer, builder.String())
+	return err
+}
+
+// renderSideBySide writes the file with the old and new versions in columns
+func (file DiffFile) renderSideBySide(writer io.Writer, options DiffRenderOptions) error {
+	colorize := diffColorizer(options.Color)
+	width := max(options.Width, diffMinWidth)
+	column := (width - 3) / 2
+	var builder strings.Builder
+
+	builder.WriteString(colorize(ColorBold, file.String()))
+	builder.WriteString("\n")
+	if file.IsBinary {
+		builder.WriteString(colorize(ColorGray, "Binary file"))
+		builder.WriteString("\n")
+	}
+	for _, hunk := range file.Hunks {
+		builder.WriteString(colorize(ColorCyan, hunk.Header))
+		builder.WriteString("\n")
+		for _, row := range hunk.pairChanges() {
+			builder.WriteString(sideBySideCell(row.Old, column, colorize))
+			builder.WriteString(colorize(ColorGray, " │ "))
+			builder.WriteString(strings.TrimRight(sideBySideCell(row.New, column, colorize), " "))
+			builder.WriteString("\n")
+		}
+	}
+	_, err := io.WriteString(writer, builder.String())
+	return err
+}
+
+// renderChanges renders a block of removed and added lines
+//
+// When colored, the removed lines are paired with the added lines, in order, and the changes within the lines are highlighted
+func renderChanges(lines []DiffLine, colored bool) string {
+	colorize := diffColorizer(colored)
+	var removed, added []int
+	for index, line := range lines {
+		switch line.Kind {
+		case '-':
+			removed = append(removed, index)
+		case '+':
+			added = append(added, index)
+		}
+	}
+	texts := make([]string, len(lines))
+	for index, line := range lines {
+		switch line.Kind {
+		case '-':
+			texts[index] = colorize(ColorRed, "-"+line.Text)
+		case '+':
+			texts[index] = colorize(ColorGreen, "+"+line.Text)
+		default:
+			texts[index] = colorize(ColorGray, line.Text)
+		}
+	}
+	if colored {
+		for i := 0; i < min(len(removed), len(added)); i++ {
+			oldText, newText := highlightChanges(lines[removed[i]].Text, lines[added[i]].Text, ColorRed, ColorGreen)
+			texts[removed[i]] = Colorize(ColorRed, "-") + oldText
+			texts[added[i]] = Colorize(ColorGreen, "+") + newText
+		}
+	}
+	return strings.Join(texts, "\n") + "\n"
+}
+
+// diffRow is a row of a side by side diff, a removed line paired with an added line, or a context line on both sides
+type diffRow struct {
+	Old *DiffLine
+	New *DiffLine
+}
+
+// pairChanges pairs the removed lines with the added lines that follow them
+func (hunk DiffHunk) pairChanges() (rows []diffRow) {
+	lines := hunk.Lines
+	for index := 0; index < len(lines); {
+		line := &lines[index]
+		switch line.Kind {
+		case '-', '+':
+			var removed, added []*DiffLine
+			for ; index < len(lines) && lines[index].Kind == '-'; index++ {
+				removed = append(removed, &lines[index])
+			}
+			for ; index < len(lines) && lines[index].Kind == '+'; index++ {
+				added = append(added, &lines[index])
+			}
+			for i := 0; i < max(len(removed), len(added)); i++ {
+				row := diffRow{}
+				if i < len(removed) {
+					row.Old = removed[i]
+				}
+				if i < len(added) {
+					row.New = added[i]
+				}
+				rows = append(rows, row)
+			}
+		case ' ':
+			rows = append(rows, diffRow{Old: line, New: line})
+			index++
+		default: // "\ No newline at end of file"
+			index++
+		}
+	}
+	return rows
+}
+
+// sideBySideCell renders one side of a side by side row, padded to the width of the column
+func sideBySideCell(line *DiffLine, width int, colorize func(Color, string) string) string {
+	if line == nil {
+		return strings.Repeat(" ", width)
+	}
+	number := line.NewNumber
+	color := ColorGreen
+	switch line.Kind {
+	case '-':
+		number = line.OldNumber
+		color = ColorRed
+	case ' ':
+		color = ""
+	}
+	text := fmt.Sprintf("%4d %s", number, strings.ReplaceAll(line.Text, "\t", strings.Repeat(" ", diffTabWidth)))
+	if length := utf8.RuneCountInString(text); length > width {
+		text = string([]rune(text)[:width-1]) + "…"
+	} else {
+		text += strings.Repeat(" ", width-length)
+	}
+	if len(color) == 0 {
+		return text
+	}
+	return colorize(color, text)
+}
+
+// highlightChanges colors the old and new versions of a line, the part that changed is highlighted
+func highlightChanges(oldText, newText string, oldColor, newColor Color) (string, string) {
+	oldRunes, newRunes := []rune(oldText), []rune(newText)
+	prefix := 0
+	for prefix < len(oldRunes) && prefix < len(newRunes) && oldRunes[prefix] == newRunes[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldRunes)-prefix && suffix < len(newRunes)-prefix && oldRunes[len(oldRunes)-1-suffix] == newRunes[len(newRunes)-1-suffix] {
+		suffix++
+	}
+	if prefix+suffix == 0 {
+		// Nothing in common, highlighting the whole line would not help
+		return Colorize(oldColor, oldText), Colorize(newColor, newText)
+	}
+	highlight := func(runes []rune, color Color) string {
+		changed := string(runes[prefix : len(runes)-suffix])
+		if len(changed) > 0 {
+			changed = "\x1b[" + string(colorReverse) + "m" + changed + "\x1b[27m"
+		}
+		return Colorize(color, string(runes[:prefix])+changed+string(runes[len(runes)-suffix:]))
+	}
+	return highlight(oldRunes, oldColor), highlight(newRunes, newColor)
+}
+
+// diffColorizer gets a function that colorizes text only if colors are enabled
+func diffColorizer(enabled bool) func(Color, string) string {
+	return func(color Color, text string) string {
+		if enabled {
+			return Colorize(color, text)
+		}
+		return text
+	}
+}
+
+// RenderContext writes lines of a diff with their line numbers, the target line is marked with an arrow
+//
+// See DiffFile.AnchorContext
+func RenderContext(writer io.Writer, lines []DiffLine, target int, color bool) error {
+	colorize := diffColorizer(color)
+	var builder strings.Builder
+
+	for index, line := range lines {
+		marker := "  "
+		if index == target {
+			marker = colorize(ColorYellow, "▶ ")
+		}
+		number := "    "
+		if line.Kind == '-' {
+			number = fmt.Sprintf("%4d", line.OldNumber)
+		} else if line.NewNumber > 0 {
+			number = fmt.Sprintf("%4d", line.NewNumber)
+		}
+		text := string(line.Kind) + strings.ReplaceAll(line.Text, "\t", strings.Repeat(" ", diffTabWidth))
+		switch line.Kind {
+		case '+':
+			text = colorize(ColorGreen, text)
+		case '-':
+			text = colorize(ColorRed, text)
+		case '\\':
+			text = colorize(ColorGray, line.Text)
+		}
+		builder.WriteString(fmt.Sprintf("%s%s %s\n", marker, colorize(ColorGray, number), text))
+	}
+	_, err := io.WriteString(writer, builder.String())
+	return err
+}
@@ -0,0 +1,97 @@
+package common_test
+
+import (
+	"strings"
+
+	"github.com/gildas/bitbucket-cli/cmd/common"
+)
+
+const sampleDiff = `diff --git a/README.md b/README.md
+index 3b18e51..a042389 100644
+--- a/README.md
++++ b/README.md
+@@ -1,3 +1,4 @@
+ # Title
+-old line
++new line
++another line
+ end
+diff --git a/new.txt b/new.txt
+new file mode 100644
+index 0000000..ce01362
+--- /dev/null
++++ b/new.txt
+@@ -0,0 +1 @@
++hello
+\ No newline at end of file
+diff --git a/image.png b/image.png
+new file mode 100644
+index 0000000..e69de29
+Binary files /dev/null and b/image.png differ
+`
+
+func (suite *CommonSuite) TestCanParseDiff() {
+	diff, err := common.ParseDiff(strings.NewReader(sampleDiff))
+	suite.Require().NoError(err)
+	suite.Require().Len(diff, 3)
+
+	readme := diff[0]
+	suite.Assert().Equal("README.md", readme.Path())
+	suite.Assert().Equal("modified", readme.Status())
+	suite.Require().Len(readme.Hunks, 1)
+	hunk := readme.Hunks[0]
+	suite.Assert().Equal(uint64(1), hunk.OldStart)
+	suite.Assert().Equal(uint64(3), hunk.OldLines)
+	suite.Assert().Equal(uint64(4), hunk.NewLines)
+	suite.Require().Len(hunk.Lines, 5)
+	suite.Assert().Equal(byte('-'), hunk.Lines[1].Kind)
+	suite.Assert().Equal(uint64(2), hunk.Lines[1].OldNumber)
+	suite.Assert().Equal(byte('+'), hunk.Lines[3].Kind)
+	suite.Assert().Equal(uint64(3), hunk.Lines[3].NewNumber)
+	suite.Assert().Equal(uint64(3), hunk.Lines[4].OldNumber)
+	suite.Assert().Equal(uint64(4), hunk.Lines[4].NewNumber)
+	added, removed := readme.Stat()
+	suite.Assert().Equal(2, added)
+	suite.Assert().Equal(1, removed)
+
+	suite.Assert().Equal("new.txt", diff[1].Path())
+	suite.Assert().Equal("added", diff[1].Status())
+	suite.Require().Len(diff[1].Hunks, 1)
+	suite.Assert().Equal(uint64(1), diff[1].Hunks[0].NewLines)
+	suite.Assert().Equal(byte('\\'), diff[1].Hunks[0].Lines[1].Kind)
+
+	suite.Assert().Equal("image.png", diff[2].Path())
+	suite.Assert().Equal("added", diff[2].Status())
+	suite.Assert().True(diff[2].IsBinary)
+	suite.Assert().Empty(diff[2].Hunks)
+}
+
+func (suite *CommonSuite) TestCanFilterDiff() {
+	diff, err := common.ParseDiff(strings.NewReader(sampleDiff))
+	suite.Require().NoError(err)
+	suite.Assert().Equal([]string{"README.md", "new.txt", "image.png"}, diff.Names())
+	suite.Assert().Equal([]string{"new.txt", "image.png"}, diff.Filter("*.txt", "image.*").Names())
+	suite.Assert().Equal([]string{"README.md"}, diff.Filter("README.md").Names())
+	suite.Assert().Empty(diff.Filter("docs/").Names())
+}
+
+func (suite *CommonSuite) TestCanRenderDiff() {
+	diff, err := common.ParseDiff(strings.NewReader(sampleDiff))
+	suite.Require().NoError(err)
+
+	var unified strings.Builder
+	suite.Require().NoError(diff.Render(&unified, common.DiffRenderOptions{}))
+	suite.Assert().Equal(sampleDiff, unified.String())
+
+	var colored strings.Builder
+	suite.Require().NoError(diff.Filter("README.md").Render(&colored, common.DiffRenderOptions{Color: true}))
+	suite.Assert().Contains(colored.String(), "\x1b[31m\x1b[7mold\x1b[27m line\x1b[0m")
+	suite.Assert().Contains(colored.String(), "\x1b[32m\x1b[7mnew\x1b[27m line\x1b[0m")
+
+	var sideBySide strings.Builder
+	suite.Require().NoError(diff.Filter("README.md").Render(&sideBySide, common.DiffRenderOptions{SideBySide: true, Width: 43}))
+	lines := strings.Split(sideBySide.String(), "\n")
+	suite.Require().Len(lines, 7)
+	suite.Assert().Equal("   2 old line        │    2 new line", lines[3])
+	suite.Assert().Equal("                     │    3 another line", lines[4])
+}
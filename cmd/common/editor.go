@@ -0,0 +1,70 @@
+package common
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"golang.org/x/term"
+)
+
+// CanEdit tells if the user can edit text interactively
+func CanEdit() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// GetEditor gets the editor command of the user
+//
+// The editor comes from $VISUAL, then $EDITOR, then the platform default
+func GetEditor() string {
+	if editor := os.Getenv("VISUAL"); len(editor) > 0 {
+		return editor
+	}
+	if editor := os.Getenv("EDITOR"); len(editor) > 0 {
+		return editor
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
+
+// EditText lets the user edit the given text in their editor and returns the edited text
+//
+// The pattern is used to name the temporary file, e.g. "PULLREQUEST_*.md"
+func EditText(ctx context.Context, pattern string, text string) (string, error) {
+	log := logger.Must(logger.FromContext(ctx)).Child("common", "edit")
+
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", errors.RuntimeError.Wrap(err)
+	}
+	defer os.Remove(file.Name())
+	if _, err = file.WriteString(text); err != nil {
+		_ = file.Close()
+		return "", errors.RuntimeError.Wrap(err)
+	}
+	if err = file.Close(); err != nil {
+		return "", errors.RuntimeError.Wrap(err)
+	}
+
+	// The editor can come with arguments, like "code --wait"
+	editor := strings.Fields(GetEditor())
+	log.Infof("Editing %s with %s", file.Name(), editor[0])
+	command := exec.CommandContext(ctx, editor[0], append(editor[1:], file.Name())...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	if err = command.Run(); err != nil {
+		return "", errors.Join(errors.Errorf("Editor %s failed", editor[0]), err)
+	}
+	content, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", errors.RuntimeError.Wrap(err)
+	}
+	return string(content), nil
+}
@@ -0,0 +1,61 @@
+package common
+
+import (
+	"fmt"
+
+	"github.com/gildas/go-errors"
+)
+
+// The exit codes of the commands, a code has the same meaning in all the commands
+const (
+	// ExitError is the exit code of the errors without a dedicated exit code
+	ExitError = 1
+	// ExitBlocked is the exit code when a pullrequest cannot be merged without someone acting on it: conflicts, a failed build, changes requested
+	ExitBlocked = 2
+	// ExitTimedOut is the exit code when what the command waits for did not happen in time (--watch, --when-ready, --async)
+	ExitTimedOut = 3
+	// ExitMergeFailed is the exit code when the merge task of a pullrequest failed
+	ExitMergeFailed = 4
+	// ExitNotApproved is the exit code when some code owners have not approved a pullrequest
+	ExitNotApproved = 5
+)
+
+// ExitCodeError is an error that tells which exit code the process should use
+type ExitCodeError struct {
+	Code  int
+	Cause error
+}
+
+// NewExitCodeError creates a new ExitCodeError
+func NewExitCodeError(code int, cause error) error {
+	return &ExitCodeError{Code: code, Cause: cause}
+}
+
+// Error gets the string representation of this error
+//
+// implements error
+func (err ExitCodeError) Error() string {
+	if err.Cause == nil {
+		return fmt.Sprintf("exit code %d", err.Code)
+	}
+	return err.Cause.Error()
+}
+
+// Unwrap gets the cause of this error
+func (err ExitCodeError) Unwrap() error {
+	return err.Cause
+}
+
+// GetExitCode gets the exit code of the process for the given error
+//
+// 0 if there is no error, the code of an ExitCodeError, ExitError otherwise
+func GetExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitCodeError *ExitCodeError
+	if errors.As(err, &exitCodeError) {
+		return exitCodeError.Code
+	}
+	return ExitError
+}
@@ -0,0 +1,24 @@
+package common_test
+
+import (
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CommonSuite) TestCanGetExitCode() {
+	suite.Assert().Equal(0, common.GetExitCode(nil))
+	suite.Assert().Equal(1, common.GetExitCode(errors.NotFound.With("pullrequest")))
+	err := common.NewExitCodeError(3, errors.Errorf("Timed out"))
+	suite.Assert().Equal(3, common.GetExitCode(err))
+	suite.Assert().Equal("Timed out", err.Error())
+	suite.Assert().Equal(3, common.GetExitCode(errors.Join(errors.Errorf("Cannot merge"), err)), "The exit code should be found in joined errors")
+}
+
+func (suite *CommonSuite) TestExitCodesShouldNotCollide() {
+	codes := []int{common.ExitError, common.ExitBlocked, common.ExitTimedOut, common.ExitMergeFailed, common.ExitNotApproved}
+	seen := map[int]bool{0: true}
+	for _, code := range codes {
+		suite.Assert().False(seen[code], "Exit code %d is used twice", code)
+		seen[code] = true
+	}
+}
@@ -0,0 +1,87 @@
+package common
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	markdownHeading    = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	markdownBullet     = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	markdownCheckbox   = regexp.MustCompile(`^\[([ xX])\]\s+(.*)$`)
+	markdownQuote      = regexp.MustCompile(`^>\s?(.*)$`)
+	markdownCode       = regexp.MustCompile("`([^`]+)`")
+	markdownBold       = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	markdownLink       = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+	markdownCodeFence  = regexp.MustCompile("^\\s*(```|~~~)")
+	markdownHorizontal = regexp.MustCompile(`^\s*(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+)
+
+// RenderMarkdown renders Markdown text for a terminal
+//
+// Only the usual constructs of comments are supported: headings, lists, checkboxes, quotes, code blocks, code spans, bold text, and links.
+// Without color, the markup is removed and the text stays readable.
+func RenderMarkdown(text string, color bool) string {
+	colorize := diffColorizer(color)
+	var builder strings.Builder
+	inCode := false
+
+	for _, line := range strings.Split(strings.ReplaceAll(strings.TrimRight(text, "\r\n"), "\r\n", "\n"), "\n") {
+		if markdownCodeFence.MatchString(line) {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			builder.WriteString("    " + colorize(ColorCyan, line) + "\n")
+			continue
+		}
+		if matches := markdownHeading.FindStringSubmatch(line); matches != nil {
+			builder.WriteString(colorize(ColorBold, renderMarkdownInline(matches[2], false)) + "\n")
+			continue
+		}
+		if markdownHorizontal.MatchString(line) {
+			builder.WriteString(colorize(ColorGray, "────────") + "\n")
+			continue
+		}
+		if matches := markdownQuote.FindStringSubmatch(line); matches != nil {
+			builder.WriteString(colorize(ColorGray, "│ "+renderMarkdownInline(matches[1], false)) + "\n")
+			continue
+		}
+		if matches := markdownBullet.FindStringSubmatch(line); matches != nil {
+			item := matches[2]
+			bullet := "•"
+			if checkbox := markdownCheckbox.FindStringSubmatch(item); checkbox != nil {
+				item = checkbox[2]
+				bullet = "☐"
+				if checkbox[1] != " " {
+					bullet = colorize(ColorGreen, "☑")
+				}
+			}
+			builder.WriteString(matches[1] + bullet + " " + renderMarkdownInline(item, color) + "\n")
+			continue
+		}
+		builder.WriteString(renderMarkdownInline(line, color) + "\n")
+	}
+	return builder.String()
+}
+
+// renderMarkdownInline renders the code spans, bold text, and links of a line
+func renderMarkdownInline(line string, color bool) string {
+	colorize := diffColorizer(color)
+
+	line = markdownCode.ReplaceAllStringFunc(line, func(match string) string {
+		return colorize(ColorCyan, markdownCode.FindStringSubmatch(match)[1])
+	})
+	line = markdownBold.ReplaceAllStringFunc(line, func(match string) string {
+		matches := markdownBold.FindStringSubmatch(match)
+		return colorize(ColorBold, matches[1]+matches[2])
+	})
+	line = markdownLink.ReplaceAllStringFunc(line, func(match string) string {
+		matches := markdownLink.FindStringSubmatch(match)
+		if matches[1] == matches[2] {
+			return colorize(ColorBlue, matches[2])
+		}
+		return matches[1] + " <" + colorize(ColorBlue, matches[2]) + ">"
+	})
+	return line
+}
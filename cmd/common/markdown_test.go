@@ -0,0 +1,10 @@
+package common_test
+
+import "github.com/gildas/bitbucket-cli/cmd/common"
+
+func (suite *CommonSuite) TestCanRenderMarkdown() {
+	text := "# Review\r\nSee **this** and `that`, [docs](https://example.com)\r\n- [x] done\r\n- [ ] todo\r\n> quoted\r\n```\r\ncode\r\n```\r\n"
+	expected := "Review\nSee this and that, docs <https://example.com>\n☑ done\n☐ todo\n│ quoted\n    code\n"
+	suite.Assert().Equal(expected, common.RenderMarkdown(text, false))
+	suite.Assert().Contains(common.RenderMarkdown("**bold**", true), "\x1b[1mbold\x1b[0m")
+}
@@ -0,0 +1,68 @@
+package common
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"golang.org/x/term"
+)
+
+// GetPager gets the pager command from $PAGER, defaults to less
+func GetPager() []string {
+	if pager := strings.Fields(os.Getenv("PAGER")); len(pager) > 0 {
+		return pager
+	}
+	return []string{"less"}
+}
+
+// GetTerminalWidth gets the width of the terminal of the standard output, or the given default if it is not a terminal
+func GetTerminalWidth(defaultWidth int) int {
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
+		return width
+	}
+	return defaultWidth
+}
+
+// Page writes the content to the standard output
+//
+// If the standard output is a terminal and the content does not fit in it, the content is sent through the pager.
+// If the pager cannot be started, the content is written to the standard output.
+func Page(ctx context.Context, content string) error {
+	log := logger.Must(logger.FromContext(ctx)).Child("common", "page")
+
+	if !term.IsTerminal(int(os.Stdout.Fd())) || !term.IsTerminal(int(os.Stdin.Fd())) {
+		_, err := io.WriteString(os.Stdout, content)
+		return err
+	}
+	if _, height, err := term.GetSize(int(os.Stdout.Fd())); err != nil || strings.Count(content, "\n") < height {
+		_, err := io.WriteString(os.Stdout, content)
+		return err
+	}
+
+	pager := GetPager()
+	log.Debugf("Paging %d bytes with %s", len(content), strings.Join(pager, " "))
+	command := exec.CommandContext(ctx, pager[0], pager[1:]...)
+	command.Stdin = strings.NewReader(content)
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	command.Env = os.Environ()
+	if _, found := os.LookupEnv("LESS"); !found {
+		// Quit if the content fits, keep the colors, and do not clear the screen
+		command.Env = append(command.Env, "LESS=FRX")
+	}
+	if err := command.Run(); err != nil {
+		var exitError *exec.ExitError
+		if errors.As(err, &exitError) {
+			return nil // the pager was quit before reading everything
+		}
+		log.Warnf("Failed to run the pager %s: %s", pager[0], err)
+		_, err := io.WriteString(os.Stdout, content)
+		return err
+	}
+	return nil
+}
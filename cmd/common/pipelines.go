@@ -0,0 +1,46 @@
+package common
+
+import (
+	"os"
+	"strings"
+)
+
+// IsRunningInPipelines tells if the current process runs in a Bitbucket Pipelines build
+func IsRunningInPipelines() bool {
+	_, found := os.LookupEnv("BITBUCKET_BUILD_NUMBER")
+	return found || len(GetPipelinesRepositoryFullName()) > 0
+}
+
+// GetPipelinesRepositoryFullName gets the repository full name (workspace/slug) of the Bitbucket Pipelines build
+//
+// Returns an empty string if not running in Bitbucket Pipelines
+func GetPipelinesRepositoryFullName() string {
+	return strings.TrimSpace(os.Getenv("BITBUCKET_REPO_FULL_NAME"))
+}
+
+// GetPipelinesWorkspace gets the workspace of the Bitbucket Pipelines build
+//
+// Returns an empty string if not running in Bitbucket Pipelines
+func GetPipelinesWorkspace() string {
+	if workspace := strings.TrimSpace(os.Getenv("BITBUCKET_WORKSPACE")); len(workspace) > 0 {
+		return workspace
+	}
+	if components := strings.Split(GetPipelinesRepositoryFullName(), "/"); len(components) == 2 {
+		return components[0]
+	}
+	return ""
+}
+
+// GetPipelinesBranch gets the branch the Bitbucket Pipelines build runs on
+//
+// Returns an empty string if not running in Bitbucket Pipelines or if the build is not for a branch (e.g. a tag)
+func GetPipelinesBranch() string {
+	return strings.TrimSpace(os.Getenv("BITBUCKET_BRANCH"))
+}
+
+// GetPipelinesPullRequestID gets the pull request ID the Bitbucket Pipelines build runs for
+//
+// Returns an empty string if not running in Bitbucket Pipelines or if the build is not for a pull request
+func GetPipelinesPullRequestID() string {
+	return strings.TrimSpace(os.Getenv("BITBUCKET_PR_ID"))
+}
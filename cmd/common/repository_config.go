@@ -0,0 +1,72 @@
+package common
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"gopkg.in/yaml.v3"
+)
+
+// RepositoryConfigFilename is the name of the configuration file of bb in a git repository
+const RepositoryConfigFilename = ".bb.yml"
+
+// RepositoryConfig is the configuration of bb stored in a git repository, in the .bb.yml file at its root
+type RepositoryConfig struct {
+	// ReviewerGroups are named groups of reviewers, given by Account ID, UUID, name, or nickname
+	ReviewerGroups map[string][]string `json:"reviewer_groups,omitempty" yaml:"reviewer_groups,omitempty"`
+	// MergeTemplates are the templates of the merge messages, by merge strategy (merge_commit, squash, fast_forward)
+	MergeTemplates map[string]string `json:"merge_templates,omitempty" yaml:"merge_templates,omitempty"`
+	// CodeOwners tells pullrequest create and update what to do with the code owners of the changed files: none, suggest, or add them as reviewers
+	CodeOwners string `json:"code_owners,omitempty" yaml:"code_owners,omitempty"`
+	// Filename is the file the configuration was loaded from
+	Filename string `json:"-" yaml:"-"`
+}
+
+// LoadRepositoryConfig loads the .bb.yml file of the git repository of the current folder
+//
+// The file is searched in the current folder and its parents, up to the root of the git repository.
+// Returns an errors.NotFound error if there is no such file.
+func LoadRepositoryConfig(ctx context.Context) (*RepositoryConfig, error) {
+	log := logger.Must(logger.FromContext(ctx)).Child("common", "repository_config")
+	folder, err := filepath.Abs(".")
+	if err != nil {
+		return nil, errors.RuntimeError.Wrap(err)
+	}
+
+	for {
+		filename := filepath.Join(folder, RepositoryConfigFilename)
+		content, err := os.ReadFile(filename)
+		if err == nil {
+			var config RepositoryConfig
+			if err := yaml.Unmarshal(content, &config); err != nil {
+				return nil, errors.Join(errors.Errorf("Invalid configuration file %s", filename), err)
+			}
+			config.Filename = filename
+			log.Infof("Loaded repository configuration from %s", filename)
+			return &config, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, errors.RuntimeError.Wrap(err)
+		}
+		if _, err := os.Stat(filepath.Join(folder, ".git")); err == nil {
+			break // this is the root of the git repository
+		}
+		parent := filepath.Dir(folder)
+		if parent == folder {
+			break
+		}
+		folder = parent
+	}
+	return nil, errors.NotFound.With("file", RepositoryConfigFilename)
+}
+
+// GetReviewerGroup gets the reviewers of the given group
+func (config RepositoryConfig) GetReviewerGroup(name string) ([]string, error) {
+	if reviewers, found := config.ReviewerGroups[name]; found {
+		return reviewers, nil
+	}
+	return nil, errors.NotFound.With("reviewer group", name)
+}
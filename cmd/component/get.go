@@ -16,6 +16,7 @@ var getCmd = &cobra.Command{
 	Use:               "get [flags] <component-id>",
 	Aliases:           []string{"show", "info", "display"},
 	Short:             "get a component by its <component-id>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "issue"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: getValidArgs,
 	RunE:              getProcess,
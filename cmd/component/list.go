@@ -14,10 +14,11 @@ import (
 )
 
 var listCmd = &cobra.Command{
-	Use:   "list",
-	Short: "list all components",
-	Args:  cobra.NoArgs,
-	RunE:  listProcess,
+	Use:         "list",
+	Short:       "list all components",
+	Annotations: map[string]string{profile.ScopesAnnotation: "issue"},
+	Args:        cobra.NoArgs,
+	RunE:        listProcess,
 }
 
 var listOptions struct {
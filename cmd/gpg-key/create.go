@@ -14,11 +14,12 @@ import (
 )
 
 var createCmd = &cobra.Command{
-	Use:     "create",
-	Aliases: []string{"add", "new"},
-	Short:   "Add a new GPG key",
-	Args:    cobra.NoArgs,
-	RunE:    createProcess,
+	Use:         "create",
+	Aliases:     []string{"add", "new"},
+	Short:       "Add a new GPG key",
+	Annotations: map[string]string{profile.ScopesAnnotation: "account:write"},
+	Args:        cobra.NoArgs,
+	RunE:        createProcess,
 }
 
 var createOptions struct {
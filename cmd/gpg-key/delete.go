@@ -16,6 +16,7 @@ var deleteCmd = &cobra.Command{
 	Use:               "delete [flags] <fingerprints...>",
 	Aliases:           []string{"remove", "rm"},
 	Short:             "delete GPG keys by their <fingerprint>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "account:write"},
 	Args:              cobra.MinimumNArgs(1),
 	ValidArgsFunction: deleteValidArgs,
 	RunE:              deleteProcess,
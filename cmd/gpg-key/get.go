@@ -15,6 +15,7 @@ var getCmd = &cobra.Command{
 	Use:               "get",
 	Aliases:           []string{"show", "info", "display"},
 	Short:             "get a GPG key by its <fingerprint>",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "account"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: getValidArgs,
 	RunE:              getProcess,
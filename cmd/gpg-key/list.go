@@ -12,10 +12,11 @@ import (
 )
 
 var listCmd = &cobra.Command{
-	Use:   "list",
-	Short: "list all public GPG keys for a given user",
-	Args:  cobra.NoArgs,
-	RunE:  listProcess,
+	Use:         "list",
+	Short:       "list all public GPG keys for a given user",
+	Annotations: map[string]string{profile.ScopesAnnotation: "account"},
+	Args:        cobra.NoArgs,
+	RunE:        listProcess,
 }
 
 var listOptions struct {
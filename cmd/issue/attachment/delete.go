@@ -17,6 +17,7 @@ var deleteCmd = &cobra.Command{
 	Use:               "delete [flags] <path...>",
 	Aliases:           []string{"remove", "rm"},
 	Short:             "delete issue attachments by their <path>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "issue:write"},
 	Args:              cobra.MinimumNArgs(1),
 	ValidArgsFunction: deleteValidArgs,
 	RunE:              deleteProcess,
@@ -14,6 +14,7 @@ var downloadCmd = &cobra.Command{
 	Use:               "download [flags] <path>",
 	Aliases:           []string{"get", "fetch"},
 	Short:             "download an issue attachment by its <path>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "issue"},
 	ValidArgsFunction: downloadValidArgs,
 	Args:              cobra.ExactArgs(1),
 	RunE:              downloadProcess,
@@ -11,11 +11,12 @@ import (
 )
 
 var uploadCmd = &cobra.Command{
-	Use:     "upload [flags] <filename>",
-	Aliases: []string{"add", "create"},
-	Short:   "upload an artifact.",
-	Args:    cobra.ExactArgs(1),
-	RunE:    uploadProcess,
+	Use:         "upload [flags] <filename>",
+	Aliases:     []string{"add", "create"},
+	Short:       "upload an artifact.",
+	Annotations: map[string]string{profile.ScopesAnnotation: "issue:write"},
+	Args:        cobra.ExactArgs(1),
+	RunE:        uploadProcess,
 }
 
 var uploadOptions struct {
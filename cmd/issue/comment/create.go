@@ -15,11 +15,12 @@ type CommentCreator struct {
 }
 
 var createCmd = &cobra.Command{
-	Use:     "create",
-	Aliases: []string{"add", "new"},
-	Short:   "create an issue comment",
-	Args:    cobra.NoArgs,
-	RunE:    createProcess,
+	Use:         "create",
+	Aliases:     []string{"add", "new"},
+	Short:       "create an issue comment",
+	Annotations: map[string]string{profile.ScopesAnnotation: "issue:write"},
+	Args:        cobra.NoArgs,
+	RunE:        createProcess,
 }
 
 var createOptions struct {
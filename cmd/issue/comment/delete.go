@@ -17,6 +17,7 @@ var deleteCmd = &cobra.Command{
 	Use:               "delete [flags] <comment-id...>",
 	Aliases:           []string{"remove", "rm"},
 	Short:             "delete issue comments by their <comment-id>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "issue:write"},
 	Args:              cobra.MinimumNArgs(1),
 	ValidArgsFunction: deleteValidArgs,
 	RunE:              deleteProcess,
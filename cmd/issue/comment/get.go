@@ -16,6 +16,7 @@ var getCmd = &cobra.Command{
 	Use:               "get [flags] <comment-id>",
 	Aliases:           []string{"show", "info", "display"},
 	Short:             "get an issue comment by its <comment-id>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "issue"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: getValidArgs,
 	RunE:              getProcess,
@@ -18,6 +18,7 @@ var updateCmd = &cobra.Command{
 	Use:               "update [flags] <comment-id>",
 	Aliases:           []string{"edit"},
 	Short:             "update an issue comment by its <comment-id>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "issue:write"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: updateValidArgs,
 	RunE:              updateProcess,
@@ -22,11 +22,12 @@ type IssueCreator struct {
 }
 
 var createCmd = &cobra.Command{
-	Use:     "create",
-	Aliases: []string{"add", "new"},
-	Short:   "create an issue",
-	Args:    cobra.NoArgs,
-	RunE:    createProcess,
+	Use:         "create",
+	Aliases:     []string{"add", "new"},
+	Short:       "create an issue",
+	Annotations: map[string]string{profile.ScopesAnnotation: "issue:write"},
+	Args:        cobra.NoArgs,
+	RunE:        createProcess,
 }
 
 var createOptions struct {
@@ -16,6 +16,7 @@ var deleteCmd = &cobra.Command{
 	Use:               "delete [flags] <issue-id...>",
 	Aliases:           []string{"remove", "rm"},
 	Short:             "delete issues by their <issue-id>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "issue:write"},
 	Args:              cobra.MinimumNArgs(1),
 	ValidArgsFunction: deleteValidArgs,
 	RunE:              deleteProcess,
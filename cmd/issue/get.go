@@ -16,6 +16,7 @@ var getCmd = &cobra.Command{
 	Use:               "get [flags] <issue-id>",
 	Aliases:           []string{"show", "info", "display"},
 	Short:             "get an issue by its <issue-id>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "issue"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: getValidArgs,
 	RunE:              getProcess,
@@ -24,6 +25,7 @@ var getCmd = &cobra.Command{
 var getOptions struct {
 	Changes bool
 	Columns *flags.EnumSliceFlag
+	Web     bool
 }
 
 func init() {
@@ -32,6 +34,8 @@ func init() {
 	getOptions.Columns = flags.NewEnumSliceFlag(columns.Columns()...)
 	getCmd.Flags().BoolVar(&getOptions.Changes, "changes", false, "Display changes")
 	getCmd.Flags().Var(getOptions.Columns, "columns", "Comma-separated list of columns to display")
+	getCmd.Flags().BoolVar(&getOptions.Web, "web", false, "Open the issue in the web browser instead of displaying it")
+	getCmd.MarkFlagsMutuallyExclusive("changes", "web")
 	_ = getCmd.RegisterFlagCompletionFunc(getOptions.Columns.CompletionFunc("columns"))
 }
 
@@ -79,5 +83,8 @@ func getProcess(cmd *cobra.Command, args []string) (err error) {
 	if err != nil {
 		return errors.Join(errors.Errorf("Failed to get issue %s", args[0]), err)
 	}
+	if getOptions.Web {
+		return common.Browse(log.ToContext(cmd.Context()), cmd, issue.Links.HTML, false)
+	}
 	return profile.Print(cmd.Context(), cmd, issue)
 }
@@ -12,6 +12,7 @@ import (
 var unvoteCmd = &cobra.Command{
 	Use:               "unvote [flags] <issue-id>",
 	Short:             "remove vote for an issue by its <issue-id>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "issue"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: unvoteValidArgs,
 	RunE:              unvoteProcess,
@@ -12,6 +12,7 @@ import (
 var unwatchCmd = &cobra.Command{
 	Use:               "unwatch [flags] <issue-id>",
 	Short:             "stop watching an issue by its <issue-id>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "issue"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: unwatchValidArgs,
 	RunE:              unwatchProcess,
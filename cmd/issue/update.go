@@ -26,6 +26,7 @@ var updateCmd = &cobra.Command{
 	Use:               "update",
 	Aliases:           []string{"edit"},
 	Short:             "update an issue",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "issue:write"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: updateValidArgs,
 	RunE:              updateProcess,
@@ -12,6 +12,7 @@ import (
 var voteCmd = &cobra.Command{
 	Use:               "vote [flags] <issue-id>",
 	Short:             "vote for an issue by its <issue-id>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "issue"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: voteValidArgs,
 	RunE:              voteProcess,
@@ -14,6 +14,7 @@ import (
 var watchCmd = &cobra.Command{
 	Use:               "watch [flags] <issue-id>",
 	Short:             "watch an issue by its <issue-id>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "issue"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: watchValidArgs,
 	RunE:              watchProcess,
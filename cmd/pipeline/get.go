@@ -17,6 +17,7 @@ var getCmd = &cobra.Command{
 	Use:               "get [flags] <pipeline-uuid-or-build-number>",
 	Aliases:           []string{"show", "info", "display"},
 	Short:             "get a pipeline by its UUID or build number",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pipeline"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: getValidArgs,
 	RunE:              getProcess,
@@ -24,6 +25,7 @@ var getCmd = &cobra.Command{
 
 var getOptions struct {
 	Columns *flags.EnumSliceFlag
+	Web     bool
 }
 
 func init() {
@@ -31,6 +33,7 @@ func init() {
 
 	getOptions.Columns = flags.NewEnumSliceFlag(columns.Columns()...)
 	getCmd.Flags().Var(getOptions.Columns, "columns", "Comma-separated list of columns to display")
+	getCmd.Flags().BoolVar(&getOptions.Web, "web", false, "Open the pipeline in the web browser instead of displaying it")
 	_ = getCmd.RegisterFlagCompletionFunc(getOptions.Columns.CompletionFunc("columns"))
 }
 
@@ -76,5 +79,8 @@ func getProcess(cmd *cobra.Command, args []string) error {
 		return errors.Join(errors.Errorf("failed to get pipeline %s", args[0]), err)
 	}
 
+	if getOptions.Web {
+		return common.Browse(log.ToContext(cmd.Context()), cmd, pipeline.GetHTMLLink(), false)
+	}
 	return profile.Print(cmd.Context(), cmd, pipeline)
 }
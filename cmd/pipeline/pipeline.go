@@ -1,13 +1,18 @@
 package pipeline
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gildas/bitbucket-cli/cmd/common"
 	"github.com/gildas/bitbucket-cli/cmd/pipeline/step"
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/gildas/bitbucket-cli/cmd/repository"
 	"github.com/gildas/bitbucket-cli/cmd/user"
 	"github.com/gildas/go-core"
 	"github.com/gildas/go-errors"
@@ -109,6 +114,48 @@ func (pipeline Pipeline) GetType() string {
 	return "pipeline"
 }
 
+// GetHTMLLink gets the link to the pipeline in the Bitbucket web UI
+//
+// The API does not always return it, in that case it is built from the link of the repository
+func (pipeline Pipeline) GetHTMLLink() *common.Link {
+	if pipeline.Links.HTML != nil {
+		return pipeline.Links.HTML
+	}
+	if pipeline.Repository.Links.HTML == nil {
+		return nil
+	}
+	return &common.Link{HREF: *pipeline.Repository.Links.HTML.HREF.JoinPath("pipelines", "results", fmt.Sprintf("%d", pipeline.BuildNumber))}
+}
+
+// resultsURLPattern matches the URLs of pipeline results in the Bitbucket web UI
+var resultsURLPattern = regexp.MustCompile(`/pipelines/(?:home#!/)?results/(\d+)`)
+
+// GetPipeline gets a pipeline of a repository by its build number or its UUID
+func GetPipeline(ctx context.Context, cmd *cobra.Command, repository *repository.Repository, id string) (*Pipeline, error) {
+	var pipeline Pipeline
+
+	profile, err := profile.GetProfileFromCommand(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	if err := profile.Get(ctx, cmd, repository.GetPath("pipelines", id), &pipeline); err != nil {
+		return nil, err
+	}
+	return &pipeline, nil
+}
+
+// GetBuildNumberFromURL gets the build number of a pipeline from the URL of its results in the Bitbucket web UI
+//
+// This is the URL of the commit statuses reported by Bitbucket Pipelines
+func GetBuildNumberFromURL(url string) (uint64, bool) {
+	matches := resultsURLPattern.FindStringSubmatch(url)
+	if len(matches) < 2 {
+		return 0, false
+	}
+	buildNumber, err := strconv.ParseUint(matches[1], 10, 64)
+	return buildNumber, err == nil
+}
+
 // GetHeaders gets the header for a table
 //
 // implements common.Tableable
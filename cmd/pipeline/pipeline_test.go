@@ -13,7 +13,6 @@ import (
 	"github.com/gildas/bitbucket-cli/cmd/commit"
 	"github.com/gildas/bitbucket-cli/cmd/common"
 	"github.com/gildas/bitbucket-cli/cmd/pipeline"
-	"github.com/gildas/bitbucket-cli/cmd/pullrequest"
 	"github.com/gildas/bitbucket-cli/cmd/user"
 	"github.com/gildas/go-logger"
 	"github.com/google/uuid"
@@ -336,7 +335,7 @@ func (suite *PipelineSuite) TestCanMarshalTriggerBodyWithPullRequestTarget() {
 			Destination:       "main",
 			DestinationCommit: &commit.CommitReference{Hash: "def456ghi789"},
 			Commit:            &commit.CommitReference{Hash: "abc123def456"},
-			PullRequest:       pullrequest.PullRequestReference{ID: 62},
+			PullRequest:       pipeline.PullRequestReference{ID: 62},
 		},
 	}
 
@@ -384,3 +383,48 @@ func (suite *PipelineSuite) TestPipelineStateWithoutResult() {
 	suite.Assert().Equal("IN_PROGRESS", p.State.Name)
 	suite.Assert().Nil(p.State.Result)
 }
+
+func (suite *PipelineSuite) TestCanGetHTMLLink() {
+	payload := []byte(`{
+		"type": "pipeline",
+		"uuid": "{a1b2c3d4-e5f6-7890-abcd-ef1234567890}",
+		"build_number": 42,
+		"state": {
+			"type": "pipeline_state_completed",
+			"name": "COMPLETED",
+			"result": { "type": "pipeline_state_completed_successful", "name": "SUCCESSFUL" }
+		},
+		"target": {
+			"type": "pipeline_ref_target",
+			"ref_type": "branch",
+			"ref_name": "develop"
+		},
+		"created_on": "2024-01-15T10:30:00.000000+00:00",
+		"repository": {
+			"type": "repository",
+			"name": "test-repo",
+			"full_name": "workspace/test-repo",
+			"links": {
+				"html": { "href": "https://bitbucket.org/workspace/test-repo" }
+			}
+		},
+		"links": {}
+	}`)
+	var p pipeline.Pipeline
+	err := json.Unmarshal(payload, &p)
+	suite.Require().NoError(err)
+	link := p.GetHTMLLink()
+	suite.Require().NotNil(link, "The link should be built from the repository")
+	suite.Assert().Equal("https://bitbucket.org/workspace/test-repo/pipelines/results/42", link.HREF.String())
+}
+
+func (suite *PipelineSuite) TestCanGetBuildNumberFromURL() {
+	buildNumber, found := pipeline.GetBuildNumberFromURL("https://bitbucket.org/myworkspace/myrepo/addon/pipelines/home#!/results/42")
+	suite.Assert().True(found)
+	suite.Assert().Equal(uint64(42), buildNumber)
+	buildNumber, found = pipeline.GetBuildNumberFromURL("https://bitbucket.org/myworkspace/myrepo/pipelines/results/7")
+	suite.Assert().True(found)
+	suite.Assert().Equal(uint64(7), buildNumber)
+	_, found = pipeline.GetBuildNumberFromURL("https://ci.example.com/job/42")
+	suite.Assert().False(found)
+}
@@ -18,6 +18,7 @@ var casesCmd = &cobra.Command{
 	Use:               "cases [flags] <pipeline-step-uuid-or-name>",
 	Aliases:           []string{"cases"},
 	Short:             "list the test cases of a pipeline step",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pipeline"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: casesValidArgs,
 	RunE:              casesProcess,
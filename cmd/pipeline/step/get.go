@@ -18,6 +18,7 @@ var getCmd = &cobra.Command{
 	Use:               "get [flags] <pipeline-step-uuid-or-name>",
 	Aliases:           []string{"show", "info", "display"},
 	Short:             "get a pipeline step by its UUID or name",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pipeline"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: getValidArgs,
 	RunE:              getProcess,
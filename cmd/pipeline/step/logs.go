@@ -18,6 +18,7 @@ var logCmd = &cobra.Command{
 	Use:               "logs [flags] <pipeline-step-uuid-or-name>",
 	Aliases:           []string{"log"},
 	Short:             "display the logs of a pipeline step",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pipeline"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: logValidArgs,
 	RunE:              logProcess,
@@ -18,6 +18,7 @@ var reportCmd = &cobra.Command{
 	Use:               "report [flags] <pipeline-step-uuid-or-name>",
 	Aliases:           []string{"report"},
 	Short:             "display the report of a pipeline step",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pipeline"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: reportValidArgs,
 	RunE:              reportProcess,
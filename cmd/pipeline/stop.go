@@ -12,11 +12,12 @@ import (
 )
 
 var stopCmd = &cobra.Command{
-	Use:     "stop [flags] <pipeline-uuid-or-build-number>",
-	Aliases: []string{"cancel", "abort"},
-	Short:   "stop a running pipeline",
-	Args:    cobra.ExactArgs(1),
-	RunE:    stopProcess,
+	Use:         "stop [flags] <pipeline-uuid-or-build-number>",
+	Aliases:     []string{"cancel", "abort"},
+	Short:       "stop a running pipeline",
+	Annotations: map[string]string{profile.ScopesAnnotation: "pipeline:write"},
+	Args:        cobra.ExactArgs(1),
+	RunE:        stopProcess,
 }
 
 func init() {
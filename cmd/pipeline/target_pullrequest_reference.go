@@ -5,18 +5,17 @@ import (
 
 	"github.com/gildas/bitbucket-cli/cmd/commit"
 	"github.com/gildas/bitbucket-cli/cmd/common"
-	"github.com/gildas/bitbucket-cli/cmd/pullrequest"
 	"github.com/gildas/go-errors"
 )
 
 // PullRequestReferenceTarget represents a target for a pipeline that is a pull request reference.
 type PullRequestReferenceTarget struct {
-	Source            string                           `json:"source"                       mapstructure:"source"`
-	Destination       string                           `json:"destination"                  mapstructure:"destination"`
-	DestinationCommit *commit.CommitReference          `json:"destination_commit,omitempty" mapstructure:"destination_commit"`
-	Commit            *commit.CommitReference          `json:"commit,omitempty"             mapstructure:"commit"`
-	Selector          *common.Selector                 `json:"selector,omitempty"           mapstructure:"selector"`
-	PullRequest       pullrequest.PullRequestReference `json:"pullrequest"                  mapstructure:"pullrequest"`
+	Source            string                  `json:"source"                       mapstructure:"source"`
+	Destination       string                  `json:"destination"                  mapstructure:"destination"`
+	DestinationCommit *commit.CommitReference `json:"destination_commit,omitempty" mapstructure:"destination_commit"`
+	Commit            *commit.CommitReference `json:"commit,omitempty"             mapstructure:"commit"`
+	Selector          *common.Selector        `json:"selector,omitempty"           mapstructure:"selector"`
+	PullRequest       PullRequestReference    `json:"pullrequest"                  mapstructure:"pullrequest"`
 }
 
 func init() {
@@ -22,11 +22,12 @@ type TriggerBody struct {
 }
 
 var triggerCmd = &cobra.Command{
-	Use:     "trigger",
-	Aliases: []string{"run", "start", "create"},
-	Short:   "trigger a new pipeline",
-	Args:    cobra.NoArgs,
-	RunE:    triggerProcess,
+	Use:         "trigger",
+	Aliases:     []string{"run", "start", "create"},
+	Short:       "trigger a new pipeline",
+	Annotations: map[string]string{profile.ScopesAnnotation: "pipeline:write"},
+	Args:        cobra.NoArgs,
+	RunE:        triggerProcess,
 }
 
 var triggerOptions struct {
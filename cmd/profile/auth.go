@@ -0,0 +1,97 @@
+package profile
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+)
+
+const (
+	// AuthAppPassword authenticates with a user and an app password (deprecated by Atlassian)
+	AuthAppPassword = "app-password"
+	// AuthOAuth authenticates with an OAuth consumer (client credentials or authorization code grant)
+	AuthOAuth = "oauth"
+	// AuthAccessToken authenticates with a Repository/Project/Workspace Access Token
+	AuthAccessToken = "access-token"
+	// AuthAPIToken authenticates with the email of an Atlassian account and one of its API tokens
+	AuthAPIToken = "api-token"
+)
+
+// AuthModes are the supported authentication modes
+var AuthModes = []string{AuthAppPassword, AuthOAuth, AuthAccessToken, AuthAPIToken}
+
+// GetAuthMode gets the authentication mode of this profile
+//
+// If the profile does not set its mode, it is deduced from its credentials
+func (profile Profile) GetAuthMode() string {
+	switch {
+	case len(profile.Auth) > 0:
+		return profile.Auth
+	case len(profile.User) > 0: // checked first, like before the auth modes, so older profiles keep their mode
+		return AuthAppPassword
+	case len(profile.Email) > 0:
+		return AuthAPIToken
+	case len(profile.ClientID) > 0:
+		return AuthOAuth
+	default:
+		return AuthAccessToken
+	}
+}
+
+// GetCredentialModes gets the authentication modes this profile has credentials for
+//
+// When there are several and the profile does not set its mode, GetAuthMode picks one of them
+func (profile Profile) GetCredentialModes() []string {
+	modes := []string{}
+	if len(profile.User) > 0 {
+		modes = append(modes, AuthAppPassword)
+	}
+	if len(profile.Email) > 0 {
+		modes = append(modes, AuthAPIToken)
+	}
+	if len(profile.ClientID) > 0 {
+		modes = append(modes, AuthOAuth)
+	}
+	return modes
+}
+
+// GetAPIToken gets the Atlassian API token from the profile, either from the vault or from the profile
+func (profile *Profile) GetAPIToken(ctx context.Context) (apiToken string, err error) {
+	log := logger.Must(logger.FromContext(ctx)).Child("profile", "getAPIToken")
+	if len(profile.APIToken) > 0 {
+		log.Debugf("API token for profile %s is set in the profile", profile.Name)
+		return profile.APIToken, nil
+	}
+	if credential, err := profile.GetCredentialFromVault(profile.VaultKey, profile.Email); err == nil {
+		log.Debugf("Loaded API token for %s from the vault", profile.Email)
+		return credential.Password, nil
+	}
+	return "", errors.Join(errors.Errorf("Profile %s does not have an API token", profile.Name), err)
+}
+
+// validateAuth validates the authentication mode of this profile
+func (profile Profile) validateAuth() error {
+	var merr errors.MultiError
+
+	switch profile.Auth {
+	case "":
+	case AuthAPIToken:
+		if len(profile.Email) == 0 {
+			merr.Append(errors.ArgumentMissing.With("email"))
+		} else if !strings.Contains(profile.Email, "@") {
+			merr.Append(errors.ArgumentInvalid.With("email", profile.Email))
+		}
+		if len(profile.User) > 0 || len(profile.ClientID) > 0 || len(profile.AccessToken) > 0 {
+			merr.Append(errors.Errorf("Profile %s uses API tokens, it cannot have a user, a client ID, or an access token", profile.Name))
+		}
+	case AuthAppPassword, AuthOAuth, AuthAccessToken:
+		if len(profile.Email) > 0 || len(profile.APIToken) > 0 {
+			merr.Append(errors.Errorf("Profile %s uses %s, it cannot have an email or an API token", profile.Name, profile.Auth))
+		}
+	default:
+		merr.Append(errors.ArgumentInvalid.With("auth", profile.Auth))
+	}
+	return merr.AsError()
+}
@@ -0,0 +1,82 @@
+package profile_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/spf13/cobra"
+)
+
+func (suite *ProfileSuite) TestCanDiagnoseAPITokenProfile() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		email, token, ok := r.BasicAuth()
+		if r.URL.Path == "/2.0/user" && ok && email == "john@acme.com" && token == "dummy-api-token" {
+			_ = json.NewEncoder(w).Encode(map[string]string{"username": "john", "display_name": "John Doe"})
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	apiRoot, err := url.Parse(server.URL)
+	suite.Require().NoError(err)
+	current := &profile.Profile{Name: "api-token", APIRoot: apiRoot, Auth: profile.AuthAPIToken, Email: "john@acme.com", APIToken: "dummy-api-token"}
+	suite.Assert().Equal(profile.AuthAPIToken, current.GetAuthMode())
+
+	cmd := &cobra.Command{}
+	cmd.Flags().String("git-remote", "", "")
+	checks := current.Diagnose(suite.Context, cmd)
+	suite.Assert().Equal(0, checks.Failures(), "No check should fail: %v", checks)
+
+	statuses := map[string]string{}
+	for _, check := range checks {
+		statuses[check.Name] = check.Status
+	}
+	suite.Assert().Equal(profile.DoctorPass, statuses["auth"])
+	suite.Assert().Equal(profile.DoctorSkip, statuses["token"])
+	suite.Assert().Equal(profile.DoctorPass, statuses["user"])
+}
+
+func (suite *ProfileSuite) TestShouldNotValidateAPITokenProfileWithoutEmail() {
+	current := &profile.Profile{Name: "api-token", Auth: profile.AuthAPIToken, APIToken: "dummy-api-token"}
+	suite.Assert().Error(current.Validate(), "An API token profile needs an email")
+
+	current = &profile.Profile{Name: "api-token", Auth: profile.AuthAPIToken, Email: "john@acme.com", User: "john"}
+	suite.Assert().Error(current.Validate(), "An API token profile cannot have a user")
+
+	current = &profile.Profile{Name: "app-password", User: "john", Password: "s3cr3t"}
+	suite.Require().NoError(current.Validate())
+	suite.Assert().Equal(profile.AuthAppPassword, current.GetAuthMode())
+}
+
+func (suite *ProfileSuite) TestShouldKeepTheAuthModeOfOlderProfiles() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusUnauthorized) }))
+	defer server.Close()
+	apiRoot, err := url.Parse(server.URL)
+	suite.Require().NoError(err)
+
+	current := &profile.Profile{Name: "both", APIRoot: apiRoot, User: "john", Password: "s3cr3t", ClientID: "client", ClientSecret: "secret"}
+	suite.Assert().Equal(profile.AuthAppPassword, current.GetAuthMode(), "Profiles with a user used their app password before the auth modes")
+	suite.Assert().Equal([]string{profile.AuthAppPassword, profile.AuthOAuth}, current.GetCredentialModes())
+
+	cmd := &cobra.Command{}
+	cmd.Flags().String("git-remote", "", "")
+	checks := current.Diagnose(suite.Context, cmd)
+	warned := false
+	for _, check := range checks {
+		if check.Name == "auth" && check.Status == profile.DoctorWarn && strings.Contains(check.Message, "credentials for app-password, oauth") {
+			warned = true
+		}
+	}
+	suite.Assert().True(warned, "The doctor should flag the ambiguous credentials: %v", checks)
+
+	current = &profile.Profile{Name: "oauth", ClientID: "client", ClientSecret: "secret"}
+	suite.Assert().Equal(profile.AuthOAuth, current.GetAuthMode())
+
+	current = &profile.Profile{Name: "explicit", Auth: profile.AuthOAuth, User: "john", ClientID: "client", ClientSecret: "secret"}
+	suite.Assert().Equal(profile.AuthOAuth, current.GetAuthMode(), "The auth of the profile wins")
+}
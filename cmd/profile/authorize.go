@@ -4,10 +4,6 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
-	"os"
-	"os/exec"
-	"runtime"
-	"strings"
 	"time"
 
 	"github.com/briandowns/spinner"
@@ -15,7 +11,6 @@ import (
 	"github.com/gildas/go-errors"
 	"github.com/gildas/go-logger"
 	"github.com/spf13/cobra"
-	"gopkg.in/ini.v1"
 )
 
 var authorizeCmd = &cobra.Command{
@@ -93,7 +88,7 @@ func authorizeProcess(cmd *cobra.Command, args []string) (err error) {
 		spinner.Start()
 	}
 
-	err = openBrowser(bitbucketAuthURL)
+	err = common.OpenBrowser(bitbucketAuthURL)
 	if err != nil {
 		log.Warnf("Failed to open browser: %s", err.Error())
 		if cmd.Flag("stop-on-error").Value.String() == "true" {
@@ -120,43 +115,3 @@ func authorizeProcess(cmd *cobra.Command, args []string) (err error) {
 	common.Verbose(ctx, cmd, "Authorization process completed successfully")
 	return nil
 }
-
-// openBrowser opens the specified URL in the default web browser
-func openBrowser(url url.URL) error {
-	var cmd string
-	var args []string
-
-	switch runtime.GOOS {
-	case "linux":
-		cmd = "xdg-open"
-		if _, exists := os.LookupEnv("SSH_CONNECTION"); exists {
-			return errors.New("Cannot open browser in SSH session")
-		}
-		if common.IsWSL() {
-			// If the flag interop=true is not set in /etc/wsl.conf, return an error
-			if content, err := os.ReadFile("/etc/wsl.conf"); err == nil {
-				if data, err := ini.Load(content); err == nil {
-					if section, err := data.GetSection("interop"); err == nil {
-						if key, err := section.GetKey("enabled"); err == nil {
-							if strings.ToLower(key.String()) != "true" {
-								return errors.New("Cannot open browser in WSL without interop enabled")
-							}
-						}
-					}
-				}
-			}
-			cmd = "cmd.exe"
-			args = append(args, "/C", "start")
-		}
-	case "windows":
-		cmd = "rundll32"
-		args = append(args, "url.dll,FileProtocolHandler")
-	case "darwin":
-		cmd = "open"
-	default:
-		return fmt.Errorf("unsupported platform")
-	}
-
-	args = append(args, `"`+url.String()+`"`)
-	return exec.Command(cmd, args...).Start()
-}
@@ -29,6 +29,7 @@ var createOptions struct {
 	DefaultProject   string
 	OutputFormat     *flags.EnumFlag
 	CloneProtocol    *flags.EnumFlag
+	SecretStore      *flags.EnumFlag
 	NoVault          bool
 }
 
@@ -37,12 +38,15 @@ func init() {
 
 	createOptions.OutputFormat = flags.NewEnumFlag("json", "yaml", "table")
 	createOptions.CloneProtocol = flags.NewEnumFlag("+git", "https", "ssh")
+	createOptions.SecretStore = flags.NewEnumFlag(SecretStoreNames...)
 	createCmd.Flags().StringVarP(&createOptions.Name, "name", "n", "", "Name of the profile")
 	createCmd.Flags().StringVar(&createOptions.Description, "description", "", "Description of the profile")
 	createCmd.Flags().BoolVar(&createOptions.Default, "default", false, "True if this is the default profile")
 	if runtime.GOOS != "windows" {
 		createCmd.Flags().StringVar(&createOptions.VaultKey, "vault-key", "bitbucket-cli", "Vault key to use for storing credentials. Default is bitbucket-cli. On Windows, the Windows Credential Manager will be used, On Linux and macOS, the system keychain will be used.")
 	}
+	createCmd.Flags().Var(createOptions.SecretStore, "secret-store", "Secret store to use for storing credentials (keyring, file, pass, command). Default is the keyring, or the encrypted file when no keyring is available.")
+	createCmd.Flags().StringVar(&createOptions.SecretCommand, "secret-command", "", "Command to call for storing credentials when the secret store is command.")
 	createCmd.Flags().BoolVar(&createOptions.NoVault, "no-vault", false, "Do not store credentials in the vault. This will store them in plain text in the configuration file.")
 	createCmd.Flags().StringVarP(&createOptions.User, "user", "u", "", "User's name of the profile")
 	createCmd.Flags().StringVar(&createOptions.Password, "password", "", "Password of the profile")
@@ -50,6 +54,8 @@ func init() {
 	createCmd.Flags().StringVar(&createOptions.ClientSecret, "client-secret", "", "Client Secret of the profile")
 	createCmd.Flags().Uint16Var(&createOptions.CallbackPort, "callback-port", 0, "Port to listen to for the Authorization Code Grant")
 	createCmd.Flags().StringVar(&createOptions.AccessToken, "access-token", "", "Access Token of the profile")
+	createCmd.Flags().StringVar(&createOptions.Email, "email", "", "Email of the Atlassian account that owns the API token")
+	createCmd.Flags().StringVar(&createOptions.APIToken, "api-token", "", "Atlassian API token of the profile")
 	createCmd.Flags().StringVar(&createOptions.DefaultWorkspace, "default-workspace", "", "Default workspace of the profile")
 	createCmd.Flags().StringVar(&createOptions.DefaultProject, "default-project", "", "Default project of the profile")
 	createCmd.Flags().Var(createOptions.CloneProtocol, "clone-protocol", "Default protocol to use for cloning repositories. Default is git, can be https, git, or ssh")
@@ -63,10 +69,13 @@ func init() {
 	_ = createCmd.MarkFlagFilename("default-ssh-key-file")
 	createCmd.MarkFlagsRequiredTogether("user", "password")
 	createCmd.MarkFlagsRequiredTogether("client-id", "client-secret")
-	createCmd.MarkFlagsMutuallyExclusive("user", "client-id", "access-token")
+	createCmd.MarkFlagsRequiredTogether("email", "api-token")
+	createCmd.MarkFlagsMutuallyExclusive("user", "client-id", "access-token", "email")
 	if runtime.GOOS != "windows" {
 		createCmd.MarkFlagsMutuallyExclusive("vault-key", "no-vault")
 	}
+	createCmd.MarkFlagsMutuallyExclusive("secret-store", "no-vault")
+	_ = createCmd.RegisterFlagCompletionFunc(createOptions.SecretStore.CompletionFunc("secret-store"))
 	_ = createCmd.RegisterFlagCompletionFunc(createOptions.CloneProtocol.CompletionFunc("clone-protocol"))
 	_ = createCmd.RegisterFlagCompletionFunc(createOptions.OutputFormat.CompletionFunc("output"))
 	_ = createCmd.RegisterFlagCompletionFunc("error-processing", createOptions.ErrorProcessing.CompletionFunc())
@@ -94,6 +103,12 @@ func createProcess(cmd *cobra.Command, args []string) (err error) {
 	if len(createOptions.CloneProtocol.String()) > 0 {
 		createOptions.Profile.CloneProtocol = createOptions.CloneProtocol.String()
 	}
+	if len(createOptions.SecretStore.String()) > 0 {
+		createOptions.Profile.SecretStore = createOptions.SecretStore.String()
+	}
+	if len(createOptions.Email) > 0 {
+		createOptions.Profile.Auth = AuthAPIToken
+	}
 	log.Infof("Creating profile %s", createOptions.Name)
 	if err := createOptions.Validate(); err != nil {
 		return err
@@ -106,23 +121,41 @@ func createProcess(cmd *cobra.Command, args []string) (err error) {
 		return nil
 	}
 
-	if common.IsWSL() {
-		// For now, we do not support vaults in WSL.
-		log.Warnf("Vaults are not supported in WSL, the credentials will be stored in plain text in the configuration file")
+	if common.IsWSL() && createOptions.GetSecretStoreName() == "keyring" {
+		// For now, we do not support the keyring in WSL.
+		log.Warnf("Vaults are not supported in WSL, the credentials will be stored in plain text in the configuration file, use --secret-store file to encrypt them instead")
 		createOptions.NoVault = true
 	}
 
 	// Store the client secret/password/access token in the vault if provided
 	if createOptions.NoVault {
-		if len(createOptions.ClientID) > 0 && len(createOptions.ClientSecret) == 0 {
+		if len(createOptions.Email) > 0 && len(createOptions.APIToken) == 0 {
+			return errors.ArgumentMissing.With("apiToken", "An API token is required when using an email since it is not stored in the vault.")
+		} else if len(createOptions.ClientID) > 0 && len(createOptions.ClientSecret) == 0 {
 			return errors.ArgumentMissing.With("clientSecret", "A client secret is required when using a client ID since it is not stored in the vault.")
 		} else if len(createOptions.User) > 0 && len(createOptions.Password) == 0 {
 			return errors.ArgumentMissing.With("password", "A password is required when using a user since it is not stored in the vault.")
-		} else if len(createOptions.ClientID) == 0 && len(createOptions.User) == 0 && len(createOptions.AccessToken) == 0 {
+		} else if len(createOptions.ClientID) == 0 && len(createOptions.User) == 0 && len(createOptions.Email) == 0 && len(createOptions.AccessToken) == 0 {
 			return errors.ArgumentMissing.With("accessToken", "An access token is required when using a user since it is not stored in the vault")
 		}
 	} else {
-		if len(createOptions.ClientID) > 0 {
+		if len(createOptions.Email) > 0 {
+			if len(createOptions.APIToken) > 0 {
+				if err := createOptions.SetCredentialInVault(createOptions.VaultKey, createOptions.Email, createOptions.APIToken); err != nil {
+					log.Errorf("Failed to store API token in the %s vault, the token will be stored in plain text in the configuration file", createOptions.VaultKey, err)
+					fmt.Fprintf(os.Stderr, "Failed to store API token in the %s vault, the token will be stored in plain text in the configuration file: %s\n", createOptions.VaultKey, err)
+				} else {
+					log.Infof("Stored API token in the %s vault for %s", createOptions.VaultKey, createOptions.Email)
+					createOptions.APIToken = "" // Clear the API token from the profile
+				}
+			} else {
+				if credential, err := createOptions.GetCredentialFromVault(createOptions.VaultKey, createOptions.Email); err == nil {
+					createOptions.APIToken = credential.Password
+				} else {
+					return errors.New("An API token is required when using an email since it is not stored in the vault. Please provide it with --api-token")
+				}
+			}
+		} else if len(createOptions.ClientID) > 0 {
 			if len(createOptions.ClientSecret) > 0 {
 				if err := createOptions.SetCredentialInVault(createOptions.VaultKey, createOptions.ClientID, createOptions.ClientSecret); err != nil {
 					log.Errorf("Failed to store client secret in the %s vault, the secret will be stored in plain text in the configuration file", createOptions.VaultKey, err)
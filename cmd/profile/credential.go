@@ -1,9 +1,13 @@
 package profile
 
 import (
+	"context"
+	"fmt"
+	"os"
+
 	"github.com/gildas/go-errors"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
-	"github.com/zalando/go-keyring"
+	"golang.org/x/term"
 )
 
 // Credential represents a user credential for authentication.
@@ -20,11 +24,17 @@ func (credential *Credential) AsHTTPBasicAuth() *http.BasicAuth {
 	}
 }
 
-// GetCredentialFromVault retrieves the credential for the given key from the Windows Credential Manager or Linux/macOS keychain.
+// GetCredentialFromVault retrieves the credential for the given key from the secret store of the profile
+//
+// By default, the secret store is the Windows Credential Manager or Linux/macOS keychain.
 func (profile Profile) GetCredentialFromVault(service, username string) (credential *Credential, err error) {
-	secret, err := keyring.Get(service, username)
+	store, err := profile.GetSecretStore()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := store.Get(service, username)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get secret from keyring")
+		return nil, err
 	}
 	if secret == "" {
 		return nil, errors.NotFound.With("key", service)
@@ -32,18 +42,64 @@ func (profile Profile) GetCredentialFromVault(service, username string) (credent
 	return &Credential{Username: username, Password: secret}, nil
 }
 
-// SetCredentialInVault stores the credential in the Windows Credential Manager or Linux/macOS keychain.
+// SetCredentialInVault stores the credential in the secret store of the profile
 func (profile Profile) SetCredentialInVault(service, username, password string) error {
-	if err := keyring.Set(service, username, password); err != nil {
-		return errors.Wrap(err, "failed to set secret in keyring")
+	store, err := profile.GetSecretStore()
+	if err != nil {
+		return err
 	}
-	return nil
+	return store.Set(service, username, password)
 }
 
-// DeleteCredentialFromVault removes the credential from the Windows Credential Manager or Linux/macOS keychain.
+// DeleteCredentialFromVault removes the credential from the secret store of the profile
 func (profile Profile) DeleteCredentialFromVault(service, username string) error {
-	if err := keyring.Delete(service, username); err != nil {
-		return errors.Wrap(err, "failed to delete secret from keyring")
+	store, err := profile.GetSecretStore()
+	if err != nil {
+		return err
+	}
+	return store.Delete(service, username)
+}
+
+// readSecretFromTerminal prompts for a secret on the terminal without echoing it
+//
+// returns errors.ArgumentMissing if the standard input is not a terminal or the secret is empty
+func readSecretFromTerminal(prompt string) (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", errors.ArgumentMissing.With("secret")
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	secret, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", errors.RuntimeError.Wrap(err)
+	}
+	if len(secret) == 0 {
+		return "", errors.ArgumentMissing.With("secret")
+	}
+	return string(secret), nil
+}
+
+// GetGitCredential gets the credential to use with git over https for this profile
+//
+// Bitbucket accepts tokens with a fixed username: x-bitbucket-api-token-auth for API tokens and x-token-auth for access tokens
+func (profile *Profile) GetGitCredential(ctx context.Context) (*Credential, error) {
+	switch profile.GetAuthMode() {
+	case AuthAPIToken:
+		apiToken, err := profile.GetAPIToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &Credential{Username: "x-bitbucket-api-token-auth", Password: apiToken}, nil
+	case AuthAppPassword:
+		password, err := profile.GetPassword(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &Credential{Username: profile.User, Password: password}, nil
+	default:
+		if _, err := profile.authorize(ctx); err != nil {
+			return nil, err
+		}
+		return &Credential{Username: "x-token-auth", Password: profile.token.AccessToken}, nil
 	}
-	return nil
 }
@@ -61,7 +61,10 @@ func deleteProcess(cmd *cobra.Command, args []string) (err error) {
 			for _, profileName := range Profiles.Names() {
 				if profile, found := Profiles.Find(profileName); found {
 					log.Infof("Deleting credential for profile %s", profile.Name)
-					if len(profile.ClientID) > 0 {
+					if profile.GetAuthMode() == AuthAPIToken {
+						_ = profile.DeleteCredentialFromVault(profile.VaultKey, profile.Email)
+						log.Debugf("Deleted API token for %s from the %s vault", profile.Email, profile.VaultKey)
+					} else if len(profile.ClientID) > 0 {
 						_ = profile.DeleteCredentialFromVault(profile.VaultKey, profile.ClientID)
 						log.Debugf("Deleted client secret for clientID %s from the vault", profile.ClientID)
 					} else if len(profile.User) > 0 {
@@ -71,6 +74,7 @@ func deleteProcess(cmd *cobra.Command, args []string) (err error) {
 						_ = profile.DeleteCredentialFromVault(profile.VaultKey, profile.Name)
 						log.Debugf("Deleted name secret for profile %s from the vault", profile.Name)
 					}
+					_ = profile.deleteCachedAccessToken()
 				}
 			}
 			deleted = Profiles.Delete(Profiles.Names()...)
@@ -80,7 +84,10 @@ func deleteProcess(cmd *cobra.Command, args []string) (err error) {
 			for _, profileName := range args {
 				if profile, found := Profiles.Find(profileName); found {
 					log.Infof("Deleting credential for profile %s", profile.Name)
-					if len(profile.ClientID) > 0 {
+					if profile.GetAuthMode() == AuthAPIToken {
+						_ = profile.DeleteCredentialFromVault(profile.VaultKey, profile.Email)
+						log.Debugf("Deleted API token for %s from the %s vault", profile.Email, profile.VaultKey)
+					} else if len(profile.ClientID) > 0 {
 						_ = profile.DeleteCredentialFromVault(profile.VaultKey, profile.ClientID)
 						log.Debugf("Deleted client secret for clientID %s from the %s vault", profile.ClientID, profile.VaultKey)
 					} else if len(profile.User) > 0 {
@@ -90,6 +97,7 @@ func deleteProcess(cmd *cobra.Command, args []string) (err error) {
 						_ = profile.DeleteCredentialFromVault(profile.VaultKey, profile.Name)
 						log.Debugf("Deleted name secret for profile %s from the %s vault", profile.Name, profile.VaultKey)
 					}
+					_ = profile.deleteCachedAccessToken()
 				}
 			}
 			deleted = Profiles.Delete(args...)
@@ -0,0 +1,267 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gildas/bitbucket-cli/cmd/remote"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// DoctorCheck describes the result of a diagnostic check
+type DoctorCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// DoctorChecks is a collection of DoctorCheck
+type DoctorChecks []DoctorCheck
+
+const (
+	DoctorPass = "pass" // DoctorPass tells the check passed
+	DoctorWarn = "warn" // DoctorWarn tells the check passed with a warning
+	DoctorFail = "fail" // DoctorFail tells the check failed
+	DoctorSkip = "skip" // DoctorSkip tells the check was not relevant
+)
+
+var doctorCmd = &cobra.Command{
+	Use:               "doctor [flags] [profile-name]",
+	Short:             "diagnose a profile (config, vault, token, scopes, git remote)",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: ValidProfileNames,
+	PreRunE:           disableUnsupportedFlags,
+	RunE:              doctorProcess,
+}
+
+func init() {
+	Command.AddCommand(doctorCmd)
+
+	doctorCmd.SetHelpFunc(hideUnsupportedFlags)
+}
+
+func doctorProcess(cmd *cobra.Command, args []string) (err error) {
+	log := logger.Must(logger.FromContext(cmd.Context())).Child(cmd.Parent().Name(), "doctor")
+	ctx := log.ToContext(cmd.Context())
+
+	checks := DoctorChecks{}
+	profile, err := GetProfileFromCommand(ctx, cmd)
+	if len(args) > 0 {
+		var found bool
+		if profile, found = Profiles.Find(args[0]); found {
+			err = nil
+		} else if err == nil {
+			err = errors.NotFound.With("profile", args[0])
+		}
+	}
+	if err != nil {
+		checks.Add("config", DoctorFail, fmt.Sprintf("Cannot load a profile from %s: %s", viper.ConfigFileUsed(), err))
+		_ = Profile{}.Print(ctx, cmd, checks)
+		return errors.Errorf("%d check(s) failed", checks.Failures())
+	}
+	if profile.IsFromEnvironment() {
+		checks.Add("config", DoctorPass, "Profile is assembled from the environment variables")
+	} else {
+		checks.Add("config", DoctorPass, fmt.Sprintf("Loaded %d profile(s) from %s", len(Profiles), viper.ConfigFileUsed()))
+	}
+
+	checks = append(checks, profile.Diagnose(ctx, cmd)...)
+	if err = profile.Print(ctx, cmd, checks); err != nil {
+		return err
+	}
+	if failures := checks.Failures(); failures > 0 {
+		return errors.Errorf("%d check(s) failed for profile %s", failures, profile.Name)
+	}
+	return nil
+}
+
+// Diagnose runs the diagnostic checks of this profile
+//
+// The checks are: profile validation, authentication mode, vault access, token load and refresh, the /user call, the token scopes and the current git remote
+func (profile *Profile) Diagnose(ctx context.Context, cmd *cobra.Command) (checks DoctorChecks) {
+	log := logger.Must(logger.FromContext(ctx)).Child("profile", "diagnose", "profile", profile.Name)
+
+	if err := profile.Validate(); err != nil {
+		checks.Add("profile", DoctorFail, err.Error())
+	} else {
+		checks.Add("profile", DoctorPass, fmt.Sprintf("Profile %s is valid", profile.Name))
+	}
+
+	if modes := profile.GetCredentialModes(); len(profile.Auth) == 0 && len(modes) > 1 {
+		checks.Add("auth", DoctorWarn, fmt.Sprintf("The profile has credentials for %s and uses %s, set its auth to the mode it should use", strings.Join(modes, ", "), profile.GetAuthMode()))
+	}
+	switch profile.GetAuthMode() {
+	case AuthAppPassword:
+		if strings.Contains(profile.User, "@") {
+			checks.Add("auth", DoctorWarn, fmt.Sprintf("The API token of %s is used as a password, convert the profile with bb profile migrate-auth", profile.User))
+		} else {
+			checks.Add("auth", DoctorWarn, "App passwords are being replaced by API tokens, convert the profile with bb profile migrate-auth")
+		}
+	case AuthAPIToken:
+		checks.Add("auth", DoctorPass, fmt.Sprintf("Authenticating with an API token of %s", profile.Email))
+	default:
+		checks.Add("auth", DoctorPass, fmt.Sprintf("Authenticating with %s", profile.GetAuthMode()))
+	}
+
+	vaultFailed := false
+	for _, secret := range profile.getSecrets() {
+		switch {
+		case profile.fromEnvironment:
+			checks.Add("vault", DoctorPass, fmt.Sprintf("The %s comes from the environment", secret.Description))
+		case len(secret.Value) > 0:
+			checks.Add("vault", DoctorWarn, fmt.Sprintf("The %s is stored in clear text in %s, use bb profile update --to-vault", secret.Description, viper.ConfigFileUsed()))
+		default:
+			if _, err := profile.GetCredentialFromVault(profile.VaultKey, secret.Key); err != nil {
+				log.Errorf("Failed to get the %s from the vault", secret.Description, err)
+				checks.Add("vault", DoctorFail, fmt.Sprintf("Cannot read the %s from the vault %s in the %s secret store: %s", secret.Description, profile.VaultKey, profile.GetSecretStoreName(), err))
+				vaultFailed = true
+			} else {
+				checks.Add("vault", DoctorPass, fmt.Sprintf("The %s is in the vault %s of the %s secret store", secret.Description, profile.VaultKey, profile.GetSecretStoreName()))
+			}
+		}
+	}
+
+	if mode := profile.GetAuthMode(); (mode == AuthOAuth || mode == AuthAccessToken) && !vaultFailed {
+		if err := profile.loadAccessToken(ctx); err != nil {
+			checks.Add("token", DoctorFail, fmt.Sprintf("Cannot load the access token: %s", err))
+		} else if profile.token == nil {
+			checks.Add("token", DoctorWarn, "No access token in the cache, a new one will be requested")
+		} else if profile.isTokenExpired() {
+			checks.Add("token", DoctorWarn, fmt.Sprintf("The access token expired %s ago", profile.token.GetExpiredSince().Round(time.Second)))
+		} else {
+			checks.Add("token", DoctorPass, fmt.Sprintf("The access token expires in %s", profile.token.GetExpiresIn().Round(time.Second)))
+		}
+		if len(profile.ClientID) > 0 {
+			if _, err := profile.authorize(ctx); err != nil {
+				checks.Add("token", DoctorFail, fmt.Sprintf("Cannot authorize with client %s: %s", profile.ClientID, err))
+			} else {
+				checks.Add("token", DoctorPass, "Authorized with Bitbucket")
+			}
+		}
+	} else {
+		checks.Add("token", DoctorSkip, "The profile does not use access tokens")
+	}
+
+	if vaultFailed {
+		checks.Add("user", DoctorSkip, "Cannot call Bitbucket without credentials")
+		return checks
+	}
+
+	var user struct {
+		Username string `json:"username"`
+		Name     string `json:"display_name"`
+	}
+	if err := profile.Get(ctx, cmd, "/user", &user); err != nil {
+		if len(profile.AccessToken) > 0 {
+			// Repository/Project/Workspace Access Tokens are not tied to a user
+			checks.Add("user", DoctorWarn, fmt.Sprintf("Cannot get the current user (expected with access tokens): %s", err))
+		} else {
+			checks.Add("user", DoctorFail, fmt.Sprintf("Cannot get the current user: %s", err))
+		}
+	} else {
+		checks.Add("user", DoctorPass, fmt.Sprintf("Authenticated as %s (%s)", user.Name, user.Username))
+	}
+
+	if profile.token != nil && len(strings.TrimSpace(profile.token.Scope)) > 0 {
+		checks.Add("scopes", DoctorPass, fmt.Sprintf("Granted scopes: %s", strings.Join(profile.token.GetScopes(), ", ")))
+	} else if profile.GetAuthMode() == AuthAppPassword {
+		checks.Add("scopes", DoctorSkip, "App passwords do not report their scopes")
+	} else if profile.GetAuthMode() == AuthAPIToken {
+		checks.Add("scopes", DoctorSkip, "The scopes of the API token were not reported")
+	} else {
+		checks.Add("scopes", DoctorWarn, "The granted scopes are unknown")
+	}
+
+	if gitRemote, err := remote.GetRemote(ctx, cmd); err != nil {
+		checks.Add("repository", DoctorSkip, "The current folder is not a Bitbucket git repository")
+	} else {
+		var repository struct {
+			FullName string `json:"full_name"`
+		}
+		if err := profile.Get(ctx, cmd, "/repositories/"+gitRemote.RepositoryName(), &repository); err != nil {
+			checks.Add("repository", DoctorFail, fmt.Sprintf("Cannot access repository %s from remote %s: %s", gitRemote.RepositoryName(), gitRemote.URL, err))
+		} else {
+			checks.Add("repository", DoctorPass, fmt.Sprintf("Repository %s is accessible", repository.FullName))
+		}
+	}
+	return checks
+}
+
+// profileSecret describes a secret of a profile and the vault key it is stored under
+type profileSecret struct {
+	Description string
+	Key         string
+	Value       string
+}
+
+// getSecrets gets the secrets this profile needs according to its authentication mode
+func (profile Profile) getSecrets() []profileSecret {
+	switch {
+	case profile.GetAuthMode() == AuthAPIToken:
+		return []profileSecret{{Description: "API token", Key: profile.Email, Value: profile.APIToken}}
+	case len(profile.ClientID) > 0:
+		return []profileSecret{{Description: "client secret", Key: profile.ClientID, Value: profile.ClientSecret}}
+	case len(profile.User) > 0:
+		return []profileSecret{{Description: "password", Key: profile.User, Value: profile.Password}}
+	default:
+		return []profileSecret{{Description: "access token", Key: profile.Name, Value: profile.AccessToken}}
+	}
+}
+
+// Add adds a check to the collection
+func (checks *DoctorChecks) Add(name, status, message string) {
+	*checks = append(*checks, DoctorCheck{Name: name, Status: status, Message: message})
+}
+
+// Failures gets the number of failed checks
+func (checks DoctorChecks) Failures() (count int) {
+	for _, check := range checks {
+		if check.Status == DoctorFail {
+			count++
+		}
+	}
+	return
+}
+
+// GetHeaders gets the header for a table
+//
+// implements common.Tableable
+func (check DoctorCheck) GetHeaders(cmd *cobra.Command) []string {
+	return []string{"Check", "Status", "Message"}
+}
+
+// GetRow gets the row for a table
+//
+// implements common.Tableable
+func (check DoctorCheck) GetRow(headers []string) []string {
+	return []string{check.Name, strings.ToUpper(check.Status), check.Message}
+}
+
+// GetHeaders gets the header for a table
+//
+// implements common.Tableables
+func (checks DoctorChecks) GetHeaders(cmd *cobra.Command) []string {
+	return DoctorCheck{}.GetHeaders(cmd)
+}
+
+// GetRowAt gets the row for a table
+//
+// implements common.Tableables
+func (checks DoctorChecks) GetRowAt(index int, headers []string) []string {
+	if index < 0 || index >= len(checks) {
+		return []string{}
+	}
+	return checks[index].GetRow(headers)
+}
+
+// Size gets the number of elements
+//
+// implements common.Tableables
+func (checks DoctorChecks) Size() int {
+	return len(checks)
+}
@@ -0,0 +1,43 @@
+package profile_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/spf13/cobra"
+)
+
+func (suite *ProfileSuite) TestCanDiagnoseAccessTokenProfile() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/2.0/user" {
+			suite.Assert().Equal("Bearer dummy-token", r.Header.Get("Authorization"))
+			_ = json.NewEncoder(w).Encode(map[string]string{"username": "john", "display_name": "John Doe"})
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	apiRoot, err := url.Parse(server.URL)
+	suite.Require().NoError(err)
+	current := &profile.Profile{Name: "doctor", APIRoot: apiRoot, AccessToken: "dummy-token"}
+
+	cmd := &cobra.Command{}
+	cmd.Flags().String("git-remote", "", "")
+	checks := current.Diagnose(suite.Context, cmd)
+	suite.Require().NotEmpty(checks)
+	suite.Assert().Equal(0, checks.Failures(), "No check should fail: %v", checks)
+
+	statuses := map[string]string{}
+	for _, check := range checks {
+		statuses[check.Name] = check.Status
+	}
+	suite.Assert().Equal(profile.DoctorPass, statuses["profile"])
+	suite.Assert().Equal(profile.DoctorWarn, statuses["vault"], "The access token is in clear text")
+	suite.Assert().Equal(profile.DoctorPass, statuses["token"])
+	suite.Assert().Equal(profile.DoctorPass, statuses["user"])
+	suite.Assert().Equal(profile.DoctorWarn, statuses["scopes"])
+}
@@ -0,0 +1,95 @@
+package profile
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/go-logger"
+)
+
+// EnvironmentProfileName is the name of the implicit profile built from the environment variables
+const EnvironmentProfileName = "environment"
+
+// GetProfileFromEnvironment gets a profile assembled from the environment variables
+//
+// The profile is only built if the environment carries credentials:
+//
+// - BB_TOKEN for Repository/Project/Workspace Access Tokens,
+//
+// - BB_EMAIL and BB_API_TOKEN for Atlassian API tokens,
+//
+// - BB_USER and BB_PASSWORD for users with an app password,
+//
+// - BB_CLIENT_ID and BB_CLIENT_SECRET for OAuth consumers (client credentials grant).
+//
+// BB_API_ROOT, BB_WORKSPACE and BB_PROJECT are optional.
+// When running in Bitbucket Pipelines, the workspace defaults to the build's workspace.
+//
+// The secrets of that profile are never read from or written to the vault or the cache.
+func GetProfileFromEnvironment(ctx context.Context) (profile *Profile, found bool) {
+	log := logger.Must(logger.FromContext(ctx)).Child("profile", "environment")
+
+	profile = &Profile{
+		Name:             EnvironmentProfileName,
+		Description:      "Profile from environment variables",
+		User:             getEnv("BB_USER"),
+		Password:         getEnv("BB_PASSWORD"),
+		ClientID:         getEnv("BB_CLIENT_ID"),
+		ClientSecret:     getEnv("BB_CLIENT_SECRET"),
+		AccessToken:      getEnv("BB_TOKEN"),
+		Email:            getEnv("BB_EMAIL"),
+		APIToken:         getEnv("BB_API_TOKEN"),
+		DefaultWorkspace: getEnv("BB_WORKSPACE"),
+		DefaultProject:   getEnv("BB_PROJECT"),
+		OutputFormat:     getEnv("BB_OUTPUT_FORMAT"),
+		fromEnvironment:  true,
+	}
+
+	switch {
+	case len(profile.AccessToken) > 0:
+		log.Debugf("Found an access token in BB_TOKEN")
+		profile.User, profile.Password, profile.ClientID, profile.ClientSecret = "", "", "", ""
+		profile.Email, profile.APIToken = "", ""
+	case len(profile.Email) > 0 && len(profile.APIToken) > 0:
+		log.Debugf("Found an API token in BB_EMAIL and BB_API_TOKEN")
+		profile.Auth = AuthAPIToken
+		profile.User, profile.Password, profile.ClientID, profile.ClientSecret = "", "", "", ""
+	case len(profile.User) > 0 && len(profile.Password) > 0:
+		log.Debugf("Found user credentials in BB_USER and BB_PASSWORD")
+		profile.ClientID, profile.ClientSecret, profile.Email, profile.APIToken = "", "", "", ""
+	case len(profile.ClientID) > 0 && len(profile.ClientSecret) > 0:
+		log.Debugf("Found client credentials in BB_CLIENT_ID and BB_CLIENT_SECRET")
+		profile.User, profile.Password, profile.Email, profile.APIToken = "", "", "", ""
+	default:
+		return nil, false
+	}
+
+	if apiRoot := getEnv("BB_API_ROOT"); len(apiRoot) > 0 {
+		if profile.APIRoot, _ = url.Parse(apiRoot); profile.APIRoot == nil || len(profile.APIRoot.Host) == 0 {
+			log.Warnf("Ignoring invalid BB_API_ROOT: %s", apiRoot)
+			profile.APIRoot = nil
+		}
+	}
+	if len(profile.DefaultWorkspace) == 0 {
+		profile.DefaultWorkspace = common.GetPipelinesWorkspace()
+	}
+	if err := profile.Validate(); err != nil {
+		log.Warnf("The profile from the environment is not valid: %s", err)
+		return nil, false
+	}
+	log.Record("profile", profile).Infof("Using profile from environment variables")
+	return profile, true
+}
+
+// IsFromEnvironment tells if the profile was assembled from the environment variables
+func (profile Profile) IsFromEnvironment() bool {
+	return profile.fromEnvironment
+}
+
+// getEnv gets the trimmed value of an environment variable
+func getEnv(name string) string {
+	return strings.TrimSpace(os.Getenv(name))
+}
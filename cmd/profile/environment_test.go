@@ -0,0 +1,50 @@
+package profile_test
+
+import (
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+)
+
+func (suite *ProfileSuite) TestCanGetProfileFromEnvironmentWithToken() {
+	suite.T().Setenv("BB_TOKEN", "dummy-token")
+	suite.T().Setenv("BB_USER", "")
+	suite.T().Setenv("BB_CLIENT_ID", "")
+	suite.T().Setenv("BB_API_ROOT", "https://api.example.com")
+	suite.T().Setenv("BB_WORKSPACE", "myworkspace")
+
+	current, found := profile.GetProfileFromEnvironment(suite.Context)
+	suite.Require().True(found, "The environment should provide a profile")
+	suite.Require().NotNil(current)
+	suite.Assert().Equal(profile.EnvironmentProfileName, current.Name)
+	suite.Assert().Equal("dummy-token", current.AccessToken)
+	suite.Assert().Equal("myworkspace", current.DefaultWorkspace)
+	suite.Require().NotNil(current.APIRoot)
+	suite.Assert().Equal("api.example.com", current.APIRoot.Host)
+	suite.Assert().True(current.IsFromEnvironment())
+}
+
+func (suite *ProfileSuite) TestCanGetProfileFromEnvironmentWithUser() {
+	suite.T().Setenv("BB_TOKEN", "")
+	suite.T().Setenv("BB_USER", "john")
+	suite.T().Setenv("BB_PASSWORD", "s3cr3t")
+	suite.T().Setenv("BB_CLIENT_ID", "")
+	suite.T().Setenv("BB_WORKSPACE", "")
+	suite.T().Setenv("BITBUCKET_WORKSPACE", "")
+	suite.T().Setenv("BITBUCKET_REPO_FULL_NAME", "pipelineworkspace/repo")
+
+	current, found := profile.GetProfileFromEnvironment(suite.Context)
+	suite.Require().True(found, "The environment should provide a profile")
+	suite.Assert().Equal("john", current.User)
+	suite.Assert().Equal("s3cr3t", current.Password)
+	suite.Assert().Equal("pipelineworkspace", current.DefaultWorkspace)
+}
+
+func (suite *ProfileSuite) TestShouldNotGetProfileFromEnvironmentWithoutCredentials() {
+	suite.T().Setenv("BB_TOKEN", "")
+	suite.T().Setenv("BB_USER", "john")
+	suite.T().Setenv("BB_PASSWORD", "")
+	suite.T().Setenv("BB_CLIENT_ID", "")
+	suite.T().Setenv("BB_WORKSPACE", "myworkspace")
+
+	_, found := profile.GetProfileFromEnvironment(suite.Context)
+	suite.Assert().False(found, "The environment should not provide a profile without a password")
+}
@@ -15,6 +15,7 @@ type BitBucketError struct {
 	Message string              `json:"-"`
 	Detail  string              `json:"-"`
 	Fields  map[string][]string `json:"-"`
+	Cause   error               `json:"-"` // the error of the HTTP request, e.g.: errors.HTTPNotFound
 }
 
 func (bberr *BitBucketError) Error() string {
@@ -38,6 +39,11 @@ func (bberr *BitBucketError) Error() string {
 	return buffer.String()
 }
 
+// Unwrap gets the error of the HTTP request
+func (bberr *BitBucketError) Unwrap() error {
+	return bberr.Cause
+}
+
 // UnmarshalJSON unmarshals the JSON
 func (bberr *BitBucketError) UnmarshalJSON(data []byte) (err error) {
 	type surrogate BitBucketError
@@ -1,6 +1,10 @@
 package profile_test
 
-import "github.com/gildas/bitbucket-cli/cmd/profile"
+import (
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/spf13/cobra"
+)
 
 func (suite *ProfileSuite) TestCanUnmarshalErrorAboutPrivileges() {
 	var bberr profile.BitBucketError
@@ -40,3 +44,28 @@ func (suite *ProfileSuite) TestCanUnmarshalErrorAboutBadRequest() {
 	suite.Assert().Contains(bberr.Fields["links.avatar"], "required key not provided")
 	suite.T().Logf("Expected Error string: %s", bberr.Error())
 }
+
+func (suite *ProfileSuite) TestErrorFlagsShouldOverrideTheProfile() {
+	newCommand := func(flag string) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().Bool("stop-on-error", false, "")
+		cmd.Flags().Bool("warn-on-error", false, "")
+		cmd.Flags().Bool("ignore-errors", false, "")
+		if len(flag) > 0 {
+			suite.Require().NoError(cmd.Flags().Set(flag, "true"))
+		}
+		return cmd
+	}
+
+	current := profile.Profile{}
+	suite.Assert().True(current.ShouldStopOnError(newCommand("")), "The profile stops on error by default")
+	suite.Assert().False(current.ShouldStopOnError(newCommand("warn-on-error")))
+	suite.Assert().True(current.ShouldWarnOnError(newCommand("warn-on-error")))
+	suite.Assert().False(current.ShouldStopOnError(newCommand("ignore-errors")))
+	suite.Assert().True(current.ShouldIgnoreErrors(newCommand("ignore-errors")))
+
+	current = profile.Profile{ErrorProcessing: common.WarnOnError}
+	suite.Assert().True(current.ShouldWarnOnError(newCommand("")))
+	suite.Assert().True(current.ShouldStopOnError(newCommand("stop-on-error")))
+	suite.Assert().False(current.ShouldWarnOnError(newCommand("stop-on-error")))
+}
@@ -0,0 +1,141 @@
+package profile
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var migrateAuthCmd = &cobra.Command{
+	Use:               "migrate-auth [flags] <profile-name>",
+	Short:             "convert a profile using an app password to an Atlassian API token",
+	Long:              "Convert a profile using a user and an app password to an Atlassian API token.\nIf the user of the profile is an email, its password is already an API token and is reused.\nOtherwise, create the API token first at https://id.atlassian.com/manage-profile/security/api-tokens",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: ValidProfileNames,
+	PreRunE:           disableUnsupportedFlags,
+	RunE:              migrateAuthProcess,
+}
+
+var migrateAuthOptions struct {
+	Email           string
+	APIToken        string
+	KeepAppPassword bool
+	NoVerify        bool
+}
+
+func init() {
+	Command.AddCommand(migrateAuthCmd)
+
+	migrateAuthCmd.Flags().StringVar(&migrateAuthOptions.Email, "email", "", "Email of the Atlassian account that owns the API token. Default is the user of the profile if it is an email")
+	migrateAuthCmd.Flags().StringVar(&migrateAuthOptions.APIToken, "api-token", "", "Atlassian API token. If not provided, the password of the profile is reused when its user is an email, otherwise it is read from BB_API_TOKEN or prompted on the terminal")
+	migrateAuthCmd.Flags().BoolVar(&migrateAuthOptions.KeepAppPassword, "keep-app-password", false, "Keep the app password in the vault")
+	migrateAuthCmd.Flags().BoolVar(&migrateAuthOptions.NoVerify, "no-verify", false, "Do not verify the API token with Bitbucket before converting the profile")
+	migrateAuthCmd.SetHelpFunc(hideUnsupportedFlags)
+}
+
+func migrateAuthProcess(cmd *cobra.Command, args []string) (err error) {
+	log := logger.Must(logger.FromContext(cmd.Context())).Child(cmd.Parent().Name(), "migrate-auth")
+	ctx := log.ToContext(cmd.Context())
+
+	_, err = GetProfileFromCommand(ctx, cmd)
+	if errors.Is(err, errors.Empty) || len(Profiles) == 0 {
+		return errors.Errorf("No profiles found")
+	}
+	if err != nil {
+		return err
+	}
+	profile, found := Profiles.Find(args[0])
+	if !found {
+		return errors.NotFound.With("profile", args[0])
+	}
+	if mode := profile.GetAuthMode(); mode != AuthAppPassword {
+		return errors.Errorf("Profile %s uses %s, only profiles with an app password can be converted", profile.Name, mode)
+	}
+
+	email := migrateAuthOptions.Email
+	if len(email) == 0 && strings.Contains(profile.User, "@") {
+		email = profile.User
+	}
+	if len(email) == 0 {
+		return errors.ArgumentMissing.With("email", "The user of the profile is not an email, please provide it with --email")
+	}
+	apiToken := migrateAuthOptions.APIToken
+	if len(apiToken) == 0 && strings.Contains(profile.User, "@") {
+		// Profiles created with --user <email> --password <api-token> already use an API token
+		if apiToken, err = profile.GetPassword(ctx); err != nil {
+			return err
+		}
+	}
+	if len(apiToken) == 0 {
+		apiToken = os.Getenv("BB_API_TOKEN")
+	}
+	if len(apiToken) == 0 {
+		if apiToken, err = readSecretFromTerminal(fmt.Sprintf("API token for %s: ", email)); err != nil {
+			return errors.Join(errors.Errorf("An API token is required, please provide it with --api-token"), err)
+		}
+	}
+
+	converted := *profile
+	converted.Auth = AuthAPIToken
+	converted.Email = email
+	converted.APIToken = apiToken
+	converted.User = ""
+	converted.Password = ""
+	converted.token = nil
+	if len(converted.CloneUser) == 0 && !strings.Contains(profile.User, "@") {
+		// git over https still authenticates with the Bitbucket username
+		converted.CloneUser = profile.User
+	}
+	if err := converted.Validate(); err != nil {
+		return err
+	}
+
+	if !migrateAuthOptions.NoVerify {
+		var user struct {
+			Username string `json:"username"`
+		}
+		log.Infof("Verifying the API token of %s", email)
+		if err := converted.Get(ctx, cmd, "/user", &user); err != nil {
+			return errors.Join(errors.Errorf("Bitbucket refused the API token of %s", email), err)
+		}
+		log.Infof("The API token belongs to %s", user.Username)
+		if len(converted.CloneUser) == 0 {
+			converted.CloneUser = user.Username
+		}
+	}
+
+	if !common.WhatIf(ctx, cmd, "Converting profile %s to an API token of %s", profile.Name, email) {
+		return nil
+	}
+
+	if len(profile.Password) > 0 {
+		log.Infof("Profile %s stored its credentials in plain text, we should keep it that way", profile.Name)
+	} else {
+		if err := converted.SetCredentialInVault(converted.VaultKey, email, apiToken); err != nil {
+			return errors.Join(errors.Errorf("Failed to store the API token in the vault"), err)
+		}
+		log.Infof("Stored API token in the %s vault for %s", converted.VaultKey, email)
+		converted.APIToken = ""
+		if !migrateAuthOptions.KeepAppPassword && profile.User != email {
+			if err := profile.DeleteCredentialFromVault(profile.VaultKey, profile.User); err != nil {
+				log.Warnf("Failed to delete the app password of %s from the %s vault: %s", profile.User, profile.VaultKey, err)
+			} else {
+				log.Infof("Deleted the app password of %s from the %s vault", profile.User, profile.VaultKey)
+			}
+		}
+	}
+	*profile = converted
+
+	viper.Set("profiles", Profiles)
+	if err := viper.WriteConfig(); err != nil {
+		return err
+	}
+	common.Verbose(ctx, cmd, "Profile %s now authenticates with an API token of %s", profile.Name, email)
+	return nil
+}
@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"reflect"
 	"runtime"
+	"slices"
 	"strings"
 
 	"github.com/gildas/bitbucket-cli/cmd/common"
@@ -35,13 +37,20 @@ type Profile struct {
 	CloneUser         string                 `json:"cloneUser,omitempty"         mapstructure:"cloneUser,omitempty"         yaml:",omitempty"`
 	SshKeyFilename    string                 `json:"sshKeyFilename,omitempty"    mapstructure:"sshKeyFilename,omitempty"    yaml:",omitempty"`
 	VaultKey          string                 `json:"vaultKey,omitempty"          mapstructure:"vaultKey,omitempty"          yaml:",omitempty"`
+	SecretStore       string                 `json:"secretStore,omitempty"       mapstructure:"secretStore,omitempty"       yaml:",omitempty"`
+	SecretCommand     string                 `json:"secretCommand,omitempty"     mapstructure:"secretCommand,omitempty"     yaml:",omitempty"`
+	MergeTemplates    map[string]string      `json:"mergeTemplates,omitempty"    mapstructure:"mergeTemplates,omitempty"    yaml:",omitempty"`
+	Auth              string                 `json:"auth,omitempty"              mapstructure:"auth,omitempty"              yaml:",omitempty"`
 	User              string                 `json:"user,omitempty"              mapstructure:"user"                        yaml:",omitempty"`
 	Password          string                 `json:"password,omitempty"          mapstructure:"password"                    yaml:",omitempty"`
 	ClientID          string                 `json:"clientID,omitempty"          mapstructure:"clientID"                    yaml:",omitempty"`
 	ClientSecret      string                 `json:"clientSecret,omitempty"      mapstructure:"clientSecret"                yaml:",omitempty"`
 	CallbackPort      uint16                 `json:"callbackPort,omitempty"      mapstructure:"callbackPort"                yaml:",omitempty"`
 	AccessToken       string                 `json:"accessToken,omitempty"       mapstructure:"accessToken,omitempty"       yaml:",omitempty"`
+	Email             string                 `json:"email,omitempty"             mapstructure:"email,omitempty"             yaml:",omitempty"`
+	APIToken          string                 `json:"apiToken,omitempty"          mapstructure:"apiToken,omitempty"          yaml:",omitempty"`
 	token             *Token                 `json:"-"                           mapstructure:"-"                           yaml:"-"`
+	fromEnvironment   bool                   `json:"-"                           mapstructure:"-"                           yaml:"-"`
 }
 
 // Current is the current profile
@@ -73,6 +82,12 @@ var columns = common.Columns[*Profile]{
 	{Name: "default", DefaultSorter: false, Compare: func(a, b *Profile) bool {
 		return a.Default == b.Default
 	}},
+	{Name: "auth", DefaultSorter: false, Compare: func(a, b *Profile) bool {
+		return strings.Compare(a.GetAuthMode(), b.GetAuthMode()) == -1
+	}},
+	{Name: "email", DefaultSorter: false, Compare: func(a, b *Profile) bool {
+		return strings.Compare(strings.ToLower(a.Email), strings.ToLower(b.Email)) == -1
+	}},
 	{Name: "user", DefaultSorter: false, Compare: func(a, b *Profile) bool {
 		return strings.Compare(strings.ToLower(a.User), strings.ToLower(b.User)) == -1
 	}},
@@ -112,6 +127,9 @@ var columns = common.Columns[*Profile]{
 	{Name: "vaultkey", DefaultSorter: false, Compare: func(a, b *Profile) bool {
 		return strings.Compare(strings.ToLower(a.VaultKey), strings.ToLower(b.VaultKey)) == -1
 	}},
+	{Name: "secretstore", DefaultSorter: false, Compare: func(a, b *Profile) bool {
+		return strings.Compare(strings.ToLower(a.GetSecretStoreName()), strings.ToLower(b.GetSecretStoreName())) == -1
+	}},
 	{Name: "errorprocessing", DefaultSorter: false, Compare: func(a, b *Profile) bool {
 		return strings.Compare(strings.ToLower(a.ErrorProcessing.String()), strings.ToLower(b.ErrorProcessing.String())) == -1
 	}},
@@ -122,7 +140,8 @@ var columns = common.Columns[*Profile]{
 
 // GetProfileFromCommand gets the profile from the command line
 //
-// If the profile is not given, it will use the current profile
+// If the profile is not given, it will use the profile from the environment variables if they contain credentials,
+// otherwise it will use the current profile
 func GetProfileFromCommand(context context.Context, cmd *cobra.Command) (profile *Profile, err error) {
 	log := logger.Must(logger.FromContext(context)).Child("profile", "getProfileFromCommand")
 
@@ -134,9 +153,20 @@ func GetProfileFromCommand(context context.Context, cmd *cobra.Command) (profile
 		var found bool
 		log.Debugf("Command line has profile flag set to %s", cmd.Flag("profile").Value.String())
 		if profile, found = Profiles.Find(cmd.Flag("profile").Value.String()); !found {
+			if cmd.Flag("profile").Value.String() == EnvironmentProfileName {
+				if profile, found = GetProfileFromEnvironment(context); found {
+					return profile, nil
+				}
+			}
 			return nil, errors.ArgumentInvalid.With("profile", cmd.Flag("profile").Value.String())
 		}
 	} else if Current == nil {
+		if len(cmd.Flag("profile").Value.String()) == 0 {
+			if profile, found := GetProfileFromEnvironment(context); found {
+				Current = profile
+				return Current, nil
+			}
+		}
 		if len(Profiles) == 0 {
 			return nil, errors.Empty.With("profiles")
 		}
@@ -189,6 +219,10 @@ func (profile Profile) GetRow(headers []string) []string {
 			row = append(row, profile.DefaultProject)
 		case "callbackport":
 			row = append(row, fmt.Sprintf("%d", profile.CallbackPort))
+		case "auth":
+			row = append(row, profile.GetAuthMode())
+		case "email":
+			row = append(row, profile.Email)
 		case "user":
 			row = append(row, profile.User)
 		case "clientid":
@@ -211,6 +245,8 @@ func (profile Profile) GetRow(headers []string) []string {
 			row = append(row, profile.SshKeyFilename)
 		case "vaultkey":
 			row = append(row, profile.VaultKey)
+		case "secretstore":
+			row = append(row, profile.GetSecretStoreName())
 		case "errorprocessing":
 			row = append(row, profile.ErrorProcessing.String())
 		case "progress":
@@ -242,6 +278,12 @@ func (profile Profile) Redact() any {
 	if len(redacted.AccessToken) > 0 {
 		redacted.AccessToken = logger.RedactWithHash(redacted.AccessToken)
 	}
+	if len(redacted.Email) > 0 {
+		redacted.Email = logger.RedactWithHash(redacted.Email)
+	}
+	if len(redacted.APIToken) > 0 {
+		redacted.APIToken = logger.RedactWithHash(redacted.APIToken)
+	}
 	if len(redacted.CloneUser) > 0 {
 		redacted.CloneUser = logger.RedactWithHash(redacted.CloneUser)
 	}
@@ -278,6 +320,10 @@ func (profile *Profile) GetPassword(ctx context.Context) (password string, err e
 
 // LoadSecrets fills the profile with its secret from the Vault as needed
 func (profile *Profile) LoadSecrets(ctx context.Context) (err error) {
+	if profile.GetAuthMode() == AuthAPIToken {
+		profile.APIToken, err = profile.GetAPIToken(ctx)
+		return err
+	}
 	if len(profile.ClientID) > 0 {
 		profile.ClientSecret, err = profile.GetClientSecret(ctx)
 		return err
@@ -312,6 +358,15 @@ func (profile *Profile) Update(other Profile) error {
 	if len(other.Password) > 0 && other.Password != profile.Password {
 		profile.Password = other.Password
 	}
+	if len(other.Auth) > 0 {
+		profile.Auth = other.Auth
+	}
+	if len(other.Email) > 0 && other.Email != profile.Email {
+		profile.Email = other.Email
+	}
+	if len(other.APIToken) > 0 && other.APIToken != profile.APIToken {
+		profile.APIToken = other.APIToken
+	}
 	if len(other.ClientID) > 0 && other.ClientID != profile.ClientID {
 		profile.ClientID = other.ClientID
 		profile.token = nil
@@ -338,31 +393,48 @@ func (profile *Profile) Update(other Profile) error {
 	if len(other.SshKeyFilename) > 0 {
 		profile.SshKeyFilename = other.SshKeyFilename
 	}
+	if len(other.SecretStore) > 0 {
+		profile.SecretStore = other.SecretStore
+	}
+	if len(other.SecretCommand) > 0 {
+		profile.SecretCommand = other.SecretCommand
+	}
+	if len(other.MergeTemplates) > 0 {
+		profile.MergeTemplates = other.MergeTemplates
+	}
 	return profile.Validate()
 }
 
 // ShouldStopOnError tells if the command should stop on error
 func (profile Profile) ShouldStopOnError(cmd *cobra.Command) bool {
-	if cmd.Flag("stop-on-error").Changed {
-		return cmd.Flag("stop-on-error").Value.String() == "true"
-	}
-	return profile.ErrorProcessing == common.StopOnError
+	return profile.getErrorProcessing(cmd) == common.StopOnError
 }
 
 // ShouldWarnOnError tells if the command should warn on error
 func (profile Profile) ShouldWarnOnError(cmd *cobra.Command) bool {
-	if cmd.Flag("warn-on-error").Changed {
-		return cmd.Flag("warn-on-error").Value.String() == "true"
-	}
-	return profile.ErrorProcessing == common.WarnOnError
+	return profile.getErrorProcessing(cmd) == common.WarnOnError
 }
 
 // ShouldIgnoreErrors tells if the command should ignore errors
 func (profile Profile) ShouldIgnoreErrors(cmd *cobra.Command) bool {
-	if cmd.Flag("ignore-errors").Changed {
-		return cmd.Flag("ignore-errors").Value.String() == "true"
+	return profile.getErrorProcessing(cmd) == common.IgnoreErrors
+}
+
+// getErrorProcessing gets how the command should process errors
+//
+// The --stop-on-error, --warn-on-error, and --ignore-errors flags override the profile
+func (profile Profile) getErrorProcessing(cmd *cobra.Command) common.ErrorProcessing {
+	flags := map[string]common.ErrorProcessing{
+		"stop-on-error": common.StopOnError,
+		"warn-on-error": common.WarnOnError,
+		"ignore-errors": common.IgnoreErrors,
+	}
+	for name, processing := range flags {
+		if flag := cmd.Flag(name); flag != nil && flag.Changed && flag.Value.String() == "true" {
+			return processing
+		}
 	}
-	return profile.ErrorProcessing == common.IgnoreErrors
+	return profile.ErrorProcessing
 }
 
 // String gets a string representation of this profile
@@ -384,6 +456,8 @@ func (profile Profile) Print(context context.Context, cmd *cobra.Command, payloa
 	switch outputFormat {
 	case "json":
 		return profile.PrintJSON(context, cmd, payload)
+	case "ndjson":
+		return profile.PrintNDJSON(context, cmd, payload)
 	case "yaml":
 		return profile.PrintYAML(context, cmd, payload)
 	case "csv":
@@ -410,6 +484,30 @@ func (profile Profile) PrintJSON(context context.Context, cmd *cobra.Command, pa
 	return nil
 }
 
+// PrintNDJSON prints the given payload to the console as newline delimited JSON
+//
+// Each element of a slice is printed as a JSON object on its own line, any other payload is printed on one line
+func (profile Profile) PrintNDJSON(context context.Context, cmd *cobra.Command, payload any) error {
+	log := logger.Must(logger.FromContext(context))
+
+	log.Debugf("Printing payload as NDJSON")
+	items := []any{payload}
+	if value := reflect.ValueOf(payload); value.Kind() == reflect.Slice {
+		items = make([]any, 0, value.Len())
+		for index := 0; index < value.Len(); index++ {
+			items = append(items, value.Index(index).Interface())
+		}
+	}
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return errors.JSONMarshalError.Wrap(err)
+		}
+		fmt.Println(string(data))
+	}
+	return nil
+}
+
 // PrintYAML prints the given payload to the console as YAML
 func (profile Profile) PrintYAML(context context.Context, cmd *cobra.Command, payload any) error {
 	log := logger.Must(logger.FromContext(context))
@@ -528,6 +626,15 @@ func (profile *Profile) Validate() error {
 	if profile.CloneProtocol != "git" && profile.CloneProtocol != "https" && profile.CloneProtocol != "ssh" {
 		merr.Append(errors.ArgumentInvalid.With("cloneProtocol", profile.CloneProtocol))
 	}
+	if err := profile.validateAuth(); err != nil {
+		merr.Append(err)
+	}
+	if len(profile.SecretStore) > 0 && !slices.Contains(SecretStoreNames, profile.SecretStore) {
+		merr.Append(errors.ArgumentInvalid.With("secretStore", profile.SecretStore))
+	}
+	if profile.SecretStore == "command" && len(profile.SecretCommand) == 0 {
+		merr.Append(errors.ArgumentMissing.With("secretCommand"))
+	}
 	if len(profile.OutputFormat) == 0 {
 		profile.OutputFormat = "table"
 	}
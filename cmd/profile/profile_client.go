@@ -438,13 +438,25 @@ func (profile *Profile) authorize(ctx context.Context) (authorization string, er
 func (profile *Profile) send(ctx context.Context, cmd *cobra.Command, options *request.Options, uripath string, response any) (result *request.Content, err error) {
 	log := logger.Must(logger.FromContext(ctx)).Child(nil, strings.ToLower(options.Method))
 
-	if len(profile.User) > 0 {
+	switch profile.GetAuthMode() {
+	case AuthAPIToken:
+		apiToken, err := profile.GetAPIToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		options.Authorization = request.BasicAuthorization(profile.Email, apiToken)
+	case AuthAppPassword:
 		password, err := profile.GetPassword(ctx)
 		if err != nil {
 			return nil, err
 		}
 		options.Authorization = request.BasicAuthorization(profile.User, password)
-	} else if options.Authorization, err = profile.authorize(ctx); err != nil {
+	default:
+		if options.Authorization, err = profile.authorize(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if err = profile.checkScopes(ctx, cmd); err != nil {
 		return nil, err
 	}
 
@@ -482,14 +494,24 @@ func (profile *Profile) send(ctx context.Context, cmd *cobra.Command, options *r
 	}
 	log.Infof("Sending %s request to %s", options.Method, options.URL)
 	result, err = request.Send(options, response)
+	if result != nil {
+		profile.recordGrantedScopes(ctx, result.Headers)
+	}
 	if err != nil {
 		if errors.Is(err, errors.JSONUnmarshalError) {
 			return result, err
 		}
+		if result != nil && result.StatusCode == http.StatusForbidden {
+			if scopeErr := profile.getMissingScopesFromResponse(cmd, result.Headers); scopeErr != nil {
+				log.Warnf("Bitbucket refused the request: %s", scopeErr)
+				return result, scopeErr
+			}
+		}
 		if result != nil {
 			var bberr *BitBucketError
 			if jerr := result.UnmarshalContentJSON(&bberr); jerr == nil {
 				log.Warnf("We have a BitBucketError: %#+v", bberr)
+				bberr.Cause = err
 				return result, bberr
 			} else {
 				log.Debugf("the Error %s is not a bitbucket error: %s", err.Error(), jerr.Error())
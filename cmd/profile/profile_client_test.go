@@ -7,6 +7,7 @@ import (
 	"net/url"
 
 	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/gildas/go-errors"
 	"github.com/spf13/cobra"
 )
 
@@ -100,3 +101,32 @@ func (suite *ProfileSuite) TestGetAll_DoesNotOverwriteExistingNextParams() {
 	suite.Require().Equal("1", items[0].ID)
 	suite.Require().Equal("2", items[1].ID)
 }
+
+func (suite *ProfileSuite) TestShouldKeepHTTPStatusInBitBucketErrors() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/src/main/CODEOWNERS" {
+			w.WriteHeader(http.StatusNotFound)
+		} else {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+		_, _ = w.Write([]byte(`{"type": "error", "error": {"message": "Nope", "detail": "Really nope"}}`))
+	}))
+	defer server.Close()
+
+	apiRoot, err := url.Parse(server.URL)
+	suite.Require().NoError(err)
+	current := &profile.Profile{APIRoot: apiRoot, AccessToken: "dummy-token"}
+	cmd := &cobra.Command{}
+
+	_, err = current.GetRaw(suite.Context, cmd, server.URL+"/src/main/CODEOWNERS")
+	suite.Require().Error(err)
+	var bberr *profile.BitBucketError
+	suite.Require().ErrorAs(err, &bberr)
+	suite.Assert().Equal("Nope", bberr.Message)
+	suite.Assert().ErrorIs(err, errors.HTTPNotFound)
+
+	_, err = current.GetRaw(suite.Context, cmd, server.URL+"/src/main/.bitbucket/CODEOWNERS")
+	suite.Require().Error(err)
+	suite.Assert().NotErrorIs(err, errors.HTTPNotFound)
+}
@@ -0,0 +1,216 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gildas/go-logger"
+	"github.com/spf13/cobra"
+)
+
+// ScopesAnnotation is the cobra annotation that contains the comma-separated Bitbucket scopes a command needs
+//
+// Example:
+//
+//	var approveCmd = &cobra.Command{
+//		Use:         "approve",
+//		Annotations: map[string]string{profile.ScopesAnnotation: "pullrequest:write"},
+//	}
+const ScopesAnnotation = "scopes"
+
+// MissingScopesError is returned when the credentials of a profile lack the scopes a command needs
+type MissingScopesError struct {
+	Command string   `json:"command"`
+	Missing []string `json:"missing"`
+	Granted []string `json:"granted"`
+	Hint    string   `json:"hint"`
+}
+
+// impliedScopes lists the scopes a Bitbucket scope implies
+//
+// repository:admin and repository:delete do not imply repository, they do not give read access.
+//
+// See: https://developer.atlassian.com/cloud/bitbucket/rest/intro/#scopes
+var impliedScopes = map[string][]string{
+	"account:write":     {"account"},
+	"team:write":        {"team"},
+	"project":           {"repository"},
+	"project:admin":     {"project"},
+	"repository:write":  {"repository"},
+	"pullrequest":       {"repository"},
+	"pullrequest:write": {"pullrequest", "repository:write"},
+	"issue":             {"repository"},
+	"issue:write":       {"issue"},
+	"snippet:write":     {"snippet"},
+	"pipeline:write":    {"pipeline"},
+	"pipeline:variable": {"pipeline"},
+	"runner:write":      {"runner"},
+}
+
+// GetRequiredScopes gets the scopes the given command needs from its annotations
+func GetRequiredScopes(cmd *cobra.Command) []string {
+	if cmd == nil || len(cmd.Annotations[ScopesAnnotation]) == 0 {
+		return []string{}
+	}
+	return parseScopes(cmd.Annotations[ScopesAnnotation])
+}
+
+// GetMissingScopes gets the required scopes that are not covered by the granted ones
+func GetMissingScopes(required, granted []string) (missing []string) {
+	effective := map[string]bool{}
+	var expand func(scope string)
+	expand = func(scope string) {
+		if effective[scope] {
+			return
+		}
+		effective[scope] = true
+		for _, implied := range impliedScopes[scope] {
+			expand(implied)
+		}
+	}
+	for _, scope := range granted {
+		expand(normalizeScope(scope))
+	}
+	for _, scope := range required {
+		if !effective[normalizeScope(scope)] {
+			missing = append(missing, scope)
+		}
+	}
+	return
+}
+
+// checkScopes verifies the granted scopes of this profile cover the scopes the command needs
+//
+// The granted scopes are known from the OAuth token or from a previous response (API Tokens).
+// If they are not known, the check is skipped.
+func (profile *Profile) checkScopes(ctx context.Context, cmd *cobra.Command) error {
+	log := logger.Must(logger.FromContext(ctx)).Child("profile", "checkscopes")
+
+	required := GetRequiredScopes(cmd)
+	if len(required) == 0 || profile.token == nil {
+		return nil
+	}
+	granted := profile.token.GetScopes()
+	if len(granted) == 0 {
+		log.Debugf("Granted scopes of profile %s are unknown, cannot check %v", profile.Name, required)
+		return nil
+	}
+	if missing := GetMissingScopes(required, granted); len(missing) > 0 {
+		log.Warnf("Profile %s is missing scopes %v (granted: %v)", profile.Name, missing, granted)
+		return profile.newMissingScopesError(cmd, missing, granted)
+	}
+	return nil
+}
+
+// recordGrantedScopes records the granted scopes from the response headers if the token does not know them
+func (profile *Profile) recordGrantedScopes(ctx context.Context, headers http.Header) {
+	if profile.token == nil || len(strings.TrimSpace(profile.token.Scope)) > 0 || headers == nil {
+		return
+	}
+	if scopes := parseScopes(headers.Get("X-OAuth-Scopes")); len(scopes) > 0 {
+		logger.Must(logger.FromContext(ctx)).Debugf("Profile %s was granted scopes %v", profile.Name, scopes)
+		profile.token.Scope = strings.Join(scopes, " ")
+	}
+}
+
+// getMissingScopesFromResponse gets the missing scopes of a forbidden response
+//
+// Bitbucket sends the scopes the endpoint accepts and the scopes the credentials were granted
+func (profile *Profile) getMissingScopesFromResponse(cmd *cobra.Command, headers http.Header) error {
+	if headers == nil {
+		return nil
+	}
+	accepted := parseScopes(headers.Get("X-Accepted-OAuth-Scopes"))
+	granted := parseScopes(headers.Get("X-OAuth-Scopes"))
+	if len(accepted) == 0 {
+		return nil
+	}
+	for _, scope := range accepted {
+		if len(GetMissingScopes([]string{scope}, granted)) == 0 {
+			return nil // any accepted scope is enough
+		}
+	}
+	return profile.newMissingScopesError(cmd, accepted[:1], granted)
+}
+
+// newMissingScopesError creates a MissingScopesError with a hint that depends on how the profile authenticates
+func (profile *Profile) newMissingScopesError(cmd *cobra.Command, missing, granted []string) *MissingScopesError {
+	var hint string
+	switch {
+	case profile.GetAuthMode() == AuthAPIToken:
+		hint = "The scopes of an API token cannot be changed, create a new API token with the scope at https://id.atlassian.com/manage-profile/security/api-tokens and update the profile with it"
+	case len(profile.ClientID) > 0:
+		hint = fmt.Sprintf("Add the permission to the OAuth consumer %s in the workspace settings (OAuth consumers)", profile.ClientID)
+		hint += fmt.Sprintf(", then delete the cached access token %s from the %s secret store", profile.getAccessTokenCacheKey(), profile.GetSecretStoreName())
+	case len(profile.AccessToken) > 0:
+		hint = "The scopes of an access token cannot be changed, create a new access token with the scope and update the profile with it"
+	default:
+		hint = fmt.Sprintf("Create new credentials with the scope and update the profile %s with them", profile.Name)
+	}
+	commandPath := ""
+	if cmd != nil {
+		commandPath = cmd.CommandPath()
+	}
+	return &MissingScopesError{
+		Command: commandPath,
+		Missing: missing,
+		Granted: granted,
+		Hint:    hint,
+	}
+}
+
+// Error returns the error message
+//
+// implements error
+func (err *MissingScopesError) Error() string {
+	var buffer strings.Builder
+
+	if len(err.Command) > 0 {
+		buffer.WriteString(err.Command)
+		buffer.WriteString(" needs the scope")
+	} else {
+		buffer.WriteString("Bitbucket needs the scope")
+	}
+	if len(err.Missing) > 1 {
+		buffer.WriteString("s")
+	}
+	buffer.WriteString(" ")
+	buffer.WriteString(strings.Join(err.Missing, ", "))
+	if len(err.Granted) > 0 {
+		buffer.WriteString(" (granted: ")
+		buffer.WriteString(strings.Join(err.Granted, ", "))
+		buffer.WriteString(")")
+	}
+	if len(err.Hint) > 0 {
+		buffer.WriteString(".\n")
+		buffer.WriteString(err.Hint)
+	}
+	return buffer.String()
+}
+
+// parseScopes parses a list of scopes separated by spaces or commas
+func parseScopes(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool { return r == ' ' || r == ',' })
+}
+
+// normalizeScope converts the granular scopes of Atlassian API tokens (e.g. "write:pullrequest:bitbucket") to Bitbucket scopes (e.g. "pullrequest:write")
+func normalizeScope(scope string) string {
+	scope = strings.ToLower(strings.TrimSpace(scope))
+	components := strings.Split(scope, ":")
+	if len(components) != 3 || components[2] != "bitbucket" {
+		return scope
+	}
+	resource := components[1]
+	switch resource {
+	case "user":
+		resource = "account"
+	case "workspace":
+		resource = "team"
+	}
+	if components[0] == "read" {
+		return resource
+	}
+	return resource + ":" + components[0]
+}
@@ -0,0 +1,85 @@
+package profile_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/spf13/cobra"
+)
+
+func (suite *ProfileSuite) TestCanGetMissingScopes() {
+	suite.Assert().Empty(profile.GetMissingScopes([]string{"pullrequest"}, []string{"pullrequest:write"}))
+	suite.Assert().Empty(profile.GetMissingScopes([]string{"repository"}, []string{"pullrequest"}))
+	suite.Assert().Empty(profile.GetMissingScopes([]string{"repository:write"}, []string{"pullrequest:write"}))
+	suite.Assert().Empty(profile.GetMissingScopes([]string{"pullrequest:write"}, []string{"write:pullrequest:bitbucket"}))
+	suite.Assert().Empty(profile.GetMissingScopes([]string{"account"}, []string{"read:user:bitbucket"}))
+	suite.Assert().Equal([]string{"pipeline:write"}, profile.GetMissingScopes([]string{"pipeline:write"}, []string{"pipeline", "repository:write"}))
+	suite.Assert().Equal([]string{"pullrequest:write"}, profile.GetMissingScopes([]string{"pullrequest:write"}, []string{"read:pullrequest:bitbucket"}))
+}
+
+func (suite *ProfileSuite) TestCanGetRequiredScopes() {
+	cmd := &cobra.Command{Annotations: map[string]string{profile.ScopesAnnotation: "pullrequest:write, repository"}}
+	suite.Assert().Equal([]string{"pullrequest:write", "repository"}, profile.GetRequiredScopes(cmd))
+	suite.Assert().Empty(profile.GetRequiredScopes(&cobra.Command{}))
+	suite.Assert().Empty(profile.GetRequiredScopes(nil))
+}
+
+func (suite *ProfileSuite) TestShouldFailEarlyWhenScopeIsMissing() {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("X-OAuth-Scopes", "pullrequest, repository")
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": "1"})
+	}))
+	defer server.Close()
+
+	apiRoot, err := url.Parse(server.URL)
+	suite.Require().NoError(err)
+	current := &profile.Profile{Name: "scopes", APIRoot: apiRoot, AccessToken: "dummy-token"}
+
+	var item testItem
+	reader := &cobra.Command{Use: "get", Annotations: map[string]string{profile.ScopesAnnotation: "pullrequest"}}
+	err = current.Get(suite.Context, reader, "/item", &item)
+	suite.Require().NoError(err, "The first call should learn the scopes from the response")
+	suite.Assert().Equal(1, calls)
+
+	writer := &cobra.Command{Use: "approve", Annotations: map[string]string{profile.ScopesAnnotation: "pullrequest:write"}}
+	err = current.Post(suite.Context, writer, "/item", nil, &item)
+	suite.Require().Error(err, "The call should fail before reaching Bitbucket")
+	suite.Assert().Equal(1, calls, "Bitbucket should not have been called")
+
+	var scopeErr *profile.MissingScopesError
+	suite.Require().ErrorAs(err, &scopeErr)
+	suite.Assert().Equal([]string{"pullrequest:write"}, scopeErr.Missing)
+	suite.Assert().Contains(err.Error(), "pullrequest:write")
+}
+
+func (suite *ProfileSuite) TestShouldExplainForbiddenResponses() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-OAuth-Scopes", "repository")
+		w.Header().Set("X-Accepted-OAuth-Scopes", "pipeline:write")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"type": "error", "error": {"message": "Forbidden"}}`))
+	}))
+	defer server.Close()
+
+	apiRoot, err := url.Parse(server.URL)
+	suite.Require().NoError(err)
+	current := &profile.Profile{Name: "scopes", APIRoot: apiRoot, AccessToken: "dummy-token"}
+
+	err = current.Post(suite.Context, &cobra.Command{Use: "trigger"}, "/pipelines", nil, nil)
+	suite.Require().Error(err)
+	var scopeErr *profile.MissingScopesError
+	suite.Require().ErrorAs(err, &scopeErr)
+	suite.Assert().Equal([]string{"pipeline:write"}, scopeErr.Missing)
+}
+
+func (suite *ProfileSuite) TestShouldNotImplyReadAccessFromAdminScopes() {
+	suite.Assert().Equal([]string{"repository"}, profile.GetMissingScopes([]string{"repository"}, []string{"repository:admin"}))
+	suite.Assert().Equal([]string{"repository"}, profile.GetMissingScopes([]string{"repository"}, []string{"repository:delete"}))
+	suite.Assert().Empty(profile.GetMissingScopes([]string{"repository"}, []string{"repository:admin", "repository:write"}))
+}
@@ -0,0 +1,106 @@
+package profile
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/gildas/go-errors"
+)
+
+// SecretStore describes a place where the secrets of the profiles are stored
+//
+// Secrets are identified by a service (the vault key of the profile) and a key (client ID, user, profile name, etc).
+type SecretStore interface {
+	// Name gets the name of the store (keyring, file, pass, command)
+	Name() string
+
+	// Get gets a secret, returns errors.NotFound if the secret does not exist
+	Get(service, key string) (string, error)
+
+	// Set stores a secret
+	Set(service, key, secret string) error
+
+	// Delete deletes a secret
+	Delete(service, key string) error
+}
+
+// SecretStoreNames are the names of the supported secret stores
+var SecretStoreNames = []string{"keyring", "file", "pass", "command"}
+
+var (
+	secretStores     = map[string]SecretStore{}
+	secretStoresLock sync.Mutex
+)
+
+// NewSecretStore gets the secret store with the given name
+//
+// The command is only used by the "command" store.
+// Stores are created once per process, so the encrypted file is only decrypted once.
+func NewSecretStore(name, command string) (store SecretStore, err error) {
+	secretStoresLock.Lock()
+	defer secretStoresLock.Unlock()
+
+	id := name + ":" + command
+	if name == "file" {
+		id += getSecretsFilename()
+	}
+	if store, found := secretStores[id]; found {
+		return store, nil
+	}
+	switch name {
+	case "keyring":
+		store = &keyringSecretStore{}
+	case "file":
+		store = newFileSecretStore(getSecretsFilename())
+	case "pass":
+		store = &passSecretStore{}
+	case "command":
+		if len(strings.TrimSpace(command)) == 0 {
+			return nil, errors.ArgumentMissing.With("secretCommand")
+		}
+		store = &commandSecretStore{Command: command}
+	default:
+		return nil, errors.ArgumentInvalid.With("secretStore", name)
+	}
+	secretStores[id] = store
+	return store, nil
+}
+
+// GetDefaultSecretStoreName gets the name of the default secret store
+//
+// The BB_SECRET_STORE environment variable overrides the default.
+// On Linux and BSD, if there is no D-Bus session (headless servers, containers) and BB_SECRETS_PASSPHRASE is set,
+// the encrypted file is used instead of the keyring. Without the passphrase, the keyring stays the default like before,
+// as the file cannot be decrypted without a terminal.
+func GetDefaultSecretStoreName() string {
+	if name := strings.TrimSpace(os.Getenv("BB_SECRET_STORE")); len(name) > 0 {
+		return name
+	}
+	switch runtime.GOOS {
+	case "windows", "darwin":
+		return "keyring"
+	}
+	if len(os.Getenv("DBUS_SESSION_BUS_ADDRESS")) == 0 && len(os.Getenv("BB_SECRETS_PASSPHRASE")) > 0 {
+		return "file"
+	}
+	return "keyring"
+}
+
+// GetSecretStoreName gets the name of the secret store of this profile
+func (profile Profile) GetSecretStoreName() string {
+	if len(profile.SecretStore) > 0 {
+		return profile.SecretStore
+	}
+	return GetDefaultSecretStoreName()
+}
+
+// GetSecretStore gets the secret store of this profile
+func (profile Profile) GetSecretStore() (SecretStore, error) {
+	command := profile.SecretCommand
+	if len(command) == 0 {
+		command = os.Getenv("BB_SECRET_COMMAND")
+	}
+	return NewSecretStore(profile.GetSecretStoreName(), command)
+}
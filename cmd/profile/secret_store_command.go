@@ -0,0 +1,77 @@
+package profile
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+
+	"github.com/gildas/go-errors"
+)
+
+// commandSecretStore stores secrets through an external command
+//
+// The command is called with the action and the secret identifiers, like git credential helpers:
+//
+//	<command> get <service> <key>     prints the secret on its standard output, exits with 1 if not found
+//	<command> set <service> <key>     reads the secret from its standard input
+//	<command> delete <service> <key>
+type commandSecretStore struct {
+	Command string
+}
+
+// Name gets the name of the store
+//
+// implements SecretStore
+func (store commandSecretStore) Name() string {
+	return "command"
+}
+
+// Get gets a secret
+//
+// implements SecretStore
+func (store commandSecretStore) Get(service, key string) (string, error) {
+	output, err := store.run("", "get", service, key)
+	if err != nil {
+		return "", err
+	}
+	secret := strings.TrimRight(output, "\r\n")
+	if len(secret) == 0 {
+		return "", errors.NotFound.With("key", key)
+	}
+	return secret, nil
+}
+
+// Set stores a secret
+//
+// implements SecretStore
+func (store commandSecretStore) Set(service, key, secret string) error {
+	_, err := store.run(secret, "set", service, key)
+	return err
+}
+
+// Delete deletes a secret
+//
+// implements SecretStore
+func (store commandSecretStore) Delete(service, key string) error {
+	_, err := store.run("", "delete", service, key)
+	return err
+}
+
+// run runs the command with the given arguments, sending input to its standard input
+func (store commandSecretStore) run(input string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	fields := strings.Fields(store.Command)
+	command := exec.Command(fields[0], append(fields[1:], args...)...)
+	command.Stdin = strings.NewReader(input)
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+	if err := command.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if args[0] == "get" && errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && stdout.Len() == 0 {
+			return "", errors.NotFound.With("key", args[2])
+		}
+		return "", errors.Errorf("%s %s failed: %s (%s)", fields[0], args[0], strings.TrimSpace(stderr.String()), err)
+	}
+	return stdout.String(), nil
+}
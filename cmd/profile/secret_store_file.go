@@ -0,0 +1,210 @@
+package profile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"filippo.io/age"
+	"github.com/gildas/go-errors"
+)
+
+// fileSecretStore stores secrets in a file encrypted with age and a passphrase
+//
+// The passphrase comes from the BB_SECRETS_PASSPHRASE environment variable or is prompted on the terminal.
+// The file can be decrypted with: age --decrypt secrets.age
+type fileSecretStore struct {
+	Filename   string
+	passphrase string
+	secrets    map[string]map[string]string
+	lock       sync.Mutex
+}
+
+// getSecretsFilename gets the path of the encrypted secrets file
+//
+// The BB_SECRETS_FILE environment variable overrides the default location
+func getSecretsFilename() string {
+	if filename := strings.TrimSpace(os.Getenv("BB_SECRETS_FILE")); len(filename) > 0 {
+		return filename
+	}
+	if configDir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(configDir, "bitbucket", "secrets.age")
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(homeDir, ".bitbucket-cli-secrets.age")
+	}
+	return "secrets.age"
+}
+
+func newFileSecretStore(filename string) *fileSecretStore {
+	return &fileSecretStore{Filename: filename}
+}
+
+// Name gets the name of the store
+//
+// implements SecretStore
+func (store *fileSecretStore) Name() string {
+	return "file"
+}
+
+// Get gets a secret
+//
+// implements SecretStore
+func (store *fileSecretStore) Get(service, key string) (string, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	if err := store.load(); err != nil {
+		return "", err
+	}
+	if secret, found := store.secrets[service][key]; found {
+		return secret, nil
+	}
+	return "", errors.NotFound.With("key", key)
+}
+
+// Set stores a secret
+//
+// implements SecretStore
+func (store *fileSecretStore) Set(service, key, secret string) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	if err := store.load(); err != nil {
+		return err
+	}
+	if _, found := store.secrets[service]; !found {
+		store.secrets[service] = map[string]string{}
+	}
+	store.secrets[service][key] = secret
+	return store.save()
+}
+
+// Delete deletes a secret
+//
+// implements SecretStore
+func (store *fileSecretStore) Delete(service, key string) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	if err := store.load(); err != nil {
+		return err
+	}
+	if _, found := store.secrets[service][key]; !found {
+		return errors.NotFound.With("key", key)
+	}
+	delete(store.secrets[service], key)
+	if len(store.secrets[service]) == 0 {
+		delete(store.secrets, service)
+	}
+	return store.save()
+}
+
+// load decrypts the secrets file if it was not loaded yet
+func (store *fileSecretStore) load() error {
+	if store.secrets != nil {
+		return nil
+	}
+	encrypted, err := os.ReadFile(store.Filename)
+	if errors.Is(err, os.ErrNotExist) {
+		store.secrets = map[string]map[string]string{}
+		return nil
+	}
+	if err != nil {
+		return errors.RuntimeError.Wrap(err)
+	}
+	passphrase, err := store.getPassphrase()
+	if err != nil {
+		return err
+	}
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return errors.RuntimeError.Wrap(err)
+	}
+	reader, err := age.Decrypt(bytes.NewReader(encrypted), identity)
+	if err != nil {
+		return errors.Join(errors.Errorf("failed to decrypt %s, is the passphrase correct?", store.Filename), err)
+	}
+	payload, err := io.ReadAll(reader)
+	if err != nil {
+		return errors.RuntimeError.Wrap(err)
+	}
+	secrets := map[string]map[string]string{}
+	if err = json.Unmarshal(payload, &secrets); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	store.secrets = secrets
+	return nil
+}
+
+// save encrypts the secrets into the secrets file
+func (store *fileSecretStore) save() error {
+	passphrase, err := store.getPassphrase()
+	if err != nil {
+		return err
+	}
+	recipient, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return errors.RuntimeError.Wrap(err)
+	}
+	payload, err := json.Marshal(store.secrets)
+	if err != nil {
+		return errors.JSONMarshalError.Wrap(err)
+	}
+	var encrypted bytes.Buffer
+	writer, err := age.Encrypt(&encrypted, recipient)
+	if err != nil {
+		return errors.RuntimeError.Wrap(err)
+	}
+	if _, err = writer.Write(payload); err != nil {
+		return errors.RuntimeError.Wrap(err)
+	}
+	if err = writer.Close(); err != nil {
+		return errors.RuntimeError.Wrap(err)
+	}
+	if err = os.MkdirAll(filepath.Dir(store.Filename), 0700); err != nil {
+		return errors.RuntimeError.Wrap(err)
+	}
+	// Write to a temporary file first, so we never leave a truncated secrets file behind
+	temp, err := os.CreateTemp(filepath.Dir(store.Filename), ".secrets-")
+	if err != nil {
+		return errors.RuntimeError.Wrap(err)
+	}
+	defer os.Remove(temp.Name())
+	if _, err = temp.Write(encrypted.Bytes()); err != nil {
+		_ = temp.Close()
+		return errors.RuntimeError.Wrap(err)
+	}
+	if err = temp.Close(); err != nil {
+		return errors.RuntimeError.Wrap(err)
+	}
+	if err = os.Chmod(temp.Name(), 0600); err != nil {
+		return errors.RuntimeError.Wrap(err)
+	}
+	return errors.RuntimeError.Wrap(os.Rename(temp.Name(), store.Filename))
+}
+
+// getPassphrase gets the passphrase from the environment or from the terminal
+func (store *fileSecretStore) getPassphrase() (string, error) {
+	if len(store.passphrase) > 0 {
+		return store.passphrase, nil
+	}
+	if passphrase, found := os.LookupEnv("BB_SECRETS_PASSPHRASE"); found && len(passphrase) > 0 {
+		store.passphrase = passphrase
+		return passphrase, nil
+	}
+	passphrase, err := readSecretFromTerminal(fmt.Sprintf("Passphrase for %s: ", store.Filename))
+	if errors.Is(err, errors.ArgumentMissing) {
+		return "", errors.ArgumentMissing.With("BB_SECRETS_PASSPHRASE")
+	}
+	if err != nil {
+		return "", err
+	}
+	store.passphrase = passphrase
+	return store.passphrase, nil
+}
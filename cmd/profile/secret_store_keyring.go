@@ -0,0 +1,50 @@
+package profile
+
+import (
+	"github.com/gildas/go-errors"
+	"github.com/zalando/go-keyring"
+)
+
+// keyringSecretStore stores secrets in the Windows Credential Manager or Linux/macOS keychain
+type keyringSecretStore struct{}
+
+// Name gets the name of the store
+//
+// implements SecretStore
+func (store keyringSecretStore) Name() string {
+	return "keyring"
+}
+
+// Get gets a secret
+//
+// implements SecretStore
+func (store keyringSecretStore) Get(service, key string) (string, error) {
+	secret, err := keyring.Get(service, key)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", errors.NotFound.With("key", key)
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get secret from keyring")
+	}
+	return secret, nil
+}
+
+// Set stores a secret
+//
+// implements SecretStore
+func (store keyringSecretStore) Set(service, key, secret string) error {
+	if err := keyring.Set(service, key, secret); err != nil {
+		return errors.Wrap(err, "failed to set secret in keyring")
+	}
+	return nil
+}
+
+// Delete deletes a secret
+//
+// implements SecretStore
+func (store keyringSecretStore) Delete(service, key string) error {
+	if err := keyring.Delete(service, key); err != nil {
+		return errors.Wrap(err, "failed to delete secret from keyring")
+	}
+	return nil
+}
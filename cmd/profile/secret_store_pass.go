@@ -0,0 +1,77 @@
+package profile
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+
+	"github.com/gildas/go-errors"
+)
+
+// passSecretStore stores secrets in pass, the standard unix password manager
+//
+// Secrets are stored as <service>/<key>
+//
+// See: https://www.passwordstore.org
+type passSecretStore struct{}
+
+// Name gets the name of the store
+//
+// implements SecretStore
+func (store passSecretStore) Name() string {
+	return "pass"
+}
+
+// Get gets a secret
+//
+// implements SecretStore
+func (store passSecretStore) Get(service, key string) (string, error) {
+	output, err := store.run("", "show", store.entry(service, key))
+	if err != nil {
+		if strings.Contains(err.Error(), "is not in the password store") {
+			return "", errors.NotFound.With("key", key)
+		}
+		return "", err
+	}
+	// pass stores the password on the first line
+	secret, _, _ := strings.Cut(output, "\n")
+	return secret, nil
+}
+
+// Set stores a secret
+//
+// implements SecretStore
+func (store passSecretStore) Set(service, key, secret string) error {
+	_, err := store.run(secret+"\n", "insert", "--multiline", "--force", store.entry(service, key))
+	return err
+}
+
+// Delete deletes a secret
+//
+// implements SecretStore
+func (store passSecretStore) Delete(service, key string) error {
+	_, err := store.run("", "rm", "--force", store.entry(service, key))
+	return err
+}
+
+// entry gets the pass entry name of a secret
+func (store passSecretStore) entry(service, key string) string {
+	if len(service) == 0 {
+		service = "bitbucket-cli"
+	}
+	return service + "/" + key
+}
+
+// run runs pass with the given arguments, sending input to its standard input
+func (store passSecretStore) run(input string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	command := exec.Command("pass", args...)
+	command.Stdin = strings.NewReader(input)
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+	if err := command.Run(); err != nil {
+		return "", errors.Errorf("pass %s failed: %s (%s)", args[0], strings.TrimSpace(stderr.String()), err)
+	}
+	return stdout.String(), nil
+}
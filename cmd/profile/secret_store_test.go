@@ -0,0 +1,132 @@
+package profile_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *ProfileSuite) TestCanStoreSecretsInEncryptedFile() {
+	filename := filepath.Join(suite.T().TempDir(), "secrets.age")
+	suite.T().Setenv("BB_SECRETS_FILE", filename)
+	suite.T().Setenv("BB_SECRETS_PASSPHRASE", "correct horse battery staple")
+
+	current := profile.Profile{Name: "test", VaultKey: "bitbucket-cli-test", SecretStore: "file"}
+	err := current.SetCredentialInVault(current.VaultKey, "john", "s3cr3t")
+	suite.Require().NoError(err, "Failed to store the secret")
+
+	payload, err := os.ReadFile(filename)
+	suite.Require().NoError(err, "The secrets file should exist")
+	suite.Assert().NotContains(string(payload), "s3cr3t", "The secrets file should be encrypted")
+	info, err := os.Stat(filename)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(os.FileMode(0600), info.Mode().Perm())
+
+	credential, err := current.GetCredentialFromVault(current.VaultKey, "john")
+	suite.Require().NoError(err, "Failed to get the secret")
+	suite.Assert().Equal("s3cr3t", credential.Password)
+
+	err = current.DeleteCredentialFromVault(current.VaultKey, "john")
+	suite.Require().NoError(err, "Failed to delete the secret")
+	_, err = current.GetCredentialFromVault(current.VaultKey, "john")
+	suite.Assert().ErrorIs(err, errors.NotFound)
+}
+
+func (suite *ProfileSuite) TestCanStoreSecretsWithCommand() {
+	folder := suite.T().TempDir()
+	script := filepath.Join(folder, "secret-helper")
+	err := os.WriteFile(script, []byte(`#!/bin/sh
+file="`+folder+`/$2-$3"
+case "$1" in
+  get)    [ -f "$file" ] || exit 1; cat "$file" ;;
+  set)    cat > "$file" ;;
+  delete) rm -f "$file" ;;
+esac
+`), 0700)
+	suite.Require().NoError(err)
+
+	current := profile.Profile{Name: "test", VaultKey: "bitbucket-cli-test", SecretStore: "command", SecretCommand: script}
+	suite.Require().NoError(current.Validate())
+	err = current.SetCredentialInVault(current.VaultKey, "client-id", "client-secret")
+	suite.Require().NoError(err, "Failed to store the secret")
+
+	credential, err := current.GetCredentialFromVault(current.VaultKey, "client-id")
+	suite.Require().NoError(err, "Failed to get the secret")
+	suite.Assert().Equal("client-secret", credential.Password)
+
+	suite.Require().NoError(current.DeleteCredentialFromVault(current.VaultKey, "client-id"))
+	_, err = current.GetCredentialFromVault(current.VaultKey, "client-id")
+	suite.Assert().ErrorIs(err, errors.NotFound)
+}
+
+type memorySecretStore struct {
+	name    string
+	secrets map[string]string
+	failOn  string
+}
+
+func (store *memorySecretStore) Name() string { return store.name }
+
+func (store *memorySecretStore) Get(service, key string) (string, error) {
+	if secret, found := store.secrets[service+"/"+key]; found {
+		return secret, nil
+	}
+	return "", errors.NotFound.With("secret", key)
+}
+
+func (store *memorySecretStore) Set(service, key, secret string) error {
+	if key == store.failOn {
+		return errors.RuntimeError.Wrap(errors.Errorf("cannot store %s", key))
+	}
+	store.secrets[service+"/"+key] = secret
+	return nil
+}
+
+func (store *memorySecretStore) Delete(service, key string) error {
+	delete(store.secrets, service+"/"+key)
+	return nil
+}
+
+func (suite *ProfileSuite) TestCanCopySecrets() {
+	source := &memorySecretStore{name: "keyring", secrets: map[string]string{"vault/client-id": "s3cr3t", "vault/token": "t0k3n"}}
+	destination := &memorySecretStore{name: "file", secrets: map[string]string{}}
+
+	copied, err := profile.CopySecrets(suite.Context, source, destination, "vault", []string{"client-id", "missing", "token"})
+	suite.Require().NoError(err)
+	suite.Assert().Equal([]string{"client-id", "token"}, copied)
+	suite.Assert().Equal("s3cr3t", destination.secrets["vault/client-id"])
+	suite.Assert().Len(source.secrets, 2, "The source store should not be modified")
+}
+
+func (suite *ProfileSuite) TestShouldKeepSecretsWhenCopyFailsOnSecondKey() {
+	source := &memorySecretStore{name: "keyring", secrets: map[string]string{"vault/client-id": "s3cr3t", "vault/token": "t0k3n"}}
+	destination := &memorySecretStore{name: "file", secrets: map[string]string{}, failOn: "token"}
+
+	_, err := profile.CopySecrets(suite.Context, source, destination, "vault", []string{"client-id", "token"})
+	suite.Require().Error(err)
+	suite.Assert().Equal("s3cr3t", source.secrets["vault/client-id"], "The first secret should still be in the source store")
+	suite.Assert().Equal("t0k3n", source.secrets["vault/token"], "The second secret should still be in the source store")
+}
+
+func (suite *ProfileSuite) TestShouldUseTheFileStoreOnlyWithAPassphrase() {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		suite.T().Skip("The keyring is always the default on Windows and macOS")
+	}
+	suite.T().Setenv("BB_SECRET_STORE", "")
+	suite.T().Setenv("DBUS_SESSION_BUS_ADDRESS", "")
+
+	suite.T().Setenv("BB_SECRETS_PASSPHRASE", "")
+	suite.Assert().Equal("keyring", profile.GetDefaultSecretStoreName(), "Without a passphrase, the keyring stays the default like before")
+
+	suite.T().Setenv("BB_SECRETS_PASSPHRASE", "s3cr3t")
+	suite.Assert().Equal("file", profile.GetDefaultSecretStoreName())
+
+	suite.T().Setenv("DBUS_SESSION_BUS_ADDRESS", "unix:path=/run/user/1000/bus")
+	suite.Assert().Equal("keyring", profile.GetDefaultSecretStoreName())
+
+	suite.T().Setenv("BB_SECRET_STORE", "pass")
+	suite.Assert().Equal("pass", profile.GetDefaultSecretStoreName())
+}
@@ -0,0 +1,185 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-flags"
+	"github.com/gildas/go-logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manage the secret stores of profiles",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Secrets requires a subcommand:")
+		for _, command := range cmd.Commands() {
+			fmt.Println(command.Name())
+		}
+	},
+}
+
+var secretsMigrateCmd = &cobra.Command{
+	Use:               "migrate [flags] <profile-name>...",
+	Short:             "move the secrets of profiles to another secret store",
+	ValidArgsFunction: ValidProfileNames,
+	PreRunE:           disableUnsupportedFlags,
+	RunE:              secretsMigrateProcess,
+}
+
+var secretsMigrateOptions struct {
+	To            *flags.EnumFlag
+	From          *flags.EnumFlag
+	SecretCommand string
+	Keep          bool
+	All           bool
+}
+
+func init() {
+	Command.AddCommand(secretsCmd)
+	secretsCmd.AddCommand(secretsMigrateCmd)
+
+	secretsMigrateOptions.To = flags.NewEnumFlag(SecretStoreNames...)
+	secretsMigrateOptions.From = flags.NewEnumFlag(SecretStoreNames...)
+	secretsMigrateCmd.Flags().Var(secretsMigrateOptions.To, "to", "Secret store to move the secrets to (keyring, file, pass, command)")
+	secretsMigrateCmd.Flags().Var(secretsMigrateOptions.From, "from", "Secret store to move the secrets from. Default is the current secret store of the profile")
+	secretsMigrateCmd.Flags().StringVar(&secretsMigrateOptions.SecretCommand, "secret-command", "", "Command to call when the destination secret store is command")
+	secretsMigrateCmd.Flags().BoolVar(&secretsMigrateOptions.Keep, "keep", false, "Keep the secrets in the source secret store")
+	secretsMigrateCmd.Flags().BoolVar(&secretsMigrateOptions.All, "all", false, "Migrate all profiles")
+	_ = secretsMigrateCmd.MarkFlagRequired("to")
+	_ = secretsMigrateCmd.RegisterFlagCompletionFunc(secretsMigrateOptions.To.CompletionFunc("to"))
+	_ = secretsMigrateCmd.RegisterFlagCompletionFunc(secretsMigrateOptions.From.CompletionFunc("from"))
+	secretsMigrateCmd.SetHelpFunc(hideUnsupportedFlags)
+}
+
+func secretsMigrateProcess(cmd *cobra.Command, args []string) (err error) {
+	log := logger.Must(logger.FromContext(cmd.Context())).Child("secrets", "migrate")
+	ctx := log.ToContext(cmd.Context())
+
+	_, err = GetProfileFromCommand(ctx, cmd)
+	if errors.Is(err, errors.Empty) || len(Profiles) == 0 {
+		return errors.Errorf("No profiles found")
+	}
+	if err != nil {
+		return err
+	}
+
+	names := args
+	if secretsMigrateOptions.All {
+		names = Profiles.Names()
+	}
+	if len(names) == 0 {
+		return errors.ArgumentMissing.With("profile")
+	}
+
+	for _, name := range names {
+		profile, found := Profiles.Find(name)
+		if !found {
+			return errors.NotFound.With("profile", name)
+		}
+		if !common.WhatIf(ctx, cmd, "Moving the secrets of profile %s to the %s secret store", profile.Name, secretsMigrateOptions.To.String()) {
+			continue
+		}
+		source, copied, err := profile.migrateSecrets(ctx, cmd)
+		if err != nil {
+			return errors.Join(errors.Errorf("Failed to move the secrets of profile %s", profile.Name), err)
+		}
+		if source == nil {
+			continue
+		}
+		// The secrets are deleted from the source store only once the configuration points to the destination store,
+		// so a failure never leaves the profile without its secrets
+		viper.Set("profiles", Profiles)
+		if err := viper.WriteConfig(); err != nil {
+			return errors.Join(errors.Errorf("Failed to save the secret store of profile %s, its secrets are still in the %s secret store", profile.Name, source.Name()), err)
+		}
+		if !secretsMigrateOptions.Keep {
+			for _, key := range copied {
+				if err := source.Delete(profile.VaultKey, key); err != nil {
+					log.Warnf("Failed to delete secret %s from the %s secret store: %s", key, source.Name(), err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// migrateSecrets copies the secrets of the profile from a secret store to another and makes the profile use the destination store
+//
+// The secrets are not deleted from the source store, the caller does it once the profile is saved.
+// Returns a nil source store if the secrets are already in the destination store
+func (profile *Profile) migrateSecrets(ctx context.Context, cmd *cobra.Command) (source SecretStore, copied []string, err error) {
+	log := logger.Must(logger.FromContext(ctx)).Child("secrets", "migrate", "profile", profile.Name)
+
+	source, err = profile.GetSecretStore()
+	if len(secretsMigrateOptions.From.String()) > 0 {
+		source, err = NewSecretStore(secretsMigrateOptions.From.String(), profile.SecretCommand)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	command := secretsMigrateOptions.SecretCommand
+	if len(command) == 0 {
+		command = os.Getenv("BB_SECRET_COMMAND")
+	}
+	destination, err := NewSecretStore(secretsMigrateOptions.To.String(), command)
+	if err != nil {
+		return nil, nil, err
+	}
+	if source == destination {
+		common.Verbose(ctx, cmd, "The secrets of profile %s are already in the %s secret store", profile.Name, destination.Name())
+		return nil, nil, nil
+	}
+
+	keys := []string{profile.getAccessTokenCacheKey()}
+	for _, secret := range profile.getSecrets() {
+		if len(secret.Value) > 0 {
+			log.Infof("The %s of profile %s is stored in clear text, use bb profile update --to-vault", secret.Description, profile.Name)
+			continue
+		}
+		keys = append(keys, secret.Key)
+	}
+	if copied, err = CopySecrets(log.ToContext(ctx), source, destination, profile.VaultKey, keys); err != nil {
+		return nil, nil, err
+	}
+	profile.SecretStore = destination.Name()
+	if destination.Name() == "command" {
+		profile.SecretCommand = command
+	} else {
+		profile.SecretCommand = ""
+	}
+	common.Verbose(ctx, cmd, "Moved the secrets of profile %s to the %s secret store (%s)", profile.Name, destination.Name(), strings.Join(copied, ", "))
+	return source, copied, nil
+}
+
+// CopySecrets copies the secrets of a service from a secret store to another
+//
+// The secrets that are not in the source store are skipped. The source store is never modified.
+// Returns the keys of the copied secrets
+func CopySecrets(ctx context.Context, source, destination SecretStore, service string, keys []string) (copied []string, err error) {
+	log := logger.Must(logger.FromContext(ctx)).Child("secrets", "copy")
+
+	copied = []string{}
+	for _, key := range keys {
+		secret, err := source.Get(service, key)
+		if errors.Is(err, errors.NotFound) {
+			log.Debugf("No secret %s in the %s secret store", key, source.Name())
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err = destination.Set(service, key, secret); err != nil {
+			return nil, err
+		}
+		copied = append(copied, key)
+		log.Infof("Copied secret %s from the %s secret store to the %s secret store", key, source.Name(), destination.Name())
+	}
+	return copied, nil
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/gildas/go-core"
@@ -40,36 +39,100 @@ func (profile *Profile) loadAccessToken(ctx context.Context) (err error) {
 		return nil
 	}
 
-	// then load the access token from the file cache
+	if profile.fromEnvironment {
+		log.Debugf("Profile %s comes from the environment, not looking in the cache or the vault", profile.Name)
+		return nil
+	}
+
+	// then load the access token from the secret store
+	if token, err := profile.loadCachedAccessToken(ctx); err == nil {
+		log.Infof("Loaded access token from cache for profile %s", profile.Name)
+		log.Record("token", token).Debugf("Access token details for profile %s", profile.Name)
+		profile.token = token
+		return nil
+	}
+
+	// Load the access token from the vault in case this is an API Token
+	log.Debugf("Looking for access token in the vault for profile %s", profile.Name)
+	if credential, err := profile.GetCredentialFromVault(profile.VaultKey, profile.Name); err == nil {
+		profile.AccessToken = credential.Password
+		log.Infof("Loaded Repository/Project/Workspace Access Token for profile %s from the vault", profile.Name)
+		profile.token = &Token{
+			AccessToken: profile.AccessToken,
+			ExpiresOn:   core.Timestamp(time.Now().Add(100 * 365 * 24 * time.Hour)), // Loaded Access Tokens never expire
+		}
+	} else {
+		log.Errorf("failed to get access token for profile %s: %v", profile.Name, err)
+	}
+	return nil // We don't return an error if the token is not found, so the authorization process can continue
+}
+
+// getAccessTokenCacheKey gets the key of the cached access token in the secret store
+func (profile Profile) getAccessTokenCacheKey() string {
+	return "access-token-" + profile.Name
+}
+
+// getLegacyAccessTokenCacheFilename gets the file where older versions cached the access token in clear text
+func (profile Profile) getLegacyAccessTokenCacheFilename() (string, error) {
 	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "bitbucket", profile.getAccessTokenCacheKey()), nil
+}
+
+// deleteCachedAccessToken deletes the OAuth access token from the secret store and the legacy cache file
+func (profile Profile) deleteCachedAccessToken() error {
+	if filename, err := profile.getLegacyAccessTokenCacheFilename(); err == nil {
+		_ = os.Remove(filename)
+	}
+	store, err := profile.GetSecretStore()
+	if err != nil {
+		return err
+	}
+	return store.Delete(profile.VaultKey, profile.getAccessTokenCacheKey())
+}
+
+// loadCachedAccessToken loads the OAuth access token from the secret store
+//
+// If the token is still in the clear text cache file of older versions, it is moved to the secret store.
+func (profile *Profile) loadCachedAccessToken(ctx context.Context) (*Token, error) {
+	log := logger.Must(logger.FromContext(ctx)).Child("profile", "loadCachedAccessToken")
+	var token Token
+
+	store, err := profile.GetSecretStore()
+	if err != nil {
+		return nil, err
+	}
+	payload, err := store.Get(profile.VaultKey, profile.getAccessTokenCacheKey())
 	if err == nil {
-		accessTokenFile := filepath.Join(cacheDir, "bitbucket", "access-token-"+profile.Name)
-		data, err := os.ReadFile(accessTokenFile)
-		if err == nil {
-			var token Token
-			if err = json.Unmarshal(data, &token); err == nil {
-				log.Infof("Loaded access token from cache for profile %s", profile.Name)
-				log.Record("token", token).Debugf("Access token details for profile %s", profile.Name)
-				profile.token = &token
-				return nil
-			}
-		}
-		// Load the access token from the vault in case this is an API Token
-		log.Debugf("Looking for access token in the vault for profile %s", profile.Name)
-		if credential, err := profile.GetCredentialFromVault(profile.VaultKey, profile.Name); err == nil {
-			profile.AccessToken = credential.Password
-			log.Infof("Loaded Repository/Project/Workspace Access Token for profile %s from the vault", profile.Name)
-			profile.token = &Token{
-				AccessToken: profile.AccessToken,
-				ExpiresOn:   core.Timestamp(time.Now().Add(100 * 365 * 24 * time.Hour)), // Loaded Access Tokens never expire
-			}
-			return nil
-		} else {
-			log.Errorf("failed to get access token for profile %s: %v", profile.Name, err)
-			return nil // We don't return an error if the token is not found, so the authorization process can continue
+		if err = json.Unmarshal([]byte(payload), &token); err != nil {
+			return nil, errors.JSONUnmarshalError.Wrap(err)
 		}
+		return &token, nil
+	}
+	if !errors.Is(err, errors.NotFound) {
+		log.Warnf("Failed to read the access token of profile %s from the %s secret store: %s", profile.Name, store.Name(), err)
+	}
+
+	filename, err := profile.getLegacyAccessTokenCacheFilename()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
 	}
-	return err
+	if err = json.Unmarshal(data, &token); err != nil {
+		return nil, errors.JSONUnmarshalError.Wrap(err)
+	}
+	if err = store.Set(profile.VaultKey, profile.getAccessTokenCacheKey(), string(data)); err == nil {
+		log.Infof("Moved the cached access token of profile %s to the %s secret store", profile.Name, store.Name())
+		_ = os.Remove(filename)
+	} else {
+		log.Warnf("Failed to move the cached access token of profile %s to the %s secret store: %s", profile.Name, store.Name(), err)
+	}
+	return &token, nil
 }
 
 // isTokenExpired tells if the token is expired
@@ -87,19 +150,43 @@ func (profile *Profile) saveAccessToken(ctx context.Context, data []byte) (acces
 		return "", err
 	}
 
-	if cacheDir, err := os.UserCacheDir(); err == nil {
-		cachePath := filepath.Join(cacheDir, "bitbucket")
-		if err = os.MkdirAll(cachePath, 0700); err == nil {
-			cacheFile := filepath.Join(cachePath, "access-token-"+profile.Name)
-			payload, _ := json.Marshal(profile.token)
-			if err = os.WriteFile(cacheFile, payload, 0600); err != nil {
-				log.Errorf("Failed to save access token to cache for profile %s", profile.Name, err)
-			}
+	if profile.fromEnvironment {
+		log.Debugf("Profile %s comes from the environment, not caching its access token", profile.Name)
+		return profile.token.AccessToken, nil
+	}
+
+	payload, _ := json.Marshal(profile.token)
+	if store, err := profile.GetSecretStore(); err == nil {
+		if err = store.Set(profile.VaultKey, profile.getAccessTokenCacheKey(), string(payload)); err == nil {
+			return profile.token.AccessToken, nil
 		}
+		log.Warnf("Failed to cache the access token of profile %s in the %s secret store, using the cache file: %s", profile.Name, store.Name(), err)
+	} else {
+		log.Warnf("Failed to get the secret store of profile %s, using the cache file: %s", profile.Name, err)
+	}
+	if err := profile.saveLegacyCachedAccessToken(payload); err != nil {
+		log.Warnf("Failed to cache the access token of profile %s, it will not be reused: %s", profile.Name, err)
 	}
 	return profile.token.AccessToken, nil
 }
 
+// saveLegacyCachedAccessToken saves the OAuth access token in the cache file of older versions
+//
+// It is used when the secret store cannot be used, like older versions did
+func (profile Profile) saveLegacyCachedAccessToken(payload []byte) error {
+	filename, err := profile.getLegacyAccessTokenCacheFilename()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return errors.RuntimeError.Wrap(err)
+	}
+	if err = os.WriteFile(filename, payload, 0600); err != nil {
+		return errors.RuntimeError.Wrap(err)
+	}
+	return nil
+}
+
 // Redact redacts sensitive information from the token
 //
 // implements logger.Redactable
@@ -139,7 +226,7 @@ func (token *Token) GetExpiredSince() time.Duration {
 
 // GetScopes returns the scopes of the token
 func (token *Token) GetScopes() []string {
-	return strings.Split(token.Scope, " ")
+	return parseScopes(token.Scope)
 }
 
 // UnmarshalTokenFromBitbucketData unmarshals the token data from the BitBucket response
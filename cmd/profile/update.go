@@ -53,6 +53,8 @@ func init() {
 	updateCmd.Flags().StringVar(&updateOptions.ClientSecret, "client-secret", "", "Client Secret of the profile")
 	updateCmd.Flags().Uint16Var(&updateOptions.CallbackPort, "callback-port", 0, "Callback port to use for OAuth2 authentication. If not set, a random port will be used.")
 	updateCmd.Flags().StringVar(&updateOptions.AccessToken, "access-token", "", "Access Token of the profile")
+	updateCmd.Flags().StringVar(&updateOptions.Email, "email", "", "Email of the Atlassian account that owns the API token")
+	updateCmd.Flags().StringVar(&updateOptions.APIToken, "api-token", "", "Atlassian API token of the profile")
 	updateCmd.Flags().BoolVar(&updateOptions.ToVault, "to-vault", false, "Store credentials in the vault. This will remove any credentials from the profile and store them in the vault. If the vault key is not provided, it will use the existing vault key of the profile or the default vault key if not set.")
 	updateCmd.Flags().BoolVar(&updateOptions.NoVault, "no-vault", false, "Do not use a vault for storing credentials")
 	updateCmd.Flags().Var(updateOptions.DefaultWorkspace, "default-workspace", "Default workspace of the profile")
@@ -66,13 +68,16 @@ func init() {
 	updateCmd.Flags().BoolVar(&updateOptions.Progress, "progress", false, "Show progress during upload/download operations.")
 	updateCmd.MarkFlagsRequiredTogether("user", "password")
 	updateCmd.MarkFlagsRequiredTogether("client-id", "client-secret")
-	updateCmd.MarkFlagsMutuallyExclusive("user", "client-id", "access-token")
+	updateCmd.MarkFlagsMutuallyExclusive("user", "client-id", "access-token", "email")
+	updateCmd.MarkFlagsMutuallyExclusive("user", "client-id", "access-token", "api-token")
 	updateCmd.MarkFlagsMutuallyExclusive("to-vault", "no-vault")
 	updateCmd.MarkFlagsMutuallyExclusive("to-vault", "access-token")
 	updateCmd.MarkFlagsMutuallyExclusive("to-vault", "client-id")
 	updateCmd.MarkFlagsMutuallyExclusive("to-vault", "client-secret")
 	updateCmd.MarkFlagsMutuallyExclusive("to-vault", "user")
 	updateCmd.MarkFlagsMutuallyExclusive("to-vault", "password")
+	updateCmd.MarkFlagsMutuallyExclusive("to-vault", "email")
+	updateCmd.MarkFlagsMutuallyExclusive("to-vault", "api-token")
 	_ = updateCmd.MarkFlagFilename("default-ssh-key-file")
 	_ = updateCmd.RegisterFlagCompletionFunc(updateOptions.DefaultWorkspace.CompletionFunc("default-workspace"))
 	_ = updateCmd.RegisterFlagCompletionFunc(updateOptions.DefaultProject.CompletionFunc("default-project"))
@@ -127,7 +132,14 @@ func updateProcess(cmd *cobra.Command, args []string) (err error) {
 			vaultKey = updateOptions.VaultKey
 		}
 
-		if len(profile.ClientSecret) > 0 {
+		if len(profile.APIToken) > 0 {
+			if err := profile.SetCredentialInVault(vaultKey, profile.Email, profile.APIToken); err != nil {
+				return errors.Join(errors.Errorf("Failed to store API token in the vault"), err)
+			}
+			log.Infof("Stored API token in the vault for %s", profile.Email)
+			profile.APIToken = ""
+			updateOptions.APIToken = ""
+		} else if len(profile.ClientSecret) > 0 {
 			if err := profile.SetCredentialInVault(vaultKey, profile.ClientID, profile.ClientSecret); err != nil {
 				return errors.Join(errors.Errorf("Failed to store client secret in the vault"), err)
 			}
@@ -151,7 +163,7 @@ func updateProcess(cmd *cobra.Command, args []string) (err error) {
 		}
 	}
 
-	if len(profile.AccessToken) > 0 || len(profile.ClientSecret) > 0 || len(profile.Password) > 0 {
+	if len(profile.AccessToken) > 0 || len(profile.ClientSecret) > 0 || len(profile.Password) > 0 || len(profile.APIToken) > 0 {
 		log.Infof("Profile %s stored its credentials in plain text, we should keep it that way", profile.Name)
 		updateOptions.NoVault = true
 	}
@@ -194,6 +206,24 @@ func updateProcess(cmd *cobra.Command, args []string) (err error) {
 			}
 		}
 	}
+	if cmd.Flag("api-token").Changed && len(updateOptions.APIToken) > 0 {
+		email := profile.Email
+		if cmd.Flag("email").Changed && len(updateOptions.Email) > 0 {
+			email = updateOptions.Email
+		}
+		if len(email) == 0 {
+			return errors.ArgumentMissing.With("email", "An email is required when using an API token")
+		}
+		if !updateOptions.NoVault {
+			if err := updateOptions.SetCredentialInVault(updateOptions.VaultKey, email, updateOptions.APIToken); err != nil {
+				log.Errorf("Failed to store API token in the vault, the token will be stored in plain text in the configuration file", err)
+				fmt.Fprintf(os.Stderr, "Failed to store API token in the vault, the token will be stored in plain text in the configuration file: %s\n", err)
+			} else {
+				log.Infof("Stored API token in the vault for %s", email)
+				updateOptions.APIToken = "" // Clear the API token from the profile
+			}
+		}
+	}
 	if cmd.Flag("access-token").Changed && len(updateOptions.AccessToken) > 0 {
 		name := profile.Name
 		if cmd.Flag("name").Changed && len(updateOptions.Name) > 0 {
@@ -210,6 +240,9 @@ func updateProcess(cmd *cobra.Command, args []string) (err error) {
 		}
 	}
 
+	if len(updateOptions.Email) > 0 {
+		updateOptions.Auth = AuthAPIToken
+	}
 	err = profile.Update(updateOptions.Profile)
 	if err != nil {
 		return err
@@ -26,6 +26,9 @@ func whichProcess(cmd *cobra.Command, args []string) (err error) {
 	ctx := log.ToContext(cmd.Context())
 
 	profile, err := GetProfileFromCommand(ctx, cmd)
+	if err == nil && profile.IsFromEnvironment() {
+		return profile.Print(ctx, cmd, profile)
+	}
 	if errors.Is(err, errors.Empty) || len(Profiles) == 0 {
 		if cmd.Flag("stop-on-error").Value.String() == "true" {
 			return errors.Errorf("No profiles found")
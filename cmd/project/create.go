@@ -24,12 +24,13 @@ type ProjectCreator struct {
 }
 
 var createCmd = &cobra.Command{
-	Use:     "create",
-	Aliases: []string{"add", "new"},
-	Short:   "create a project",
-	Args:    cobra.NoArgs,
-	PreRunE: disableUnsupportedFlags,
-	RunE:    createProcess,
+	Use:         "create",
+	Aliases:     []string{"add", "new"},
+	Short:       "create a project",
+	Annotations: map[string]string{profile.ScopesAnnotation: "project:admin"},
+	Args:        cobra.NoArgs,
+	PreRunE:     disableUnsupportedFlags,
+	RunE:        createProcess,
 }
 
 var createOptions struct {
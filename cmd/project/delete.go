@@ -16,6 +16,7 @@ var deleteCmd = &cobra.Command{
 	Use:               "delete [flags] <project-key...>",
 	Aliases:           []string{"remove", "rm"},
 	Short:             "delete projects by their <project-key>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "project:admin"},
 	Args:              cobra.MinimumNArgs(1),
 	ValidArgsFunction: deleteValidArgs,
 	PreRunE:           disableUnsupportedFlags,
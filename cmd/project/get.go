@@ -16,6 +16,7 @@ var getCmd = &cobra.Command{
 	Use:               "get [flags] <project-key>",
 	Aliases:           []string{"show", "info", "display"},
 	Short:             "get a project by its <project-key>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "project"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: getValidArgs,
 	PreRunE:           disableUnsupportedFlags,
@@ -14,11 +14,12 @@ import (
 )
 
 var listCmd = &cobra.Command{
-	Use:     "list",
-	Short:   "list all projects",
-	Args:    cobra.NoArgs,
-	PreRunE: disableUnsupportedFlags,
-	RunE:    listProcess,
+	Use:         "list",
+	Short:       "list all projects",
+	Annotations: map[string]string{profile.ScopesAnnotation: "project"},
+	Args:        cobra.NoArgs,
+	PreRunE:     disableUnsupportedFlags,
+	RunE:        listProcess,
 }
 
 var listOptions struct {
@@ -14,12 +14,13 @@ import (
 )
 
 var addCmd = &cobra.Command{
-	Use:     "add",
-	Aliases: []string{"append"},
-	Short:   "add a reviewer",
-	Args:    cobra.ExactArgs(1),
-	PreRunE: disableUnsupportedFlags,
-	RunE:    addProcess,
+	Use:         "add",
+	Aliases:     []string{"append"},
+	Short:       "add a reviewer",
+	Annotations: map[string]string{profile.ScopesAnnotation: "project:admin"},
+	Args:        cobra.ExactArgs(1),
+	PreRunE:     disableUnsupportedFlags,
+	RunE:        addProcess,
 }
 
 var addOptions struct {
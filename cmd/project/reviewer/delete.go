@@ -17,6 +17,7 @@ var deleteCmd = &cobra.Command{
 	Use:               "delete [flags] <user-id...>",
 	Aliases:           []string{"remove"},
 	Short:             "delete  reviewers by their <user-id>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "project:admin"},
 	ValidArgsFunction: deleteValidArgs,
 	Args:              cobra.MinimumNArgs(1),
 	PreRunE:           disableUnsupportedFlags,
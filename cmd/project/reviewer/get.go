@@ -17,6 +17,7 @@ var getCmd = &cobra.Command{
 	Use:               "get",
 	Aliases:           []string{"show", "info", "display"},
 	Short:             "get a reviewer",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "project:admin"},
 	ValidArgsFunction: getValidArgs,
 	Args:              cobra.ExactArgs(1),
 	PreRunE:           disableUnsupportedFlags,
@@ -12,11 +12,12 @@ import (
 )
 
 var listCmd = &cobra.Command{
-	Use:     "list",
-	Short:   "list all reviewers",
-	Args:    cobra.NoArgs,
-	PreRunE: disableUnsupportedFlags,
-	RunE:    listProcess,
+	Use:         "list",
+	Short:       "list all reviewers",
+	Annotations: map[string]string{profile.ScopesAnnotation: "project:admin"},
+	Args:        cobra.NoArgs,
+	PreRunE:     disableUnsupportedFlags,
+	RunE:        listProcess,
 }
 
 var listOptions struct {
@@ -27,6 +27,7 @@ var updateCmd = &cobra.Command{
 	Use:               "update [flags] <project-key>",
 	Aliases:           []string{"edit"},
 	Short:             "update a project by its <project-key>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "project:admin"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: updateValidArgs,
 	PreRunE:           disableUnsupportedFlags,
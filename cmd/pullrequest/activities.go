@@ -3,6 +3,7 @@ package pullrequest
 import (
 	"fmt"
 	"net/url"
+	"time"
 
 	"github.com/gildas/bitbucket-cli/cmd/common"
 	"github.com/gildas/bitbucket-cli/cmd/profile"
@@ -43,8 +44,15 @@ func (activities Activities) Size() int {
 }
 
 var activitiesCmd = &cobra.Command{
-	Use:               "activities",
-	Short:             "List all activities of a pullrequest",
+	Use:   "activities [flags] <pullrequest-id>",
+	Short: "List all activities of a pullrequest",
+	Long: `List all activities (approvals, updates, and comments) of a pullrequest, or of all the pullrequests of the repository with --all.
+
+With --follow, the activities are polled and the new ones are printed as they happen, one per line (as JSON with --output json or ndjson).
+With --exec type=command, the command is run by the shell for each new activity of that type (approval, update, comment, or all).
+The activity is given as JSON on the standard input of the command, and its main fields in the BB_EVENT_TYPE, BB_EVENT_DATE, BB_EVENT_USER,
+BB_EVENT_SUMMARY, BB_PULLREQUEST_ID, and BB_PULLREQUEST_TITLE environment variables.`,
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest"},
 	Args:              cobra.MaximumNArgs(1),
 	ValidArgsFunction: activitiesValidArgs,
 	RunE:              activitiesProcess,
@@ -52,6 +60,10 @@ var activitiesCmd = &cobra.Command{
 
 var activitiesOptions struct {
 	Query      string
+	All        bool
+	Follow     bool
+	Interval   time.Duration
+	Exec       []string
 	Columns    *flags.EnumSliceFlag
 	SortBy     *flags.EnumFlag
 	PageLength int
@@ -63,11 +75,16 @@ func init() {
 	activitiesOptions.Columns = flags.NewEnumSliceFlagWithAllAllowed(activityColumns.Columns()...)
 	activitiesOptions.SortBy = flags.NewEnumFlag(activityColumns.Sorters()...)
 	activitiesCmd.Flags().StringVar(&activitiesOptions.Query, "query", "", "Query string to filter activities")
+	activitiesCmd.Flags().BoolVar(&activitiesOptions.All, "all", false, "Show the activities of all the pullrequests of the repository")
+	activitiesCmd.Flags().BoolVarP(&activitiesOptions.Follow, "follow", "f", false, "Print the new activities as they happen, until interrupted")
+	activitiesCmd.Flags().DurationVar(&activitiesOptions.Interval, "interval", 30*time.Second, "Interval between two polls with --follow")
+	activitiesCmd.Flags().StringArrayVar(&activitiesOptions.Exec, "exec", []string{}, "Command to run for each new activity with --follow, as type=command where type is approval, update, comment, or all. Can be repeated")
 	activitiesCmd.Flags().Var(activitiesOptions.Columns, "columns", "Comma-separated list of columns to display")
 	activitiesCmd.Flags().Var(activitiesOptions.SortBy, "sort", "Column to sort by")
 	activitiesCmd.Flags().IntVar(&activitiesOptions.PageLength, "page-length", 0, "Number of items per page to retrieve from Bitbucket. Default is the profile's default page length")
 	_ = activitiesCmd.RegisterFlagCompletionFunc(activitiesOptions.Columns.CompletionFunc("columns"))
 	_ = activitiesCmd.RegisterFlagCompletionFunc(activitiesOptions.SortBy.CompletionFunc("sort"))
+	activitiesCmd.MarkFlagsMutuallyExclusive("all", "query")
 }
 
 func activitiesValidArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -96,22 +113,47 @@ func activitiesProcess(cmd *cobra.Command, args []string) (err error) {
 		return errors.Join(errors.Errorf("Cannot list activities for Pull Request"), err)
 	}
 
-	pullRequestID, err := GetPullRequestIDFromArgs(cmd.Context(), cmd, repository, args)
-	if err != nil {
-		return errors.Join(errors.Errorf("Cannot list activities for Pull Request"), err)
+	hooks := make([]ActivityHook, 0, len(activitiesOptions.Exec))
+	for _, value := range activitiesOptions.Exec {
+		hook, err := ParseActivityHook(value)
+		if err != nil {
+			return errors.Join(errors.Errorf("Invalid --exec %s, it should be type=command where type is approval, update, comment, or all", value), err)
+		}
+		hooks = append(hooks, hook)
+	}
+	if len(hooks) > 0 && !activitiesOptions.Follow {
+		return errors.ArgumentInvalid.With("exec", "requires --follow")
 	}
 
-	uripath := repository.GetPath(fmt.Sprintf("pullrequests/%s/activity", pullRequestID))
-
-	if len(listOptions.Query) > 0 {
-		uripath = fmt.Sprintf("%s?q=%s", uripath, url.QueryEscape(listOptions.Query))
+	var uripath string
+	var target string
+	if activitiesOptions.All {
+		if len(args) > 0 {
+			return errors.ArgumentInvalid.With("pullrequest-id", "cannot be used with --all")
+		}
+		uripath = repository.GetPath("pullrequests", "activity")
+		target = "all pullrequests"
+	} else {
+		pullRequestID, err := GetPullRequestIDFromArgs(cmd.Context(), cmd, repository, args)
+		if err != nil {
+			return errors.Join(errors.Errorf("Cannot list activities for Pull Request"), err)
+		}
+		uripath = repository.GetPath(fmt.Sprintf("pullrequests/%s/activity", pullRequestID))
+		target = "pullrequest " + pullRequestID
+		if len(activitiesOptions.Query) > 0 {
+			uripath = fmt.Sprintf("%s?q=%s", uripath, url.QueryEscape(activitiesOptions.Query))
+		}
 	}
 
 	log.Infof("Listing all activities from repository %s with profile %s", repository, currentProfile)
-	if !common.WhatIf(log.ToContext(cmd.Context()), cmd, fmt.Sprintf("Showing activities for pullrequest %s in repository %s with profile %s", pullRequestID, repository, currentProfile)) {
+	if !common.WhatIf(log.ToContext(cmd.Context()), cmd, fmt.Sprintf("Showing activities for %s in repository %s with profile %s", target, repository, currentProfile)) {
 		return nil
 	}
 
+	if activitiesOptions.Follow {
+		return followActivities(log.ToContext(cmd.Context()), cmd, currentProfile, uripath, hooks)
+	}
+
 	activities, err := profile.GetAll[Activity](cmd.Context(), cmd, uripath)
 	if err != nil {
 		return err
@@ -120,7 +162,7 @@ func activitiesProcess(cmd *cobra.Command, args []string) (err error) {
 		log.Infof("No activities found")
 		return nil
 	}
-	core.Sort(activities, activityColumns.SortBy(listOptions.SortBy.Value))
+	core.Sort(activities, activityColumns.SortBy(activitiesOptions.SortBy.Value))
 	return currentProfile.Print(
 		cmd.Context(),
 		cmd,
@@ -2,6 +2,7 @@ package pullrequest
 
 import (
 	"encoding/json"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -232,6 +233,71 @@ func (activity Activity) GetRow(headers []string) []string {
 	return row
 }
 
+// GetType gets the type of the activity: approval, update, or comment
+func (activity Activity) GetType() string {
+	switch {
+	case activity.Approval != nil:
+		return "approval"
+	case activity.Update != nil:
+		return "update"
+	case activity.Comment != nil:
+		return "comment"
+	default:
+		return ""
+	}
+}
+
+// GetDate gets the date of the activity
+func (activity Activity) GetDate() time.Time {
+	switch {
+	case activity.Approval != nil:
+		return activity.Approval.Date
+	case activity.Update != nil:
+		return activity.Update.Date
+	case activity.Comment != nil:
+		return activity.Comment.CreatedOn
+	default:
+		return time.Time{}
+	}
+}
+
+// GetUser gets the user who did the activity
+func (activity Activity) GetUser() user.User {
+	switch {
+	case activity.Approval != nil:
+		return activity.Approval.User
+	case activity.Update != nil:
+		return activity.Update.Author
+	case activity.Comment != nil:
+		return activity.Comment.User
+	default:
+		return user.User{}
+	}
+}
+
+// GetKey gets a key that identifies the activity
+func (activity Activity) GetKey() string {
+	if activity.Comment != nil {
+		return fmt.Sprintf("comment:%d", activity.Comment.ID)
+	}
+	return fmt.Sprintf("%s:%d:%s:%s", activity.GetType(), activity.PullRequest.ID, activity.GetUser().ID, activity.GetDate().Format(time.RFC3339Nano))
+}
+
+// Summary gets a one line description of the activity
+func (activity Activity) Summary() string {
+	switch {
+	case activity.Approval != nil:
+		return fmt.Sprintf("%s approved", activity.Approval.User.Name)
+	case activity.Update != nil:
+		return fmt.Sprintf("%s updated the pullrequest (%s)", activity.Update.Author.Name, activity.Update.State)
+	case activity.Comment != nil:
+		line, _, _ := strings.Cut(strings.TrimSpace(activity.Comment.Content.Raw), "\n")
+		return fmt.Sprintf("%s commented: %s", activity.Comment.User.Name, strings.TrimRight(line, "\r"))
+	default:
+		return ""
+	}
+}
+
 // Validate validates a Comment
 func (activity *Activity) Validate() error {
 	var merr errors.MultiError
@@ -15,11 +15,12 @@ import (
 )
 
 var listCmd = &cobra.Command{
-	Use:        "list",
-	Short:      "list all pullrequest Activities",
-	Deprecated: "Please use 'pullrequest activities' instead",
-	Args:       cobra.MaximumNArgs(1),
-	RunE:       listProcess,
+	Use:         "list",
+	Short:       "list all pullrequest Activities",
+	Annotations: map[string]string{profile.ScopesAnnotation: "pullrequest"},
+	Deprecated:  "Please use 'pullrequest activities' instead",
+	Args:        cobra.MaximumNArgs(1),
+	RunE:        listProcess,
 }
 
 var listOptions struct {
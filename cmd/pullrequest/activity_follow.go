@@ -0,0 +1,177 @@
+package pullrequest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/gildas/go-core"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/spf13/cobra"
+)
+
+// ActivityFollower tracks the activities already seen while following the activity of pullrequests
+type ActivityFollower struct {
+	seen     map[string]bool
+	lastSeen time.Time
+}
+
+// ActivityHook is a command to run when an activity of a given type happens
+type ActivityHook struct {
+	Type    string // approval, update, comment, or all
+	Command string
+}
+
+// NewActivityFollower creates a new ActivityFollower, the given activities are already seen
+func NewActivityFollower(activities []Activity) *ActivityFollower {
+	follower := &ActivityFollower{seen: map[string]bool{}}
+	_ = follower.Next(activities)
+	return follower
+}
+
+// Next gets the activities that were not seen yet, the oldest first, and marks them as seen
+//
+// Activities older than the last seen one are ignored, they were seen on a previous page or deleted since
+func (follower *ActivityFollower) Next(activities []Activity) []Activity {
+	unseen := core.Filter(activities, func(activity Activity) bool {
+		return len(activity.GetType()) > 0 && !follower.seen[activity.GetKey()] && !activity.GetDate().Before(follower.lastSeen)
+	})
+	core.Sort(unseen, func(a, b Activity) bool { return a.GetDate().Before(b.GetDate()) })
+	for _, activity := range unseen {
+		follower.seen[activity.GetKey()] = true
+		if activity.GetDate().After(follower.lastSeen) {
+			follower.lastSeen = activity.GetDate()
+		}
+	}
+	return unseen
+}
+
+// ParseActivityHook parses a hook given as type=command
+func ParseActivityHook(value string) (ActivityHook, error) {
+	eventType, command, found := strings.Cut(value, "=")
+	eventType = strings.ToLower(strings.TrimSpace(eventType))
+	if !found || len(strings.TrimSpace(command)) == 0 {
+		return ActivityHook{}, errors.ArgumentInvalid.With("exec", value)
+	}
+	switch eventType {
+	case "approval", "update", "comment", "all":
+		return ActivityHook{Type: eventType, Command: command}, nil
+	default:
+		return ActivityHook{}, errors.ArgumentInvalid.With("exec", value)
+	}
+}
+
+// Matches tells if the hook should run for the given activity
+func (hook ActivityHook) Matches(activity Activity) bool {
+	return hook.Type == "all" || hook.Type == activity.GetType()
+}
+
+// Run runs the command of the hook with the shell
+//
+// The activity is given as JSON on the standard input and its main fields in BB_* environment variables
+func (hook ActivityHook) Run(ctx context.Context, activity Activity) error {
+	log := logger.Must(logger.FromContext(ctx)).Child("activity", "hook", "type", hook.Type)
+	payload, err := json.Marshal(activity)
+	if err != nil {
+		return errors.JSONMarshalError.Wrap(err)
+	}
+
+	var command *exec.Cmd
+	if runtime.GOOS == "windows" {
+		command = exec.CommandContext(ctx, "cmd", "/C", hook.Command)
+	} else {
+		command = exec.CommandContext(ctx, "sh", "-c", hook.Command)
+	}
+	command.Env = append(os.Environ(),
+		"BB_EVENT_TYPE="+activity.GetType(),
+		"BB_EVENT_DATE="+activity.GetDate().Format(time.RFC3339),
+		"BB_EVENT_USER="+activity.GetUser().Name,
+		"BB_EVENT_SUMMARY="+activity.Summary(),
+		"BB_PULLREQUEST_ID="+strconv.FormatUint(activity.PullRequest.ID, 10),
+		"BB_PULLREQUEST_TITLE="+activity.PullRequest.Title,
+	)
+	command.Stdin = strings.NewReader(string(payload))
+	command.Stdout = os.Stderr
+	command.Stderr = os.Stderr
+	log.Infof("Running %s", hook.Command)
+	if err := command.Run(); err != nil {
+		return errors.Join(errors.Errorf("The command for %s events failed: %s", hook.Type, hook.Command), err)
+	}
+	return nil
+}
+
+// followActivities polls the activity of pullrequests and prints the new ones until the command is interrupted
+func followActivities(ctx context.Context, cmd *cobra.Command, currentProfile *profile.Profile, uripath string, hooks []ActivityHook) error {
+	log := logger.Must(logger.FromContext(ctx)).Child("activity", "follow")
+
+	// Only the first page is needed, the activities come the newest first
+	getLatest := func() ([]Activity, error) {
+		var page profile.PaginatedResources[Activity]
+		separator := "?"
+		if strings.Contains(uripath, "?") {
+			separator = "&"
+		}
+		if err := currentProfile.Get(ctx, cmd, uripath+separator+"pagelen=50", &page); err != nil {
+			return nil, err
+		}
+		return page.Values, nil
+	}
+
+	activities, err := getLatest()
+	if err != nil {
+		return errors.Join(errors.Errorf("Failed to get the activities"), err)
+	}
+	follower := NewActivityFollower(activities)
+	outputFormat := getOutputFormat(cmd, currentProfile)
+	fmt.Fprintf(os.Stderr, "Following the activities every %s, press Ctrl+C to stop\n", activitiesOptions.Interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(activitiesOptions.Interval):
+		}
+		activities, err := getLatest()
+		if err != nil {
+			// A network hiccup should not stop a long running follow
+			log.Warnf("Failed to get the activities: %s", err)
+			continue
+		}
+		for _, activity := range follower.Next(activities) {
+			if err := printActivityEvent(outputFormat, activity); err != nil {
+				return err
+			}
+			for _, hook := range hooks {
+				if hook.Matches(activity) {
+					if err := hook.Run(ctx, activity); err != nil {
+						log.Errorf("Failed to run hook: %s", err)
+						fmt.Fprintln(os.Stderr, err)
+					}
+				}
+			}
+		}
+	}
+}
+
+// printActivityEvent prints an activity on one line, as JSON for the json and ndjson formats
+func printActivityEvent(outputFormat string, activity Activity) error {
+	switch outputFormat {
+	case "json", "ndjson":
+		payload, err := json.Marshal(activity)
+		if err != nil {
+			return errors.JSONMarshalError.Wrap(err)
+		}
+		fmt.Println(string(payload))
+	default:
+		fmt.Printf("%s  #%d %s  %s\n", activity.GetDate().Local().Format("2006-01-02 15:04:05"), activity.PullRequest.ID, activity.PullRequest.Title, activity.Summary())
+	}
+	return nil
+}
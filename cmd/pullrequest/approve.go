@@ -1,6 +1,9 @@
 package pullrequest
 
 import (
+	"context"
+	"strconv"
+
 	"github.com/gildas/bitbucket-cli/cmd/common"
 	"github.com/gildas/bitbucket-cli/cmd/profile"
 	"github.com/gildas/bitbucket-cli/cmd/pullrequest/common"
@@ -14,13 +17,18 @@ import (
 var approveCmd = &cobra.Command{
 	Use:               "approve [flags] <pullrequest-id>",
 	Short:             "approve a pullrequest by its <pullrequest-id>. If not provided, it will try to approve the only open pullrequest.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest:write"},
 	Args:              cobra.MaximumNArgs(1),
 	ValidArgsFunction: approveValidArgs,
 	RunE:              approveProcess,
 }
 
+var approveOptions bulkOptions
+
 func init() {
 	Command.AddCommand(approveCmd)
+
+	approveOptions.addFlags(approveCmd, "Approve")
 }
 
 func approveValidArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -51,6 +59,23 @@ func approveProcess(cmd *cobra.Command, args []string) (err error) {
 		return errors.Join(errors.Errorf("Cannot approve Pull Request"), err)
 	}
 
+	if approveOptions.isBulk(cmd) {
+		if len(args) > 0 {
+			return errors.ArgumentInvalid.With("pullrequest-id", "cannot be used with filters")
+		}
+		pullrequests, err := approveOptions.getPullRequests(log.ToContext(cmd.Context()), cmd, repository)
+		if err != nil {
+			return errors.Join(errors.Errorf("Cannot approve Pull Requests"), err)
+		}
+		return approveOptions.processBulk(log.ToContext(cmd.Context()), cmd, profile, "Approving", pullrequests, func(ctx context.Context, pullrequest PullRequest) error {
+			var participant user.Participant
+			if err := profile.Post(ctx, cmd, repository.GetPath("pullrequests", strconv.FormatUint(pullrequest.ID, 10), "approve"), nil, &participant); err != nil {
+				return errors.Join(errors.Errorf("Failed to approve Pull Request %d", pullrequest.ID), err)
+			}
+			return nil
+		})
+	}
+
 	pullRequestID, err := GetPullRequestIDFromArgs(cmd.Context(), cmd, repository, args)
 	if err != nil {
 		return errors.Join(errors.Errorf("Cannot approve Pull Request"), err)
@@ -0,0 +1,218 @@
+package pullrequest
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/spf13/cobra"
+)
+
+// bulkOptions are the options of the commands that process all the open pullrequests matching filters instead of one pullrequest
+type bulkOptions struct {
+	Filter pullRequestFilter
+	Yes    bool
+}
+
+// BulkResult is the result of processing one pullrequest in bulk
+type BulkResult struct {
+	ID     uint64 `json:"id"`
+	Title  string `json:"title"`
+	Status string `json:"status"` // succeeded, or failed
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkResults is a slice of BulkResult
+type BulkResults []BulkResult
+
+// addFlags adds the filter flags and --yes to the given command, verb starts the usage of the filter flags
+func (options *bulkOptions) addFlags(cmd *cobra.Command, verb string) {
+	options.Filter.addFlags(cmd, verb)
+	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", false, "Do not ask for confirmation before processing the pullrequests matching the filters")
+}
+
+// isBulk tells if the command should process the pullrequests matching the filters
+func (options bulkOptions) isBulk(cmd *cobra.Command) bool {
+	return options.Filter.isSet(cmd)
+}
+
+// getPullRequests gets the open pullrequests matching the filters
+func (options bulkOptions) getPullRequests(ctx context.Context, cmd *cobra.Command, repository *repository.Repository) ([]PullRequest, error) {
+	filter, err := options.Filter.getQuery(ctx, cmd, repository)
+	if err != nil {
+		return nil, err
+	}
+	query := common.BBQL{}
+	query.Equals("state", "OPEN")
+	query = append(query, filter...)
+	logger.Must(logger.FromContext(ctx)).Infof("Selecting pull requests with: %s", query)
+	pullrequests, err := getPullRequestsWithQuery(ctx, cmd, repository, query.String())
+	if err != nil {
+		return nil, errors.Join(errors.Errorf("Failed to get the pullrequests matching the filters"), err)
+	}
+	return pullrequests, nil
+}
+
+// processBulk previews the given pullrequests, asks for confirmation, and runs process on each of them
+//
+// See RunBulk for how the errors are handled.
+// The results are printed at the end, as a table of what succeeded and what failed.
+func (options bulkOptions) processBulk(ctx context.Context, cmd *cobra.Command, currentProfile *profile.Profile, action string, pullrequests []PullRequest, process func(ctx context.Context, pullrequest PullRequest) error) error {
+	if len(pullrequests) == 0 {
+		fmt.Fprintln(os.Stderr, "No open pullrequest matches the filters")
+		return nil
+	}
+
+	WritePreview(os.Stderr, pullrequests)
+	if !common.WhatIf(ctx, cmd, "%s %d pullrequests", action, len(pullrequests)) {
+		return nil
+	}
+	if !options.Yes {
+		confirmed, err := Confirm(cmd.InOrStdin(), os.Stderr, fmt.Sprintf("%s these %d pullrequests?", action, len(pullrequests)))
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Fprintln(os.Stderr, "Cancelled")
+			return nil
+		}
+	}
+
+	results, bulkErr := RunBulk(ctx, cmd, currentProfile, action, pullrequests, process)
+	if err := currentProfile.Print(ctx, cmd, results); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, results.Summary(action))
+	return bulkErr
+}
+
+// RunBulk runs process on each of the given pullrequests and collects the results
+//
+// The errors are handled as configured by the profile or the --stop-on-error, --warn-on-error, and --ignore-errors flags:
+// with stop-on-error, the pullrequests after the first failure are not processed and the error is returned,
+// with warn-on-error, the errors are written to stderr, and with ignore-errors, they are only logged.
+func RunBulk(ctx context.Context, cmd *cobra.Command, currentProfile *profile.Profile, action string, pullrequests []PullRequest, process func(ctx context.Context, pullrequest PullRequest) error) (BulkResults, error) {
+	log := logger.Must(logger.FromContext(ctx)).Child("pullrequest", "bulk", "action", action)
+
+	results := make(BulkResults, 0, len(pullrequests))
+	var merr errors.MultiError
+	for _, pullrequest := range pullrequests {
+		result := BulkResult{ID: pullrequest.ID, Title: pullrequest.Title, Status: "succeeded"}
+		log.Infof("%s pullrequest %d", action, pullrequest.ID)
+		if err := process(ctx, pullrequest); err != nil {
+			log.Errorf("Failed to process pullrequest %d: %s", pullrequest.ID, err)
+			result.Status = "failed"
+			result.Error = err.Error()
+			merr.Append(err)
+		}
+		results = append(results, result)
+		if len(result.Error) > 0 && currentProfile.ShouldStopOnError(cmd) {
+			break
+		}
+	}
+
+	if !merr.IsEmpty() && currentProfile.ShouldWarnOnError(cmd) {
+		fmt.Fprintf(os.Stderr, "Failed to process these pullrequests: %s\n", merr)
+		return results, nil
+	}
+	if currentProfile.ShouldIgnoreErrors(cmd) {
+		log.Warnf("Failed to process these pullrequests, but ignoring errors: %s", merr)
+		return results, nil
+	}
+	return results, merr.AsError()
+}
+
+// WritePreview writes the pullrequests that are about to be processed
+func WritePreview(writer io.Writer, pullrequests []PullRequest) {
+	table := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "ID\tTitle\tAuthor\tBranches\tUpdated")
+	for _, pullrequest := range pullrequests {
+		fmt.Fprintf(table, "%d\t%s\t%s\t%s → %s\t%s\n",
+			pullrequest.ID,
+			pullrequest.Title,
+			pullrequest.Author.Name,
+			pullrequest.Source.Branch.Name,
+			pullrequest.Destination.Branch.Name,
+			pullrequest.UpdatedOn.Local().Format("2006-01-02 15:04"),
+		)
+	}
+	_ = table.Flush()
+}
+
+// Confirm asks a yes/no question, no is the default answer
+func Confirm(reader io.Reader, writer io.Writer, question string) (bool, error) {
+	fmt.Fprintf(writer, "%s [y/N] ", question)
+	answer, err := bufio.NewReader(reader).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, errors.Join(errors.Errorf("Failed to read the answer"), err)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// Summary tells how many pullrequests succeeded and failed
+func (results BulkResults) Summary(action string) string {
+	failed := []string{}
+	for _, result := range results {
+		if result.Status == "failed" {
+			failed = append(failed, "#"+strconv.FormatUint(result.ID, 10))
+		}
+	}
+	summary := fmt.Sprintf("%s: %d succeeded, %d failed", action, len(results)-len(failed), len(failed))
+	if len(failed) > 0 {
+		summary += " (" + strings.Join(failed, ", ") + ")"
+	}
+	return summary
+}
+
+// GetHeaders gets the header for a table
+//
+// implements common.Tableables
+func (results BulkResults) GetHeaders(cmd *cobra.Command) []string {
+	return []string{"ID", "Title", "Status", "Error"}
+}
+
+// GetRowAt gets the row for a table
+//
+// implements common.Tableables
+func (results BulkResults) GetRowAt(index int, headers []string) []string {
+	if index < 0 || index >= len(results) {
+		return []string{}
+	}
+	result := results[index]
+	row := make([]string, 0, len(headers))
+	for _, header := range headers {
+		switch strings.ToLower(header) {
+		case "id":
+			row = append(row, strconv.FormatUint(result.ID, 10))
+		case "title":
+			row = append(row, result.Title)
+		case "status":
+			row = append(row, result.Status)
+		case "error":
+			row = append(row, result.Error)
+		}
+	}
+	return row
+}
+
+// Size gets the number of elements
+//
+// implements common.Tableables
+func (results BulkResults) Size() int {
+	return len(results)
+}
@@ -0,0 +1,143 @@
+package pullrequest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gildas/bitbucket-cli/cmd/branch"
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/gildas/bitbucket-cli/cmd/remote"
+	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/go-git/go-git/v5"
+	"github.com/spf13/cobra"
+)
+
+var checkoutCmd = &cobra.Command{
+	Use:               "checkout [flags] <pullrequest-id>",
+	Aliases:           []string{"co"},
+	Short:             "check out a pullrequest locally by its <pullrequest-id>. If not provided, it will try to check out the only open pullrequest.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest"},
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: approveValidArgs,
+	RunE:              checkoutProcess,
+}
+
+var checkoutOptions struct {
+	Branch string
+	Detach bool
+	Force  bool
+}
+
+func init() {
+	Command.AddCommand(checkoutCmd)
+
+	checkoutCmd.Flags().StringVar(&checkoutOptions.Branch, "branch", "", "Name of the local branch. Default is the source branch, or pr/<pullrequest-id> for pullrequests from forks")
+	checkoutCmd.Flags().BoolVar(&checkoutOptions.Detach, "detach", false, "Check out the source commit in detached HEAD instead of a local branch")
+	checkoutCmd.Flags().BoolVar(&checkoutOptions.Force, "force", false, "Reset the local branch if it diverged and discard local changes")
+	checkoutCmd.MarkFlagsMutuallyExclusive("branch", "detach")
+}
+
+func checkoutProcess(cmd *cobra.Command, args []string) (err error) {
+	log := logger.Must(logger.FromContext(cmd.Context())).Child(cmd.Parent().Name(), "checkout")
+	ctx := log.ToContext(cmd.Context())
+
+	profile, err := profile.GetProfileFromCommand(ctx, cmd)
+	if err != nil {
+		return errors.Join(errors.Errorf("Cannot check out Pull Request"), err)
+	}
+
+	repository, err := repository.GetRepository(ctx, cmd)
+	if err != nil {
+		return errors.Join(errors.Errorf("Cannot check out Pull Request"), err)
+	}
+
+	pullRequestID, err := GetPullRequestIDFromArgs(ctx, cmd, repository, args)
+	if err != nil {
+		return errors.Join(errors.Errorf("Cannot check out Pull Request"), err)
+	}
+
+	var pullrequest PullRequest
+	if err = profile.Get(ctx, cmd, repository.GetPath("pullrequests", pullRequestID), &pullrequest); err != nil {
+		return errors.Join(errors.Errorf("Failed to get pullrequest %s", pullRequestID), err)
+	}
+
+	repo, err := branch.OpenLocalRepository()
+	if err != nil {
+		return err
+	}
+
+	sourceRepositoryName := repository.FullName
+	if pullrequest.Source.Repository != nil && len(pullrequest.Source.Repository.FullName) > 0 {
+		sourceRepositoryName = pullrequest.Source.Repository.FullName
+	}
+	options := branch.CheckoutOptions{
+		Branch:      pullrequest.Source.Branch.Name,
+		LocalBranch: checkoutOptions.Branch,
+		Detach:      checkoutOptions.Detach,
+		Force:       checkoutOptions.Force,
+	}
+	remoteURL := ""
+	if options.RemoteName, remoteURL = findGitRemote(repo, sourceRepositoryName); len(options.RemoteName) == 0 {
+		log.Infof("Pullrequest %s comes from the fork %s", pullRequestID, sourceRepositoryName)
+		remoteURL = getForkURL(ctx, cmd, sourceRepositoryName)
+		options.RemoteURL = remoteURL
+		if len(options.LocalBranch) == 0 {
+			options.LocalBranch = "pr/" + pullRequestID
+		}
+	}
+	if strings.HasPrefix(remoteURL, "https://") {
+		credential, err := profile.GetGitCredential(ctx)
+		if err != nil {
+			return errors.Join(errors.Errorf("Cannot get the git credentials of profile %s", profile.Name), err)
+		}
+		options.Auth = credential.AsHTTPBasicAuth()
+	}
+
+	if !common.WhatIf(ctx, cmd, "Checking out pullrequest %s (branch %s from %s)", pullRequestID, options.Branch, sourceRepositoryName) {
+		return nil
+	}
+	hash, err := branch.Checkout(ctx, repo, options)
+	if err != nil {
+		return errors.Join(errors.Errorf("Failed to check out pullrequest %s", pullRequestID), err)
+	}
+	if options.Detach {
+		fmt.Printf("Checked out pullrequest %s at %s (detached HEAD)\n", pullRequestID, hash.String()[:12])
+	} else {
+		localBranch := options.LocalBranch
+		if len(localBranch) == 0 {
+			localBranch = options.Branch
+		}
+		fmt.Printf("Checked out pullrequest %s in branch %s at %s\n", pullRequestID, localBranch, hash.String()[:12])
+	}
+	return nil
+}
+
+// getForkURL gets the URL to fetch a Bitbucket repository that is not a git remote, like a fork
+//
+// The URL uses https if the current git remote does, ssh otherwise
+func getForkURL(ctx context.Context, cmd *cobra.Command, repositoryName string) string {
+	if gitRemote, err := remote.GetRemote(ctx, cmd); err == nil && strings.HasPrefix(gitRemote.URL, "https://") {
+		return "https://bitbucket.org/" + repositoryName + ".git"
+	}
+	return "git@bitbucket.org:" + repositoryName + ".git"
+}
+
+// findGitRemote finds the configured git remote of the given Bitbucket repository
+func findGitRemote(repo *git.Repository, repositoryName string) (name string, url string) {
+	remotes, err := repo.Remotes()
+	if err != nil {
+		return "", ""
+	}
+	for _, gitRemote := range remotes {
+		for _, url := range gitRemote.Config().URLs {
+			if strings.EqualFold((remote.Remote{URL: url}).RepositoryName(), repositoryName) {
+				return gitRemote.Config().Name, url
+			}
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,284 @@
+package pullrequest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gildas/bitbucket-cli/cmd/commit"
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/bitbucket-cli/cmd/pipeline"
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	prcommon "github.com/gildas/bitbucket-cli/cmd/pullrequest/common"
+	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/gildas/go-core"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-flags"
+	"github.com/gildas/go-logger"
+	"github.com/spf13/cobra"
+)
+
+// Check is a build status reported on the source commit of a pullrequest, with its pipeline if it was run by Bitbucket Pipelines
+type Check struct {
+	Status   commit.Status      `json:"status"`
+	Pipeline *pipeline.Pipeline `json:"pipeline,omitempty"`
+}
+
+// Checks is a collection of Check
+type Checks []Check
+
+var checksCmd = &cobra.Command{
+	Use:   "checks [flags] <pullrequest-id>",
+	Short: "show the builds of a pull request by its <pullrequest-id>. If not provided, it will try to show the builds of the only open pullrequest.",
+	Long: `Show the builds (commit statuses and pipelines) of the source commit of a pull request.
+
+The command exits with 2 if a build failed, like "bb pullrequest merge --when-ready", the builds that are still running do not change the exit code.
+With --watch, the command waits for all the builds to finish and exits with 3 if they do not finish in time.`,
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest"},
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: checksValidArgs,
+	RunE:              checksProcess,
+}
+
+var checksOptions struct {
+	Watch    bool
+	Interval time.Duration
+	Timeout  time.Duration
+	Columns  *flags.EnumSliceFlag
+}
+
+var checksColumns = []string{"name", "state", "duration", "url", "key", "description", "pipeline", "created_on", "updated_on"}
+
+func init() {
+	Command.AddCommand(checksCmd)
+
+	checksOptions.Columns = flags.NewEnumSliceFlag(checksColumns...)
+	checksCmd.Flags().BoolVar(&checksOptions.Watch, "watch", false, "Refresh the builds until they all finish")
+	checksCmd.Flags().DurationVar(&checksOptions.Interval, "interval", 10*time.Second, "Interval between two refreshes with --watch")
+	checksCmd.Flags().DurationVar(&checksOptions.Timeout, "timeout", 1*time.Hour, "Maximum time to wait for the builds with --watch")
+	checksCmd.Flags().Var(checksOptions.Columns, "columns", "Comma-separated list of columns to display")
+	_ = checksCmd.RegisterFlagCompletionFunc(checksOptions.Columns.CompletionFunc("columns"))
+}
+
+func checksValidArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	ids, err := prcommon.GetPullRequestIDsWithState(cmd.Context(), cmd, "OPEN")
+	if err != nil {
+		cobra.CompErrorln(err.Error())
+		return []string{}, cobra.ShellCompDirectiveError
+	}
+	return common.FilterValidArgs(ids, args, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func checksProcess(cmd *cobra.Command, args []string) (err error) {
+	log := logger.Must(logger.FromContext(cmd.Context())).Child(cmd.Parent().Name(), "checks")
+
+	profile, err := profile.GetProfileFromCommand(cmd.Context(), cmd)
+	if err != nil {
+		return errors.Join(errors.Errorf("Failed to get the profile"), err)
+	}
+
+	repository, err := repository.GetRepository(cmd.Context(), cmd)
+	if err != nil {
+		return errors.Join(errors.Errorf("Cannot show the builds of the Pull Request"), err)
+	}
+
+	pullRequestID, err := GetPullRequestIDFromArgs(cmd.Context(), cmd, repository, args)
+	if err != nil {
+		return errors.Join(errors.Errorf("Cannot show the builds of the Pull Request"), err)
+	}
+
+	log.Infof("Showing the builds of pullrequest %s", pullRequestID)
+	if !common.WhatIf(log.ToContext(cmd.Context()), cmd, "Showing the builds of pullrequest %s", pullRequestID) {
+		return nil
+	}
+
+	deadline := time.Now().Add(checksOptions.Timeout)
+	last := ""
+	for {
+		checks, err := GetChecks(log.ToContext(cmd.Context()), cmd, profile, repository, pullRequestID)
+		if err != nil {
+			return err
+		}
+		if !checksOptions.Watch || checks.IsFinished() {
+			if err := profile.Print(cmd.Context(), cmd, checks); err != nil {
+				return err
+			}
+			if checks.State() == commit.StatusFailed {
+				return common.NewExitCodeError(common.ExitBlocked, errors.Errorf("Some builds of pullrequest %s failed", pullRequestID))
+			}
+			return nil
+		}
+		if summary := checks.String(); summary != last {
+			fmt.Fprintf(os.Stderr, "Pullrequest %s: %s\n", pullRequestID, summary)
+			last = summary
+		}
+		if time.Now().Add(checksOptions.Interval).After(deadline) {
+			_ = profile.Print(cmd.Context(), cmd, checks)
+			return common.NewExitCodeError(common.ExitTimedOut, errors.Errorf("The builds of pullrequest %s did not finish after %s", pullRequestID, checksOptions.Timeout))
+		}
+		select {
+		case <-cmd.Context().Done():
+			return cmd.Context().Err()
+		case <-time.After(checksOptions.Interval):
+		}
+	}
+}
+
+// GetChecks gets the builds reported on the source commit of a pullrequest
+//
+// The builds run by Bitbucket Pipelines come with their pipeline
+func GetChecks(ctx context.Context, cmd *cobra.Command, profile *profile.Profile, repository *repository.Repository, pullRequestID string) (Checks, error) {
+	log := logger.Must(logger.FromContext(ctx)).Child("pullrequest", "checks")
+	var pullrequest PullRequest
+
+	if err := profile.Get(ctx, cmd, repository.GetPath("pullrequests", pullRequestID), &pullrequest); err != nil {
+		return nil, errors.Join(errors.Errorf("Failed to get pullrequest %s", pullRequestID), err)
+	}
+	if pullrequest.Source.Commit == nil {
+		return Checks{}, nil
+	}
+	statuses, err := commit.GetStatuses(ctx, cmd, repository, pullrequest.Source.Commit.Hash)
+	if err != nil {
+		return nil, errors.Join(errors.Errorf("Failed to get the builds of pullrequest %s", pullRequestID), err)
+	}
+	checks := make(Checks, 0, len(statuses))
+	for _, status := range statuses {
+		check := Check{Status: status}
+		if buildNumber, found := pipeline.GetBuildNumberFromURL(status.URL); found {
+			if check.Pipeline, err = pipeline.GetPipeline(ctx, cmd, repository, strconv.FormatUint(buildNumber, 10)); err != nil {
+				log.Warnf("Failed to get pipeline %d: %s", buildNumber, err)
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// GetDuration gets the duration of the check, from its pipeline if any
+func (check Check) GetDuration() time.Duration {
+	if check.Pipeline != nil && check.Pipeline.Duration > 0 {
+		return check.Pipeline.Duration
+	}
+	return check.Status.GetDuration()
+}
+
+// GetURL gets the URL of the check
+func (check Check) GetURL() string {
+	if len(check.Status.URL) > 0 {
+		return check.Status.URL
+	}
+	if check.Pipeline != nil {
+		if link := check.Pipeline.GetHTMLLink(); link != nil {
+			return link.HREF.String()
+		}
+	}
+	return ""
+}
+
+// GetHeaders gets the header for a table
+//
+// implements common.Tableable
+func (check Check) GetHeaders(cmd *cobra.Command) []string {
+	if cmd != nil && cmd.Flag("columns") != nil && cmd.Flag("columns").Changed {
+		if columns, err := cmd.Flags().GetStringSlice("columns"); err == nil {
+			return core.Map(columns, func(column string) string { return strings.ReplaceAll(column, "_", " ") })
+		}
+	}
+	return []string{"Name", "State", "Duration", "URL"}
+}
+
+// GetRow gets the row for a table
+//
+// implements common.Tableable
+func (check Check) GetRow(headers []string) []string {
+	var row []string
+
+	for _, header := range headers {
+		switch strings.ToLower(header) {
+		case "name":
+			if len(check.Status.Name) > 0 {
+				row = append(row, check.Status.Name)
+			} else {
+				row = append(row, check.Status.Key)
+			}
+		case "state":
+			row = append(row, check.Status.State)
+		case "duration":
+			row = append(row, check.GetDuration().String())
+		case "url":
+			row = append(row, check.GetURL())
+		case "pipeline":
+			if check.Pipeline != nil {
+				row = append(row, strconv.FormatUint(check.Pipeline.BuildNumber, 10))
+			} else {
+				row = append(row, "")
+			}
+		default:
+			row = append(row, check.Status.GetRow([]string{header})...)
+		}
+	}
+	return row
+}
+
+// IsFinished tells if all the checks are finished
+func (checks Checks) IsFinished() bool {
+	return checks.State() != commit.StatusInProgress
+}
+
+// State gets the overall state of the checks
+//
+// See commit.Statuses.State
+func (checks Checks) State() string {
+	return commit.Statuses(core.Map(checks, func(check Check) commit.Status { return check.Status })).State()
+}
+
+// String gets a summary of the checks
+//
+// implements fmt.Stringer
+func (checks Checks) String() string {
+	if len(checks) == 0 {
+		return "no builds"
+	}
+	counts := map[string]int{}
+	for _, check := range checks {
+		counts[check.Status.State]++
+	}
+	var summary []string
+	for _, state := range []string{commit.StatusSuccessful, commit.StatusInProgress, commit.StatusFailed, commit.StatusStopped} {
+		if count := counts[state]; count > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s", count, strings.ToLower(state)))
+		}
+	}
+	return strings.Join(summary, ", ")
+}
+
+// GetHeaders gets the header for a table
+//
+// implements common.Tableables
+func (checks Checks) GetHeaders(cmd *cobra.Command) []string {
+	return Check{}.GetHeaders(cmd)
+}
+
+// GetRowAt gets the row for a table
+//
+// implements common.Tableables
+func (checks Checks) GetRowAt(index int, headers []string) []string {
+	if index < 0 || index >= len(checks) {
+		return []string{}
+	}
+	return checks[index].GetRow(headers)
+}
+
+// Size gets the number of elements
+//
+// implements common.Tableables
+func (checks Checks) Size() int {
+	return len(checks)
+}
@@ -0,0 +1,349 @@
+package pullrequest
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/gildas/bitbucket-cli/cmd/user"
+	"github.com/gildas/bitbucket-cli/cmd/workspace"
+	"github.com/gildas/go-core"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/spf13/cobra"
+)
+
+// CodeOwnersLocations are the paths of the CODEOWNERS file in a repository, in the order they are searched
+var CodeOwnersLocations = []string{"CODEOWNERS", ".bitbucket/CODEOWNERS", ".github/CODEOWNERS", "docs/CODEOWNERS"}
+
+// CodeOwnersFile is a parsed CODEOWNERS file
+type CodeOwnersFile struct {
+	Filename string           `json:"filename"`
+	Rules    []CodeOwnersRule `json:"rules"`
+}
+
+// CodeOwnersRule is a line of a CODEOWNERS file: the files matching the pattern are owned by the owners
+//
+// The patterns use the .gitignore syntax
+type CodeOwnersRule struct {
+	Pattern string   `json:"pattern"`
+	Owners  []string `json:"owners"`
+	Line    int      `json:"line"`
+	matcher gitignore.Pattern
+}
+
+// CodeOwner is an owner of files changed by a pullrequest
+type CodeOwner struct {
+	Owner      string      `json:"owner"` // as written in the CODEOWNERS file
+	Type       string      `json:"type"`  // user, group, or unknown
+	Users      []user.User `json:"users,omitempty"`
+	Files      []string    `json:"files"`
+	ApprovedBy []string    `json:"approved_by"`
+}
+
+// CodeOwners is a slice of CodeOwner
+type CodeOwners []CodeOwner
+
+// ParseCodeOwners parses a CODEOWNERS file
+//
+// Each line is a pattern followed by its owners: @nickname, @@group or @workspace/group, or an email.
+// Empty lines, comments, and sections ([Section]) are ignored.
+func ParseCodeOwners(reader io.Reader) (*CodeOwnersFile, error) {
+	file := &CodeOwnersFile{Rules: []CodeOwnersRule{}}
+	scanner := bufio.NewScanner(reader)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if index := strings.Index(line, " #"); index >= 0 {
+			line = strings.TrimSpace(line[:index])
+		}
+		if len(line) == 0 || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") {
+			continue
+		}
+		fields := strings.Fields(line)
+		pattern := strings.ReplaceAll(fields[0], `\#`, "#")
+		file.Rules = append(file.Rules, CodeOwnersRule{
+			Pattern: pattern,
+			Owners:  fields[1:],
+			Line:    number,
+			matcher: gitignore.ParsePattern(pattern, nil),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.RuntimeError.Wrap(err)
+	}
+	return file, nil
+}
+
+// GetOwners gets the owners of the file at the given path
+//
+// Like git, the last matching rule wins. A rule without owners leaves the file without owners
+func (file CodeOwnersFile) GetOwners(path string) []string {
+	components := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for index := len(file.Rules) - 1; index >= 0; index-- {
+		if file.Rules[index].matcher.Match(components, false) == gitignore.Exclude {
+			return file.Rules[index].Owners
+		}
+	}
+	return []string{}
+}
+
+// GetCodeOwners gets the owners of the given files, in the order they appear
+func (file CodeOwnersFile) GetCodeOwners(paths []string) CodeOwners {
+	owners := CodeOwners{}
+	for _, path := range paths {
+		for _, name := range file.GetOwners(path) {
+			index := slices.IndexFunc(owners, func(owner CodeOwner) bool { return strings.EqualFold(owner.Owner, name) })
+			if index < 0 {
+				owners = append(owners, CodeOwner{Owner: name, Type: "unknown", Files: []string{}, ApprovedBy: []string{}})
+				index = len(owners) - 1
+			}
+			owners[index].Files = append(owners[index].Files, path)
+		}
+	}
+	return owners
+}
+
+// GetCodeOwnersFile gets the CODEOWNERS file of the repository at the given revision (a commit or a branch)
+//
+// Returns an errors.NotFound error if the repository has no CODEOWNERS file, any other error from Bitbucket is returned as is
+func GetCodeOwnersFile(ctx context.Context, cmd *cobra.Command, currentProfile *profile.Profile, repository *repository.Repository, revision string) (*CodeOwnersFile, error) {
+	log := logger.Must(logger.FromContext(ctx)).Child("pullrequest", "codeowners", "revision", revision)
+
+	for _, location := range CodeOwnersLocations {
+		raw, err := currentProfile.GetRaw(ctx, cmd, repository.GetPath("src", revision, location))
+		if errors.Is(err, errors.HTTPNotFound) {
+			log.Debugf("No CODEOWNERS file at %s", location)
+			continue
+		} else if err != nil {
+			return nil, errors.Join(errors.Errorf("Failed to get the CODEOWNERS file %s", location), err)
+		}
+		file, err := ParseCodeOwners(raw)
+		if err != nil {
+			return nil, errors.Join(errors.Errorf("Invalid CODEOWNERS file %s", location), err)
+		}
+		file.Filename = location
+		log.Infof("Found the CODEOWNERS file %s with %d rules", location, len(file.Rules))
+		return file, nil
+	}
+	return nil, errors.NotFound.With("file", "CODEOWNERS")
+}
+
+// getCodeOwners gets the owners of the files changed by the pullrequest, from the CODEOWNERS file of its destination branch
+//
+// The owners are resolved to users with the workspace members.
+// As the Bitbucket API does not give the groups of a workspace, the groups must be declared in the reviewer groups of the .bb.yml file
+func getCodeOwners(ctx context.Context, cmd *cobra.Command, currentProfile *profile.Profile, repository *repository.Repository, pullrequest PullRequest) (CodeOwners, error) {
+	log := logger.Must(logger.FromContext(ctx)).Child("pullrequest", "codeowners", "pullrequest", pullrequest.ID)
+
+	revision := pullrequest.Destination.Branch.Name
+	if pullrequest.Destination.Commit != nil && len(pullrequest.Destination.Commit.Hash) > 0 {
+		revision = pullrequest.Destination.Commit.Hash
+	}
+	file, err := GetCodeOwnersFile(ctx, cmd, currentProfile, repository, revision)
+	if err != nil {
+		return nil, err
+	}
+	diffstats, err := GetDiffStats(ctx, cmd, repository, pullrequest.ID)
+	if err != nil {
+		return nil, errors.Join(errors.Errorf("Failed to get the diffstat of pullrequest %d", pullrequest.ID), err)
+	}
+	owners := file.GetCodeOwners(core.Map(diffstats, func(diffstat DiffStat) string { return diffstat.Path() }))
+
+	var members []workspace.Member
+	if ownersWorkspace, err := repository.GetWorkspace(ctx, cmd); err == nil {
+		members, _ = ownersWorkspace.GetMembers(ctx, cmd)
+	}
+	config, _ := common.LoadRepositoryConfig(ctx)
+	approvers := []user.User{}
+	for _, participant := range pullrequest.Participants {
+		if participant.Approved {
+			approvers = append(approvers, participant.User)
+		}
+	}
+
+	for index := range owners {
+		owner := &owners[index]
+		var ids []string
+		switch name := owner.Owner; {
+		case strings.HasPrefix(name, "@@"), strings.HasPrefix(name, "@") && strings.Contains(name, "/"):
+			group := name[strings.LastIndexAny(name, "@/")+1:]
+			if config == nil {
+				log.Warnf("Cannot resolve the group %s, it must be declared in the reviewer_groups of a %s file", group, common.RepositoryConfigFilename)
+				continue
+			}
+			if ids, err = config.GetReviewerGroup(group); err != nil {
+				log.Warnf("Cannot resolve the group %s, it must be declared in the reviewer_groups of %s", group, config.Filename)
+				continue
+			}
+			owner.Type = "group"
+		case strings.HasPrefix(name, "@"):
+			ids = []string{strings.TrimPrefix(name, "@")}
+			owner.Type = "user"
+		default:
+			log.Warnf("Cannot resolve the owner %s, Bitbucket does not find users by email", name)
+			continue
+		}
+		for _, id := range ids {
+			found, err := FindUser(ctx, cmd, members, id)
+			if err != nil {
+				log.Warnf("Owner %s is not a member of the workspace", id)
+				continue
+			}
+			owner.Users = append(owner.Users, *found)
+			if slices.ContainsFunc(approvers, func(approver user.User) bool { return approver.ID == found.ID }) {
+				owner.ApprovedBy = append(owner.ApprovedBy, found.Name)
+			}
+		}
+		if len(owner.Users) == 0 {
+			owner.Type = "unknown"
+		}
+	}
+	return owners, nil
+}
+
+// applyCodeOwners suggests or adds the code owners of the files changed by the pullrequest as reviewers
+//
+// mode is suggest, add, or none. Returns true if reviewers were added to the pullrequest.
+// The code owners should not prevent creating or updating a pullrequest, so failures are only warnings
+func applyCodeOwners(ctx context.Context, cmd *cobra.Command, currentProfile *profile.Profile, repository *repository.Repository, pullrequest *PullRequest, mode string) bool {
+	log := logger.Must(logger.FromContext(ctx)).Child("pullrequest", "codeowners", "pullrequest", pullrequest.ID, "mode", mode)
+
+	if mode == "none" {
+		return false
+	}
+	owners, err := getCodeOwners(ctx, cmd, currentProfile, repository, *pullrequest)
+	if errors.Is(err, errors.NotFound) {
+		fmt.Fprintln(os.Stderr, "The destination branch has no CODEOWNERS file, no code owner to review the pullrequest")
+		return false
+	} else if err != nil {
+		log.Warnf("Failed to process the code owners of pullrequest %d: %s", pullrequest.ID, err)
+		fmt.Fprintf(os.Stderr, "Warning: Failed to process the code owners of pullrequest %d: %s\n", pullrequest.ID, err)
+		return false
+	}
+
+	missing := owners.GetUsers(func(candidate user.User) bool {
+		return candidate.ID != pullrequest.Author.ID && !slices.ContainsFunc(pullrequest.Reviewers, func(reviewer user.User) bool { return reviewer.ID == candidate.ID })
+	})
+	if len(missing) == 0 {
+		log.Infof("All the code owners are reviewers")
+		return false
+	}
+	names := core.Map(missing, func(owner user.User) string { return owner.Name })
+	if mode == "suggest" {
+		nicknames := core.Map(missing, func(owner user.User) string { return owner.Nickname })
+		fmt.Fprintf(os.Stderr, "Code owners who are not reviewers: %s\n", strings.Join(names, ", "))
+		fmt.Fprintf(os.Stderr, "Add them with: bb pullrequest update %d --add-reviewer %s\n", pullrequest.ID, strings.Join(nicknames, ","))
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "Adding the code owners as reviewers: %s\n", strings.Join(names, ", "))
+	pullrequest.Reviewers = append(pullrequest.Reviewers, missing...)
+	return true
+}
+
+// CodeOwnersModes are the values of the --code-owners flag and of the code_owners setting of the .bb.yml file
+var CodeOwnersModes = []string{"none", "suggest", "add"}
+
+// GetCodeOwnersMode gets the mode of the --code-owners flag, or of the code_owners setting of the .bb.yml file if the flag is not given
+//
+// Returns an errors.ArgumentInvalid error if the code_owners setting is not one of CodeOwnersModes
+func GetCodeOwnersMode(ctx context.Context, cmd *cobra.Command) (string, error) {
+	if flag := cmd.Flag("code-owners"); flag != nil && flag.Changed {
+		return flag.Value.String(), nil
+	}
+	if config, err := common.LoadRepositoryConfig(ctx); err == nil && len(config.CodeOwners) > 0 {
+		if !slices.Contains(CodeOwnersModes, config.CodeOwners) {
+			return "", errors.Join(errors.Errorf("Invalid code_owners in %s, it should be one of %s", config.Filename, strings.Join(CodeOwnersModes, ", ")), errors.ArgumentInvalid.With("code_owners", config.CodeOwners))
+		}
+		return config.CodeOwners, nil
+	}
+	return "none", nil
+}
+
+// GetUsers gets the users of the code owners matching the filter, without duplicates
+func (owners CodeOwners) GetUsers(filter func(user.User) bool) []user.User {
+	users := []user.User{}
+	for _, owner := range owners {
+		for _, candidate := range owner.Users {
+			if filter(candidate) && !slices.ContainsFunc(users, func(found user.User) bool { return found.ID == candidate.ID }) {
+				users = append(users, candidate)
+			}
+		}
+	}
+	return users
+}
+
+// IsApproved tells if the owner approved the pullrequest, for a group one of its members is enough
+func (owner CodeOwner) IsApproved() bool {
+	return len(owner.ApprovedBy) > 0
+}
+
+// IsResolved tells if the owner was resolved to workspace members
+//
+// Owners given by email, groups missing from the .bb.yml file, and users who are not members of the workspace are not resolved, they can never approve
+func (owner CodeOwner) IsResolved() bool {
+	return len(owner.Users) > 0
+}
+
+// GetNotApproved gets the resolved owners who have not approved the pullrequest
+func (owners CodeOwners) GetNotApproved() CodeOwners {
+	return core.Filter(owners, func(owner CodeOwner) bool { return owner.IsResolved() && !owner.IsApproved() })
+}
+
+// GetUnresolved gets the owners that could not be resolved to workspace members
+func (owners CodeOwners) GetUnresolved() CodeOwners {
+	return core.Filter(owners, func(owner CodeOwner) bool { return !owner.IsResolved() })
+}
+
+// GetHeaders gets the header for a table
+//
+// implements common.Tableables
+func (owners CodeOwners) GetHeaders(cmd *cobra.Command) []string {
+	return []string{"Owner", "Type", "Approved", "Approved By", "Files"}
+}
+
+// GetRowAt gets the row for a table
+//
+// implements common.Tableables
+func (owners CodeOwners) GetRowAt(index int, headers []string) []string {
+	if index < 0 || index >= len(owners) {
+		return []string{}
+	}
+	owner := owners[index]
+	row := make([]string, 0, len(headers))
+	for _, header := range headers {
+		switch strings.ToLower(header) {
+		case "owner":
+			row = append(row, owner.Owner)
+		case "type":
+			row = append(row, owner.Type)
+		case "approved":
+			if owner.IsApproved() {
+				row = append(row, "yes")
+			} else if !owner.IsResolved() {
+				row = append(row, "unresolved")
+			} else {
+				row = append(row, "no")
+			}
+		case "approved by":
+			row = append(row, strings.Join(owner.ApprovedBy, ", "))
+		case "files":
+			row = append(row, fmt.Sprintf("%d", len(owner.Files)))
+		}
+	}
+	return row
+}
+
+// Size gets the number of elements
+//
+// implements common.Tableables
+func (owners CodeOwners) Size() int {
+	return len(owners)
+}
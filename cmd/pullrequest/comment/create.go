@@ -23,11 +23,12 @@ type ContentCreator struct {
 }
 
 var createCmd = &cobra.Command{
-	Use:     "create",
-	Aliases: []string{"add", "new"},
-	Short:   "create a pullrequest comment",
-	Args:    cobra.NoArgs,
-	RunE:    createProcess,
+	Use:         "create",
+	Aliases:     []string{"add", "new"},
+	Short:       "create a pullrequest comment",
+	Annotations: map[string]string{profile.ScopesAnnotation: "pullrequest:write"},
+	Args:        cobra.NoArgs,
+	RunE:        createProcess,
 }
 
 var createOptions struct {
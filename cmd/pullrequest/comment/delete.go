@@ -18,6 +18,7 @@ var deleteCmd = &cobra.Command{
 	Use:               "delete [flags] <comment-id...>",
 	Aliases:           []string{"remove", "rm"},
 	Short:             "delete pullrequest comments by their <comment-id>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest:write"},
 	Args:              cobra.MinimumNArgs(1),
 	ValidArgsFunction: deleteValidArgs,
 	RunE:              deleteProcess,
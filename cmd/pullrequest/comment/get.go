@@ -17,6 +17,7 @@ var getCmd = &cobra.Command{
 	Use:               "get [flags] <comment-id>",
 	Aliases:           []string{"show", "info", "display"},
 	Short:             "get a pullrequest comment by its <comment-id>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: getValidArgs,
 	RunE:              getProcess,
@@ -14,6 +14,7 @@ import (
 var reopenCmd = &cobra.Command{
 	Use:               "reopen [flags] <comment-id>",
 	Short:             "reopen a pullrequest comment by its <comment-id>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest:write"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: reopenValidArgs,
 	RunE:              reopenProcess,
@@ -14,6 +14,7 @@ import (
 var resolveCmd = &cobra.Command{
 	Use:               "resolve [flags] <comment-id>",
 	Short:             "resolve a pullrequest comment by its <comment-id>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest:write"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: resolveValidArgs,
 	RunE:              resolveProcess,
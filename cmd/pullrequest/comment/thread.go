@@ -0,0 +1,218 @@
+package comment
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/go-core"
+	"github.com/spf13/cobra"
+)
+
+// Thread is a comment with its replies
+type Thread struct {
+	Comment Comment  `json:"comment"`
+	Replies []Thread `json:"replies,omitempty"`
+}
+
+// Threads is a collection of Thread
+type Threads []Thread
+
+// Threads builds the conversation trees of the comments, the oldest first
+//
+// A reply whose parent is not among the comments starts its own thread
+func (comments Comments) Threads() Threads {
+	sorted := make(Comments, len(comments))
+	copy(sorted, comments)
+	core.Sort(sorted, func(a, b Comment) bool { return a.CreatedOn.Before(b.CreatedOn) })
+
+	known := map[int]bool{}
+	children := map[int][]Comment{}
+	for _, comment := range sorted {
+		known[comment.ID] = true
+	}
+	roots := Comments{}
+	for _, comment := range sorted {
+		if comment.Parent != nil && known[comment.Parent.ID] {
+			children[comment.Parent.ID] = append(children[comment.Parent.ID], comment)
+		} else {
+			roots = append(roots, comment)
+		}
+	}
+
+	var build func(comment Comment) Thread
+	build = func(comment Comment) Thread {
+		thread := Thread{Comment: comment}
+		for _, child := range children[comment.ID] {
+			thread.Replies = append(thread.Replies, build(child))
+		}
+		return thread
+	}
+	threads := make(Threads, 0, len(roots))
+	for _, root := range roots {
+		threads = append(threads, build(root))
+	}
+	return threads
+}
+
+// IsResolved tells if the thread was resolved
+func (thread Thread) IsResolved() bool {
+	return thread.Comment.Resolution != nil
+}
+
+// IsPending tells if a comment of the thread is pending, i.e. not published yet
+func (thread Thread) IsPending() bool {
+	if thread.Comment.IsPending {
+		return true
+	}
+	for _, reply := range thread.Replies {
+		if reply.IsPending() {
+			return true
+		}
+	}
+	return false
+}
+
+// IsInline tells if the thread is about a line of code
+func (thread Thread) IsInline() bool {
+	return thread.Comment.Anchor != nil
+}
+
+// Size gets the number of comments of the thread, its first comment included
+func (thread Thread) Size() int {
+	size := 1
+	for _, reply := range thread.Replies {
+		size += reply.Size()
+	}
+	return size
+}
+
+// Status gets the status of the thread: resolved, pending, or open
+func (thread Thread) Status() string {
+	switch {
+	case thread.IsResolved():
+		return "resolved"
+	case thread.IsPending():
+		return "pending"
+	default:
+		return "open"
+	}
+}
+
+// Unresolved gets the threads that are not resolved
+func (threads Threads) Unresolved() Threads {
+	return core.Filter(threads, func(thread Thread) bool { return !thread.IsResolved() })
+}
+
+// GetHeaders gets the header for a table
+//
+// implements common.Tableables
+func (threads Threads) GetHeaders(cmd *cobra.Command) []string {
+	return []string{"ID", "File", "Status", "User", "Replies", "Content"}
+}
+
+// GetRowAt gets the row for a table
+//
+// implements common.Tableables
+func (threads Threads) GetRowAt(index int, headers []string) []string {
+	if index < 0 || index >= len(threads) {
+		return []string{}
+	}
+	thread := threads[index]
+	var row []string
+
+	for _, header := range headers {
+		switch strings.ToLower(header) {
+		case "status":
+			row = append(row, thread.Status())
+		case "replies":
+			row = append(row, fmt.Sprintf("%d", thread.Size()-1))
+		default:
+			row = append(row, thread.Comment.GetRow([]string{header})...)
+		}
+	}
+	return row
+}
+
+// Size gets the number of elements
+//
+// implements common.Tableables
+func (threads Threads) Size() int {
+	return len(threads)
+}
+
+// ThreadRenderOptions tells how to render threads
+type ThreadRenderOptions struct {
+	Color   bool // Colorize the output
+	Context int  // Number of lines of code to show around inline comments
+}
+
+// Render writes the threads as a conversation, with the code around the inline comments found in the given diff
+func (threads Threads) Render(writer io.Writer, diff common.Diff, options ThreadRenderOptions) error {
+	colorize := func(color common.Color, text string) string {
+		if options.Color {
+			return common.Colorize(color, text)
+		}
+		return text
+	}
+	var builder strings.Builder
+
+	for index, thread := range threads {
+		if index > 0 {
+			builder.WriteString("\n")
+		}
+		title := "General comment"
+		if thread.IsInline() {
+			title = thread.Comment.Anchor.String()
+		}
+		status := thread.Status()
+		switch status {
+		case "resolved":
+			status = colorize(common.ColorGreen, status)
+		case "pending":
+			status = colorize(common.ColorGray, status)
+		default:
+			status = colorize(common.ColorYellow, status)
+		}
+		builder.WriteString(fmt.Sprintf("%s %s [%s]\n", colorize(common.ColorBold, "●"), colorize(common.ColorBold, title), status))
+		if thread.IsInline() {
+			if file, found := diff.FindFile(thread.Comment.Anchor.Path); found {
+				if lines, target := file.AnchorContext(*thread.Comment.Anchor, options.Context); len(lines) > 0 {
+					_ = common.RenderContext(&builder, lines, target, options.Color)
+				}
+			}
+		}
+		thread.render(&builder, 1, colorize, options)
+		if thread.IsResolved() {
+			builder.WriteString("  " + colorize(common.ColorGreen, thread.Comment.GetRow([]string{"resolution"})[0]) + "\n")
+		}
+	}
+	_, err := io.WriteString(writer, builder.String())
+	return err
+}
+
+// render writes the comments of the thread, the replies are indented under their parent
+func (thread Thread) render(builder *strings.Builder, depth int, colorize func(common.Color, string) string, options ThreadRenderOptions) {
+	indent := strings.Repeat("  ", depth)
+	arrow := ""
+	if depth > 1 {
+		arrow = "↳ "
+	}
+	header := fmt.Sprintf("%s · %s", thread.Comment.User.Name, thread.Comment.CreatedOn.Format("2006-01-02 15:04"))
+	if thread.Comment.IsPending {
+		header += " (pending)"
+	}
+	builder.WriteString(indent + arrow + colorize(common.ColorCyan, header) + "\n")
+
+	content := thread.Comment.Content.Raw
+	if thread.Comment.IsDeleted && len(content) == 0 {
+		content = "(deleted)"
+	}
+	for _, line := range strings.Split(strings.TrimRight(common.RenderMarkdown(content, options.Color), "\n"), "\n") {
+		builder.WriteString(indent + "  " + line + "\n")
+	}
+	for _, reply := range thread.Replies {
+		reply.render(builder, depth+1, colorize, options)
+	}
+}
@@ -16,6 +16,7 @@ import (
 var commitsCmd = &cobra.Command{
 	Use:               "commits [flags] <pullrequest-id>",
 	Short:             "Lists the commits of a pullrequest by its <pullrequest-id>",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest"},
 	Args:              cobra.MaximumNArgs(1),
 	ValidArgsFunction: commitsValidArgs,
 	RunE:              commitsProcess,
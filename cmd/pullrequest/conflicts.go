@@ -0,0 +1,214 @@
+package pullrequest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gildas/bitbucket-cli/cmd/branch"
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	prcommon "github.com/gildas/bitbucket-cli/cmd/pullrequest/common"
+	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-flags"
+	"github.com/gildas/go-logger"
+	"github.com/spf13/cobra"
+)
+
+var conflictsCmd = &cobra.Command{
+	Use:   "conflicts [flags] <pullrequest-id>",
+	Short: "predict the merge conflicts of a pull request by its <pullrequest-id>. If not provided, it will try to check the only open pullrequest.",
+	Long: `Predict the merge conflicts of a pull request by merging its branches locally.
+
+The source and destination branches are fetched in the git repository of the current folder,
+which updates their remote tracking branches like git fetch, and merged in memory with the merge strategy.
+The worktree and the local branches are not touched.
+Each conflicting file is shown with its conflicting hunks.
+
+The command exits with 2 if the pull request cannot be merged without conflicts.`,
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest"},
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: conflictsValidArgs,
+	RunE:              conflictsProcess,
+}
+
+var conflictsOptions struct {
+	MergeStrategy *flags.EnumFlag
+	Color         *flags.EnumFlag
+	NoPager       bool
+}
+
+func init() {
+	Command.AddCommand(conflictsCmd)
+
+	conflictsOptions.MergeStrategy = flags.NewEnumFlag("+merge_commit", "squash", "fast_forward")
+	conflictsOptions.Color = flags.NewEnumFlag("always", "+auto", "never")
+	conflictsCmd.Flags().Var(conflictsOptions.MergeStrategy, "merge-strategy", "Merge strategy to use. Possible values are \"merge_commit\", \"squash\" or \"fast_forward\"")
+	conflictsCmd.Flags().Var(conflictsOptions.Color, "color", "colorize the output: always, auto, or never")
+	conflictsCmd.Flags().BoolVar(&conflictsOptions.NoPager, "no-pager", false, "Do not send the output to a pager")
+	_ = conflictsCmd.RegisterFlagCompletionFunc(conflictsOptions.MergeStrategy.CompletionFunc("merge-strategy"))
+	_ = conflictsCmd.RegisterFlagCompletionFunc(conflictsOptions.Color.CompletionFunc("color"))
+}
+
+func conflictsValidArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	ids, err := prcommon.GetPullRequestIDsWithState(cmd.Context(), cmd, "OPEN")
+	if err != nil {
+		cobra.CompErrorln(err.Error())
+		return []string{}, cobra.ShellCompDirectiveError
+	}
+	return common.FilterValidArgs(ids, args, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func conflictsProcess(cmd *cobra.Command, args []string) error {
+	log := logger.Must(logger.FromContext(cmd.Context())).Child(cmd.Parent().Name(), "conflicts")
+
+	currentProfile, err := profile.GetProfileFromCommand(cmd.Context(), cmd)
+	if err != nil {
+		return err
+	}
+
+	repository, err := repository.GetRepository(cmd.Context(), cmd)
+	if err != nil {
+		return errors.Join(errors.Errorf("Cannot predict the conflicts of the Pull Request"), err)
+	}
+
+	pullRequestID, err := GetPullRequestIDFromArgs(cmd.Context(), cmd, repository, args)
+	if err != nil {
+		return errors.Join(errors.Errorf("Cannot predict the conflicts of the Pull Request"), err)
+	}
+
+	log.Infof("Predicting the conflicts of pullrequest %s", pullRequestID)
+	if !common.WhatIf(log.ToContext(cmd.Context()), cmd, "Predicting the conflicts of pullrequest %s", pullRequestID) {
+		return nil
+	}
+
+	var pullrequest PullRequest
+	if err = currentProfile.Get(log.ToContext(cmd.Context()), cmd, repository.GetPath("pullrequests", pullRequestID), &pullrequest); err != nil {
+		return errors.Join(errors.Errorf("Failed to get pullrequest %s", pullRequestID), err)
+	}
+
+	result, err := predictConflicts(log.ToContext(cmd.Context()), cmd, currentProfile, repository, pullrequest, conflictsOptions.MergeStrategy.String())
+	if err != nil {
+		return errors.Join(errors.Errorf("Failed to predict the conflicts of pullrequest %s", pullRequestID), err)
+	}
+
+	if getOutputFormat(cmd, currentProfile) != "table" {
+		err = currentProfile.Print(cmd.Context(), cmd, result)
+	} else {
+		var builder strings.Builder
+		writeConflicts(&builder, pullrequest, *result, common.UseColor(conflictsOptions.Color.String()))
+		if conflictsOptions.NoPager {
+			_, err = io.WriteString(os.Stdout, builder.String())
+		} else {
+			err = common.Page(log.ToContext(cmd.Context()), builder.String())
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if !result.IsClean() {
+		return common.NewExitCodeError(common.ExitBlocked, errors.Errorf("Pullrequest %s cannot be merged without conflicts", pullRequestID))
+	}
+	return nil
+}
+
+// predictConflicts fetches the branches of the pullrequest in the local git repository and merges them in memory
+//
+// The destination branch is fetched from the git remote of the repository, which updates its remote tracking branch,
+// the source branch from the same remote or from its fork
+func predictConflicts(ctx context.Context, cmd *cobra.Command, currentProfile *profile.Profile, repository *repository.Repository, pullrequest PullRequest, strategy string) (*branch.TrialMerge, error) {
+	log := logger.Must(logger.FromContext(ctx)).Child("pullrequest", "predict_conflicts", "pullrequest", pullrequest.ID)
+
+	repo, err := branch.OpenLocalRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	destinationRemote, destinationURL := findGitRemote(repo, repository.FullName)
+	if len(destinationRemote) == 0 {
+		return nil, errors.NotFound.With("git remote", repository.FullName)
+	}
+	auth, err := getGitAuth(ctx, currentProfile, destinationURL)
+	if err != nil {
+		return nil, err
+	}
+	destination, err := branch.FetchBranch(ctx, repo, destinationRemote, "", pullrequest.Destination.Branch.Name, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceRepositoryName := repository.FullName
+	if pullrequest.Source.Repository != nil && len(pullrequest.Source.Repository.FullName) > 0 {
+		sourceRepositoryName = pullrequest.Source.Repository.FullName
+	}
+	sourceRemote, sourceURL := findGitRemote(repo, sourceRepositoryName)
+	if len(sourceRemote) == 0 {
+		log.Infof("Pullrequest %d comes from the fork %s", pullrequest.ID, sourceRepositoryName)
+		sourceURL = getForkURL(ctx, cmd, sourceRepositoryName)
+	}
+	if sourceURL != destinationURL {
+		if auth, err = getGitAuth(ctx, currentProfile, sourceURL); err != nil {
+			return nil, err
+		}
+	}
+	source, err := branch.FetchBranch(ctx, repo, sourceRemote, sourceURL, pullrequest.Source.Branch.Name, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Merging %s (%s) into %s (%s) with %s", pullrequest.Source.Branch.Name, source, pullrequest.Destination.Branch.Name, destination, strategy)
+	return branch.PredictMerge(repo, destination, source, strategy)
+}
+
+// writeConflicts writes the conflicting files of a trial merge with their hunks, with conflict markers like git
+func writeConflicts(writer io.Writer, pullrequest PullRequest, result branch.TrialMerge, useColor bool) {
+	colorize := func(_ common.Color, text string) string { return text }
+	if useColor {
+		colorize = common.Colorize
+	}
+	sourceName := pullrequest.Source.Branch.Name
+	destinationName := pullrequest.Destination.Branch.Name
+
+	fmt.Fprintf(writer, "%s %s: %s → %s (%s)\n", colorize(common.ColorBold, fmt.Sprintf("Pullrequest #%d", pullrequest.ID)), pullrequest.Title, sourceName, destinationName, result.Strategy)
+	if result.IsClean() {
+		fmt.Fprintln(writer, colorize(common.ColorGreen, "No conflict, the pullrequest can be merged"))
+		return
+	}
+	if result.Strategy == "fast_forward" && !result.CanFastForward {
+		fmt.Fprintln(writer, colorize(common.ColorRed, fmt.Sprintf("Cannot fast-forward: %s has commits that are not in %s", destinationName, sourceName)))
+	}
+	for _, conflict := range result.Conflicts {
+		fmt.Fprintln(writer)
+		fmt.Fprintln(writer, colorize(common.ColorRed, fmt.Sprintf("CONFLICT (%s): %s", conflict.Reason, conflict.Path)))
+		for _, hunk := range conflict.Hunks {
+			fmt.Fprintln(writer, colorize(common.ColorCyan, fmt.Sprintf("@@ %s line %d, %s line %d @@", destinationName, hunk.DestinationLine, sourceName, hunk.SourceLine)))
+			fmt.Fprintln(writer, colorize(common.ColorGray, "<<<<<<< "+destinationName))
+			writeLines(writer, hunk.Destination, colorize, common.ColorYellow)
+			fmt.Fprintln(writer, colorize(common.ColorGray, "||||||| base"))
+			writeLines(writer, hunk.Base, colorize, "")
+			fmt.Fprintln(writer, colorize(common.ColorGray, "======="))
+			writeLines(writer, hunk.Source, colorize, common.ColorGreen)
+			fmt.Fprintln(writer, colorize(common.ColorGray, ">>>>>>> "+sourceName))
+		}
+	}
+	fmt.Fprintln(writer)
+	fmt.Fprintf(writer, "%d conflicting files\n", len(result.Conflicts))
+}
+
+// writeLines writes lines that keep their end of line, in the given color if any
+func writeLines(writer io.Writer, lines []string, colorize func(common.Color, string) string, color common.Color) {
+	for _, line := range lines {
+		line = strings.TrimSuffix(line, "\n")
+		if len(color) > 0 {
+			line = colorize(color, line)
+		}
+		fmt.Fprintln(writer, line)
+	}
+}
@@ -0,0 +1,139 @@
+package pullrequest
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/gildas/bitbucket-cli/cmd/pullrequest/comment"
+	prcommon "github.com/gildas/bitbucket-cli/cmd/pullrequest/common"
+	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-flags"
+	"github.com/gildas/go-logger"
+	"github.com/spf13/cobra"
+)
+
+var conversationCmd = &cobra.Command{
+	Use:     "conversation [flags] <pullrequest-id>",
+	Aliases: []string{"conv", "threads"},
+	Short:   "show the comments of a pull request by its <pullrequest-id> as threads. If not provided, it will try to show the comments of the only open pullrequest.",
+	Long: `Show the comments of a pull request as threads, with the replies under the comment they answer.
+
+Inline comments are shown with the lines of code around them, taken from the diff of the pull request.
+Each thread shows if it is open, pending (not published yet), or resolved.
+With an output format other than table, the threads are printed in that format.`,
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest"},
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: conversationValidArgs,
+	RunE:              conversationProcess,
+}
+
+var conversationOptions struct {
+	Unresolved bool
+	Context    int
+	Color      *flags.EnumFlag
+	NoPager    bool
+}
+
+func init() {
+	Command.AddCommand(conversationCmd)
+
+	conversationOptions.Color = flags.NewEnumFlag("always", "+auto", "never")
+	conversationCmd.Flags().BoolVar(&conversationOptions.Unresolved, "unresolved", false, "Show only the threads that are not resolved")
+	conversationCmd.Flags().IntVar(&conversationOptions.Context, "context", 3, "Number of lines of code to show around inline comments")
+	conversationCmd.Flags().Var(conversationOptions.Color, "color", "colorize the output: always, auto, or never")
+	conversationCmd.Flags().BoolVar(&conversationOptions.NoPager, "no-pager", false, "Do not send the output to a pager")
+	_ = conversationCmd.RegisterFlagCompletionFunc(conversationOptions.Color.CompletionFunc("color"))
+}
+
+func conversationValidArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	ids, err := prcommon.GetPullRequestIDs(cmd.Context(), cmd, args, toComplete)
+	if err != nil {
+		cobra.CompErrorln(err.Error())
+		return []string{}, cobra.ShellCompDirectiveError
+	}
+	return common.FilterValidArgs(ids, args, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func conversationProcess(cmd *cobra.Command, args []string) error {
+	log := logger.Must(logger.FromContext(cmd.Context())).Child(cmd.Parent().Name(), "conversation")
+
+	currentProfile, err := profile.GetProfileFromCommand(cmd.Context(), cmd)
+	if err != nil {
+		return err
+	}
+
+	repository, err := repository.GetRepository(cmd.Context(), cmd)
+	if err != nil {
+		return errors.Join(errors.Errorf("Cannot show the conversation of the Pull Request"), err)
+	}
+
+	pullRequestID, err := GetPullRequestIDFromArgs(cmd.Context(), cmd, repository, args)
+	if err != nil {
+		return errors.Join(errors.Errorf("Cannot show the conversation of the Pull Request"), err)
+	}
+
+	log.Infof("Showing the conversation of pullrequest %s", pullRequestID)
+	if !common.WhatIf(log.ToContext(cmd.Context()), cmd, fmt.Sprintf("Showing the conversation of pullrequest %s", pullRequestID)) {
+		return nil
+	}
+
+	comments, err := profile.GetAll[comment.Comment](log.ToContext(cmd.Context()), cmd, repository.GetPath("pullrequests", pullRequestID, "comments"))
+	if err != nil {
+		return errors.Join(errors.Errorf("Failed to get the comments of pullrequest %s", pullRequestID), err)
+	}
+	threads := comment.Comments(comments).Threads()
+	if conversationOptions.Unresolved {
+		threads = threads.Unresolved()
+	}
+	if len(threads) == 0 {
+		log.Infof("No comment found")
+		fmt.Fprintf(os.Stderr, "No conversation in pullrequest %s\n", pullRequestID)
+		return nil
+	}
+
+	if getOutputFormat(cmd, currentProfile) != "table" {
+		return currentProfile.Print(cmd.Context(), cmd, threads)
+	}
+
+	diff := common.Diff{}
+	if hasInlineThreads(threads) {
+		if raw, err := currentProfile.GetRaw(log.ToContext(cmd.Context()), cmd, repository.GetPath("pullrequests", pullRequestID, "diff")); err != nil {
+			log.Warnf("Failed to get the diff of pullrequest %s, the code will not be shown: %s", pullRequestID, err)
+		} else if diff, err = common.ParseDiff(raw); err != nil {
+			log.Warnf("Failed to parse the diff of pullrequest %s, the code will not be shown: %s", pullRequestID, err)
+		}
+	}
+
+	var builder strings.Builder
+	options := comment.ThreadRenderOptions{
+		Color:   common.UseColor(conversationOptions.Color.String()),
+		Context: conversationOptions.Context,
+	}
+	if err := threads.Render(&builder, diff, options); err != nil {
+		return err
+	}
+	if conversationOptions.NoPager {
+		_, err := io.WriteString(os.Stdout, builder.String())
+		return err
+	}
+	return common.Page(log.ToContext(cmd.Context()), builder.String())
+}
+
+// hasInlineThreads tells if at least one of the threads is about a line of code
+func hasInlineThreads(threads comment.Threads) bool {
+	for _, thread := range threads {
+		if thread.IsInline() {
+			return true
+		}
+	}
+	return false
+}
@@ -1,21 +1,23 @@
 package pullrequest
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/gildas/bitbucket-cli/cmd/branch"
 	"github.com/gildas/bitbucket-cli/cmd/common"
 	"github.com/gildas/bitbucket-cli/cmd/profile"
-	"github.com/gildas/bitbucket-cli/cmd/project/reviewer"
 	"github.com/gildas/bitbucket-cli/cmd/repository"
 	"github.com/gildas/bitbucket-cli/cmd/user"
-	"github.com/gildas/bitbucket-cli/cmd/workspace"
-	"github.com/gildas/go-core"
 	"github.com/gildas/go-errors"
 	"github.com/gildas/go-flags"
 	"github.com/gildas/go-logger"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/spf13/cobra"
 )
 
@@ -30,42 +32,66 @@ type PullRequestCreator struct {
 }
 
 var createCmd = &cobra.Command{
-	Use:     "create",
-	Aliases: []string{"add", "new"},
-	Short:   "create a pullrequest",
-	Args:    cobra.NoArgs,
-	RunE:    createProcess,
+	Use:         "create",
+	Aliases:     []string{"add", "new"},
+	Short:       "create a pullrequest",
+	Annotations: map[string]string{profile.ScopesAnnotation: "pullrequest:write"},
+	Args:        cobra.NoArgs,
+	RunE:        createProcess,
 }
 
 var createOptions struct {
 	Title             string
 	Description       string
-	Source            *flags.EnumFlag
+	Source            string
 	Destination       *flags.EnumFlag
 	Reviewers         *flags.EnumSliceFlag
 	CloseSourceBranch bool
 	Draft             bool
+	Fill              bool
+	NoPush            bool
+	ReviewerStrategy  *flags.EnumFlag
+	ReviewerCount     int
+	ReviewerPool      string
+	CodeOwners        *flags.EnumFlag
 }
 
+// PullRequestTemplate is the path of the pullrequest template in the repository
+const PullRequestTemplate = ".bitbucket/PULL_REQUEST_TEMPLATE.md"
+
 func init() {
 	Command.AddCommand(createCmd)
 
-	createOptions.Source = flags.NewEnumFlagWithFunc(createCmd, "", branch.GetBranchNames)
 	createOptions.Destination = flags.NewEnumFlagWithFunc(createCmd, "", branch.GetBranchNames)
 	createOptions.Reviewers = flags.NewEnumSliceFlagWithAllAllowedAndFunc(createCmd, GetReviewerNicknames)
+	createOptions.ReviewerStrategy = flags.NewEnumFlag("+all", "round-robin", "least-loaded")
+	createOptions.CodeOwners = flags.NewEnumFlag("+none", "suggest", "add")
 
 	createCmd.Flags().StringVar(&createOptions.Title, "title", "", "Title of the pullrequest")
 	createCmd.Flags().StringVar(&createOptions.Description, "description", "", "Description of the pullrequest")
-	createCmd.Flags().Var(createOptions.Source, "source", "Source branch of the pullrequest")
-	createCmd.Flags().Var(createOptions.Destination, "destination", "Destination branch of the pullrequest")
+	createCmd.Flags().StringVar(&createOptions.Source, "source", "", "Source branch of the pullrequest. Default is the current branch")
+	createCmd.Flags().Var(createOptions.Destination, "destination", "Destination branch of the pullrequest. Default is the main branch of the repository")
 	createCmd.Flags().Var(createOptions.Reviewers, "reviewer", "Reviewer(s) of the pullrequest. Can be specified multiple times, or as a comma-separated list. Can be the user Account ID, UUID, name, or nickname. If the first reviewer is `default`, the command will try to find the default reviewers from the repository or project settings.")
+	createCmd.Flags().Var(createOptions.ReviewerStrategy, "reviewer-strategy", "How to pick the reviewers from the pool when no reviewer or \"default\" is given: all, round-robin, or least-loaded")
+	createCmd.Flags().IntVar(&createOptions.ReviewerCount, "reviewer-count", 1, "Number of reviewers to assign with the round-robin and least-loaded strategies, the author of the pullrequest is never a candidate")
+	createCmd.Flags().StringVar(&createOptions.ReviewerPool, "reviewer-pool", "default", "Candidate reviewers: default (the default reviewers of the repository or project), members (the workspace members), or group:<name> (a group of the .bb.yml file)")
+	createCmd.Flags().Var(createOptions.CodeOwners, "code-owners", "What to do with the code owners of the changed files (from the CODEOWNERS file of the destination branch): none, suggest, or add them as reviewers. Default is the code_owners setting of the .bb.yml file, or none")
 	createCmd.Flags().BoolVar(&createOptions.CloseSourceBranch, "close-source-branch", false, "Close the source branch of the pullrequest")
 	createCmd.Flags().BoolVar(&createOptions.Draft, "draft", false, "Create the pullrequest as a draft")
-	_ = createCmd.MarkFlagRequired("title")
-	_ = createCmd.MarkFlagRequired("source")
-	_ = createCmd.RegisterFlagCompletionFunc(createOptions.Source.CompletionFunc("source"))
+	createCmd.Flags().BoolVar(&createOptions.Fill, "fill", false, "Use the title and description from the commits (or the template) without opening an editor")
+	createCmd.Flags().BoolVar(&createOptions.NoPush, "no-push", false, "Do not push the source branch before creating the pullrequest")
+	_ = createCmd.RegisterFlagCompletionFunc("source", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		names, err := branch.GetBranchNames(cmd.Context(), cmd, args, toComplete)
+		if err != nil {
+			return []string{}, cobra.ShellCompDirectiveError
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	})
 	_ = createCmd.RegisterFlagCompletionFunc(createOptions.Destination.CompletionFunc("destination"))
 	_ = createCmd.RegisterFlagCompletionFunc(createOptions.Reviewers.CompletionFunc("reviewer"))
+	_ = createCmd.RegisterFlagCompletionFunc(createOptions.ReviewerStrategy.CompletionFunc("reviewer-strategy"))
+	_ = createCmd.RegisterFlagCompletionFunc("reviewer-pool", reviewerPoolCompletion)
+	_ = createCmd.RegisterFlagCompletionFunc(createOptions.CodeOwners.CompletionFunc("code-owners"))
 }
 
 func createProcess(cmd *cobra.Command, args []string) (err error) {
@@ -82,38 +108,68 @@ func createProcess(cmd *cobra.Command, args []string) (err error) {
 		return err
 	}
 
-	if len(createOptions.Title) == 0 {
+	source := createOptions.Source
+	if len(source) == 0 {
+		current, err := branch.GetCurrentBranch()
+		if err != nil {
+			return errors.Join(errors.Errorf("Cannot find the current branch, please provide the source branch with --source"), err)
+		}
+		source = current.Name
+	}
+	destination := createOptions.Destination.Value
+	if len(destination) == 0 {
+		destination = repository.MainBranch
+	}
+	if source == destination {
+		return errors.Errorf("The source branch %s is the destination branch, please checkout another branch or use --source", source)
+	}
+
+	codeOwnersMode, err := GetCodeOwnersMode(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	title, description, err := prepareFromLocalRepository(ctx, repository, source, destination)
+	if err != nil {
+		return err
+	}
+	if len(createOptions.Title) > 0 {
+		title = createOptions.Title
+	}
+	if len(createOptions.Description) > 0 {
+		description = createOptions.Description
+	}
+	if !createOptions.Fill && (len(createOptions.Title) == 0 || len(createOptions.Description) == 0) && common.CanEdit() {
+		text, err := common.EditText(ctx, "PULLREQUEST_*.md", title+"\n\n"+description)
+		if err != nil {
+			return err
+		}
+		title, description, _ = strings.Cut(strings.TrimSpace(text), "\n")
+		title = strings.TrimSpace(title)
+		description = strings.TrimSpace(description)
+	}
+	if len(title) == 0 {
 		return errors.ArgumentMissing.With("title")
 	}
 
 	payload := PullRequestCreator{
-		Title:             createOptions.Title,
-		Description:       createOptions.Description,
-		Source:            Endpoint{Branch: Branch{Name: createOptions.Source.Value}},
+		Title:             title,
+		Description:       description,
+		Source:            Endpoint{Branch: Branch{Name: source}},
 		CloseSourceBranch: createOptions.CloseSourceBranch,
 		Draft:             createOptions.Draft,
 	}
-	if len(createOptions.Destination.Value) > 0 {
-		payload.Destination = &Endpoint{Branch: Branch{Name: createOptions.Destination.Value}}
+	if len(destination) > 0 {
+		payload.Destination = &Endpoint{Branch: Branch{Name: destination}}
 	}
 
 	log.Record("repository", repository).Infof("Using repository: %s", repository)
 
 	if len(createOptions.Reviewers.Values) > 0 && createOptions.Reviewers.Values[0] != "default" {
-		isMember := func(member workspace.Member, id string) bool {
-			if id, err := common.ParseUUID(id); err == nil {
-				return member.User.ID == id
-			}
-			return member.User.AccountID == id || strings.EqualFold(member.User.Nickname, id) || strings.EqualFold(member.User.Name, id)
-		}
-
 		members, _ := repository.Workspace.GetMembers(ctx, cmd)
 		payload.Reviewers = make([]user.User, 0, len(createOptions.Reviewers.Values))
 		for _, reviewer := range createOptions.Reviewers.Values {
-			if matches := core.Filter(members, func(member workspace.Member) bool { return isMember(member, reviewer) }); len(matches) > 0 {
-				log.Record("matches", matches).Infof("Adding reviewer: %s", matches[0].User.ID)
-				payload.Reviewers = append(payload.Reviewers, matches[0].User)
-			} else if user, err := user.GetUser(ctx, cmd, reviewer); err == nil {
+			if user, err := FindUser(ctx, cmd, members, reviewer); err == nil {
 				log.Record("user", user).Infof("Adding reviewer: %s", reviewer)
 				payload.Reviewers = append(payload.Reviewers, *user)
 			} else {
@@ -122,33 +178,16 @@ func createProcess(cmd *cobra.Command, args []string) (err error) {
 			}
 		}
 	} else {
-		var reviewers []reviewer.Reviewer
-
-		// Find me
-		log.Debugf("Finding current user")
-		me, errMe := user.GetMe(ctx, cmd)
-		if errMe != nil {
-			// RAT (repo scoped tokens) do not have access to that API endpoint usually
-			log.Warnf("Failed to get current user, this may be a RAT client. Error: %s", errMe.Error())
-		} else {
-			log.Infof("Current user: %s (%s)", me.Username, me.ID)
-		}
-
-		// Find the default reviewers from the repo or project settings
-		log.Debugf("No reviewers in the repository, trying to get effective default reviewers from the repository")
-		reviewers, err = repository.GetEffectiveDefaultReviewers(ctx, cmd)
-		if err != nil {
-			log.Errorf("Failed to get default reviewers", err)
-			return errors.Join(errors.New("Failed to get the default reviewers"), err, errMe)
+		if payload.Reviewers, err = assignReviewers(ctx, cmd, profile, repository); err != nil {
+			return err
 		}
-		log.Debugf("Found %d default reviewers", len(reviewers))
+	}
 
-		if me != nil {
-			// Removing myself from the reviewers since I cannot be a reviewer of my own pullrequest
-			reviewers = core.Filter(reviewers, func(reviewer reviewer.Reviewer) bool { return reviewer.User.ID != me.ID })
-			log.Debugf("Filtered reviewers to remove current user: %d reviewers remaining", len(reviewers))
+	// The branch is pushed once the pullrequest is ready, so aborting the editor does not leave a pushed branch without a pullrequest
+	if !createOptions.NoPush {
+		if err := pushSourceBranch(ctx, cmd, profile, repository, source); err != nil {
+			return err
 		}
-		payload.Reviewers = core.Map(reviewers, func(reviewer reviewer.Reviewer) user.User { return reviewer.User })
 	}
 
 	log.Record("payload", payload).Infof("Creating pullrequest")
@@ -168,5 +207,123 @@ func createProcess(cmd *cobra.Command, args []string) (err error) {
 	if err != nil {
 		return errors.Join(errors.Errorf("Failed to create pullrequest"), err)
 	}
+
+	// The pullrequest is created, a failure with the code owners should not fail the command
+	if applyCodeOwners(ctx, cmd, profile, repository, &pullrequest, codeOwnersMode) {
+		updated, err := UpdatePullRequest(ctx, cmd, profile, repository, pullrequest)
+		if err != nil {
+			log.Warnf("Failed to add the code owners as reviewers of pullrequest %d: %s", pullrequest.ID, err)
+			fmt.Fprintf(os.Stderr, "Warning: Failed to add the code owners as reviewers of pullrequest %d: %s\n", pullrequest.ID, err)
+		} else {
+			return profile.Print(cmd.Context(), cmd, updated)
+		}
+	}
 	return profile.Print(cmd.Context(), cmd, pullrequest)
 }
+
+// prepareFromLocalRepository gets the default title and description from the local repository
+//
+// The title comes from the only commit since the destination branch, or from the source branch name.
+// The description comes from the pullrequest template if the repository has one, or from the commits since the destination branch.
+//
+// When the current folder is not a git repository, the pullrequest is created from the branches known by Bitbucket.
+func prepareFromLocalRepository(ctx context.Context, repository *repository.Repository, source, destination string) (title, description string, err error) {
+	log := logger.Must(logger.FromContext(ctx)).Child("pullrequest", "prepare")
+
+	title = titleFromBranchName(source)
+	repo, err := branch.OpenLocalRepository()
+	if err != nil {
+		log.Warnf("Cannot use the local repository: %s", err)
+		return title, "", nil
+	}
+	remoteName, _ := findGitRemote(repo, repository.FullName)
+
+	var commits []*object.Commit
+	if head, err := branch.ResolveLocalBranch(repo, "", source); err != nil {
+		log.Warnf("Cannot find the source branch %s locally: %s", source, err)
+	} else if base, err := branch.ResolveLocalBranch(repo, remoteName, destination); err != nil {
+		log.Warnf("Cannot find the destination branch %s locally: %s", destination, err)
+	} else if commits, err = branch.GetCommitsSince(repo, head, base); err != nil {
+		log.Warnf("Cannot get the commits of %s since %s: %s", source, destination, err)
+	}
+	log.Infof("Found %d commits since %s", len(commits), destination)
+	if len(commits) == 1 {
+		title, description, _ = strings.Cut(strings.TrimSpace(commits[0].Message), "\n")
+		description = strings.TrimSpace(description)
+	} else {
+		lines := make([]string, 0, len(commits))
+		for i := len(commits) - 1; i >= 0; i-- { // oldest first
+			subject, _, _ := strings.Cut(strings.TrimSpace(commits[i].Message), "\n")
+			lines = append(lines, "- "+subject)
+		}
+		description = strings.Join(lines, "\n")
+	}
+
+	if worktree, err := repo.Worktree(); err == nil {
+		if template, err := os.ReadFile(filepath.Join(worktree.Filesystem.Root(), PullRequestTemplate)); err == nil {
+			log.Infof("Using the pullrequest template %s", PullRequestTemplate)
+			description = strings.TrimSpace(string(template))
+		}
+	}
+	return title, description, nil
+}
+
+// pushSourceBranch pushes the source branch of a pullrequest to its git remote if it has commits that were not pushed yet
+//
+// Nothing is pushed when the current folder is not a git repository, the branch is not local, or none of the git remotes is the repository
+func pushSourceBranch(ctx context.Context, cmd *cobra.Command, profile *profile.Profile, repository *repository.Repository, source string) error {
+	log := logger.Must(logger.FromContext(ctx)).Child("pullrequest", "push", "branch", source)
+
+	repo, err := branch.OpenLocalRepository()
+	if err != nil {
+		log.Warnf("Cannot use the local repository: %s", err)
+		return nil
+	}
+	remoteName, remoteURL := findGitRemote(repo, repository.FullName)
+	if len(remoteName) == 0 {
+		log.Warnf("None of the git remotes is the repository %s, the source branch will not be pushed", repository.FullName)
+		return nil
+	}
+	if _, err := repo.Reference(plumbing.NewBranchReferenceName(source), true); err != nil || branch.IsPushed(repo, remoteName, source) {
+		return nil
+	}
+	auth, err := getGitAuth(ctx, profile, remoteURL)
+	if err != nil {
+		return err
+	}
+	if common.WhatIf(ctx, cmd, "Pushing branch %s to %s", source, remoteName) {
+		if err := branch.Push(ctx, repo, remoteName, source, auth); err != nil {
+			return err
+		}
+		common.Verbose(ctx, cmd, "Pushed branch %s to %s", source, remoteName)
+	}
+	return nil
+}
+
+// getGitAuth gets the git authentication to use with the given remote URL
+//
+// HTTPS remotes use the git credentials of the profile, SSH remotes use the SSH agent (nil)
+func getGitAuth(ctx context.Context, profile *profile.Profile, remoteURL string) (transport.AuthMethod, error) {
+	if !strings.HasPrefix(remoteURL, "https://") {
+		return nil, nil
+	}
+	credential, err := profile.GetGitCredential(ctx)
+	if err != nil {
+		return nil, errors.Join(errors.Errorf("Cannot get the git credentials of profile %s", profile.Name), err)
+	}
+	return credential.AsHTTPBasicAuth(), nil
+}
+
+// titleFromBranchName makes a pullrequest title from a branch name
+//
+// e.g.: feature/add-login-page becomes "Add login page"
+func titleFromBranchName(name string) string {
+	if index := strings.LastIndex(name, "/"); index >= 0 {
+		name = name[index+1:]
+	}
+	name = strings.TrimSpace(strings.NewReplacer("-", " ", "_", " ").Replace(name))
+	if len(name) == 0 {
+		return ""
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -1,6 +1,9 @@
 package pullrequest
 
 import (
+	"context"
+	"strconv"
+
 	"github.com/gildas/bitbucket-cli/cmd/common"
 	"github.com/gildas/bitbucket-cli/cmd/profile"
 	"github.com/gildas/bitbucket-cli/cmd/pullrequest/common"
@@ -14,13 +17,18 @@ import (
 var declineCmd = &cobra.Command{
 	Use:               "decline [flags] <pullrequest-id>",
 	Short:             "decline a pullrequest by its <pullrequest-id>. If not provided, it will try to decline the only open pullrequest.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest:write"},
 	Args:              cobra.MaximumNArgs(1),
 	ValidArgsFunction: declineValidArgs,
 	RunE:              declineProcess,
 }
 
+var declineOptions bulkOptions
+
 func init() {
 	Command.AddCommand(declineCmd)
+
+	declineOptions.addFlags(declineCmd, "Decline")
 }
 
 func declineValidArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -49,6 +57,23 @@ func declineProcess(cmd *cobra.Command, args []string) (err error) {
 		return errors.Join(errors.Errorf("Cannot decline Pull Request"), err)
 	}
 
+	if declineOptions.isBulk(cmd) {
+		if len(args) > 0 {
+			return errors.ArgumentInvalid.With("pullrequest-id", "cannot be used with filters")
+		}
+		pullrequests, err := declineOptions.getPullRequests(log.ToContext(cmd.Context()), cmd, repository)
+		if err != nil {
+			return errors.Join(errors.Errorf("Cannot decline Pull Requests"), err)
+		}
+		return declineOptions.processBulk(log.ToContext(cmd.Context()), cmd, profile, "Declining", pullrequests, func(ctx context.Context, pullrequest PullRequest) error {
+			var declined PullRequest
+			if err := profile.Post(ctx, cmd, repository.GetPath("pullrequests", strconv.FormatUint(pullrequest.ID, 10), "decline"), nil, &declined); err != nil {
+				return errors.Join(errors.Errorf("Failed to decline Pull Request %d", pullrequest.ID), err)
+			}
+			return nil
+		})
+	}
+
 	pullRequestID, err := GetPullRequestIDFromArgs(cmd.Context(), cmd, repository, args)
 	if err != nil {
 		return errors.Join(errors.Errorf("Cannot decline Pull Request"), err)
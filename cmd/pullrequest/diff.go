@@ -2,8 +2,6 @@ package pullrequest
 
 import (
 	"fmt"
-	"io"
-	"os"
 
 	"github.com/gildas/bitbucket-cli/cmd/common"
 	"github.com/gildas/bitbucket-cli/cmd/profile"
@@ -17,19 +15,18 @@ import (
 var diffCmd = &cobra.Command{
 	Use:               "diff [flags] <pullrequest-id>",
 	Short:             "show the diff of a pull request by its <pullrequest-id>. If not provided, it will try to show the diff of the only open pullrequest.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest"},
 	Args:              cobra.MaximumNArgs(1),
 	ValidArgsFunction: validDiffArgs,
 	RunE:              diffProcess,
 }
 
-var diffOptions struct {
-	Stat bool
-}
+var diffOptions common.DiffOptions
 
 func init() {
 	Command.AddCommand(diffCmd)
 
-	diffCmd.Flags().BoolVar(&diffOptions.Stat, "stat", false, "show only the diffstat")
+	diffOptions.AddFlags(diffCmd)
 }
 
 func validDiffArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -73,11 +70,17 @@ func diffProcess(cmd *cobra.Command, args []string) error {
 		uripath = repository.GetPath("pullrequests", pullRequestID, "diffstat")
 	}
 
+	query, err := diffOptions.GetQuery()
+	if err != nil {
+		return errors.Join(errors.Errorf("Path patterns are not supported with --stat"), err)
+	}
+	if len(query) > 0 {
+		uripath += "?" + query.Encode()
+	}
+
 	diff, err := profile.GetRaw(log.ToContext(cmd.Context()), cmd, uripath)
 	if err != nil {
 		return err
 	}
-
-	_, err = io.Copy(os.Stdout, diff)
-	return err
+	return diffOptions.Print(log.ToContext(cmd.Context()), diff)
 }
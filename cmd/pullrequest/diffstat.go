@@ -0,0 +1,53 @@
+package pullrequest
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/spf13/cobra"
+)
+
+// DiffStat is the number of lines changed in a file of a pullrequest
+type DiffStat struct {
+	Type         string        `json:"type"          mapstructure:"type"`
+	Status       string        `json:"status"        mapstructure:"status"`
+	LinesAdded   uint64        `json:"lines_added"   mapstructure:"lines_added"`
+	LinesRemoved uint64        `json:"lines_removed" mapstructure:"lines_removed"`
+	Old          *DiffStatFile `json:"old,omitempty" mapstructure:"old"`
+	New          *DiffStatFile `json:"new,omitempty" mapstructure:"new"`
+}
+
+// DiffStatFile is a file of a DiffStat
+type DiffStatFile struct {
+	Path string `json:"path" mapstructure:"path"`
+}
+
+// GetDiffStats gets the diffstat of a pullrequest
+func GetDiffStats(ctx context.Context, cmd *cobra.Command, repository *repository.Repository, pullRequestID uint64) ([]DiffStat, error) {
+	return profile.GetAll[DiffStat](ctx, cmd, repository.GetPath("pullrequests", strconv.FormatUint(pullRequestID, 10), "diffstat"))
+}
+
+// Path gets the path of the file, the new one unless the file was removed
+func (diffstat DiffStat) Path() string {
+	if diffstat.New != nil {
+		return diffstat.New.Path
+	}
+	if diffstat.Old != nil {
+		return diffstat.Old.Path
+	}
+	return ""
+}
+
+// HasConflict tells if the file has a merge conflict
+//
+// Bitbucket reports conflicts with the statuses: merge conflict, local deleted, remote deleted, local and remote deleted
+func (diffstat DiffStat) HasConflict() bool {
+	switch diffstat.Status {
+	case "added", "removed", "modified", "renamed":
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,198 @@
+package pullrequest
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	prcommon "github.com/gildas/bitbucket-cli/cmd/pullrequest/common"
+	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-flags"
+	"github.com/gildas/go-logger"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [flags] <pullrequest-id>...",
+	Short: "export pull requests by their <pullrequest-id> to a Markdown, HTML, or JSON report. If not provided, it will try to export the only open pullrequest.",
+	Long: `Export pull requests to a single self-contained Markdown, HTML, or JSON report.
+
+The report contains the metadata of each pull request, its reviewers and approvals, its builds, commits, changed files, tasks, comments, and activity log.
+With --merged-since, every pull request merged since that date (and until --merged-until) is exported.
+Dates can be given as 2025-12-31, 2025-12-31T12:00:00Z, today, yesterday, or as a duration before now like 72h, 7d, or 2w.`,
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest"},
+	ValidArgsFunction: exportValidArgs,
+	RunE:              exportProcess,
+}
+
+var exportOptions struct {
+	Format      *flags.EnumFlag
+	File        string
+	MergedSince string
+	MergedUntil string
+}
+
+func init() {
+	Command.AddCommand(exportCmd)
+
+	exportOptions.Format = flags.NewEnumFlag("+markdown", "html", "json")
+	exportCmd.Flags().Var(exportOptions.Format, "format", "Format of the report: markdown, html, or json")
+	exportCmd.Flags().StringVar(&exportOptions.File, "file", "", "File to write the report to. Default is the standard output")
+	exportCmd.Flags().StringVar(&exportOptions.MergedSince, "merged-since", "", "Export all the pull requests merged since this date")
+	exportCmd.Flags().StringVar(&exportOptions.MergedUntil, "merged-until", "", "Export the pull requests merged until this date (a date includes that whole day), with --merged-since")
+	_ = exportCmd.RegisterFlagCompletionFunc(exportOptions.Format.CompletionFunc("format"))
+}
+
+func exportValidArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	ids, err := prcommon.GetPullRequestIDs(cmd.Context(), cmd, args, toComplete)
+	if err != nil {
+		cobra.CompErrorln(err.Error())
+		return []string{}, cobra.ShellCompDirectiveError
+	}
+	return common.FilterValidArgs(ids, args, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func exportProcess(cmd *cobra.Command, args []string) (err error) {
+	log := logger.Must(logger.FromContext(cmd.Context())).Child(cmd.Parent().Name(), "export")
+	ctx := log.ToContext(cmd.Context())
+
+	currentProfile, err := profile.GetProfileFromCommand(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	repository, err := repository.GetRepository(ctx, cmd)
+	if err != nil {
+		return errors.Join(errors.Errorf("Cannot export the Pull Requests"), err)
+	}
+
+	var ids []string
+	switch {
+	case len(exportOptions.MergedSince) > 0 && len(args) > 0:
+		return errors.ArgumentInvalid.With("pullrequest-id", "cannot be used with --merged-since")
+	case len(exportOptions.MergedSince) > 0:
+		if ids, err = getMergedPullRequestIDs(cmd, repository); err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			fmt.Fprintln(os.Stderr, "No pullrequest was merged in that period")
+			return nil
+		}
+	case len(exportOptions.MergedUntil) > 0:
+		return errors.ArgumentMissing.With("merged-since")
+	case len(args) > 0:
+		ids = args
+	default:
+		id, err := GetPullRequestIDFromArgs(ctx, cmd, repository, args)
+		if err != nil {
+			return errors.Join(errors.Errorf("Cannot export the Pull Request"), err)
+		}
+		ids = []string{id}
+	}
+
+	log.Infof("Exporting %d pullrequests as %s", len(ids), exportOptions.Format)
+	if !common.WhatIf(ctx, cmd, "Exporting pullrequests %v as %s", ids, exportOptions.Format) {
+		return nil
+	}
+
+	// The pullrequests that cannot be exported are left out of the report, as configured by the error flags
+	reports := Reports{ExportedOn: time.Now().UTC(), Reports: make([]Report, 0, len(ids))}
+	var merr errors.MultiError
+	for index, id := range ids {
+		common.Verbose(ctx, cmd, "Exporting pullrequest %s (%d/%d)", id, index+1, len(ids))
+		report, err := GetReport(ctx, cmd, currentProfile, repository, id)
+		if err != nil {
+			if currentProfile.ShouldStopOnError(cmd) {
+				return err
+			}
+			log.Errorf("Failed to export pullrequest %s", id, err)
+			merr.Append(errors.Join(errors.Errorf("Failed to export pullrequest %s", id), err))
+			continue
+		}
+		reports.Reports = append(reports.Reports, *report)
+	}
+
+	var writer io.Writer = os.Stdout
+	if len(exportOptions.File) > 0 {
+		file, createErr := os.Create(exportOptions.File)
+		if createErr != nil {
+			return errors.Join(errors.Errorf("Cannot create the report file %s", exportOptions.File), createErr)
+		}
+		// Closing flushes the file, its error is the error of the command
+		defer func() {
+			if closeErr := file.Close(); closeErr != nil && err == nil {
+				err = errors.Join(errors.Errorf("Failed to write the report file %s", exportOptions.File), closeErr)
+			}
+		}()
+		writer = file
+	}
+
+	switch exportOptions.Format.Value {
+	case "html":
+		err = reports.WriteHTML(writer)
+	case "json":
+		err = reports.WriteJSON(writer)
+	default:
+		err = reports.WriteMarkdown(writer)
+	}
+	if err != nil {
+		return errors.Join(errors.Errorf("Failed to write the report"), err)
+	}
+	if len(exportOptions.File) > 0 {
+		common.Verbose(ctx, cmd, "Exported %d pullrequests to %s", len(reports.Reports), exportOptions.File)
+	}
+	if !merr.IsEmpty() && currentProfile.ShouldWarnOnError(cmd) {
+		fmt.Fprintf(os.Stderr, "Failed to export these pullrequests: %s\n", merr)
+		return nil
+	}
+	if currentProfile.ShouldIgnoreErrors(cmd) {
+		log.Warnf("Failed to export these pullrequests, but ignoring errors: %s", merr)
+		return nil
+	}
+	return merr.AsError()
+}
+
+// getMergedPullRequestIDs gets the IDs of the pullrequests merged between --merged-since and --merged-until
+//
+// Bitbucket does not tell when a pullrequest was merged, its last update is used instead
+func getMergedPullRequestIDs(cmd *cobra.Command, repository *repository.Repository) ([]string, error) {
+	query, err := GetMergedQuery(exportOptions.MergedSince, exportOptions.MergedUntil, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	pullrequests, err := getPullRequestsWithQuery(cmd.Context(), cmd, repository, query.String())
+	if err != nil {
+		return nil, errors.Join(errors.Errorf("Failed to get the merged pullrequests"), err)
+	}
+	ids := make([]string, 0, len(pullrequests))
+	for index := len(pullrequests) - 1; index >= 0; index-- { // the oldest first
+		ids = append(ids, strconv.FormatUint(pullrequests[index].ID, 10))
+	}
+	return ids, nil
+}
+
+// GetMergedQuery gets the BBQL query of the pullrequests merged between since and until
+//
+// until is optional, a date (2026-03-31) includes that whole day
+func GetMergedQuery(since, until string, now time.Time) (common.BBQL, error) {
+	var query common.BBQL
+
+	after, err := common.ParseBBQLTime(since, now)
+	if err != nil {
+		return nil, errors.Join(errors.Errorf("Invalid value for --merged-since"), err)
+	}
+	query.Equals("state", "MERGED").After("updated_on", after)
+	if len(until) > 0 {
+		before, err := common.ParseBBQLEndTime(until, now)
+		if err != nil {
+			return nil, errors.Join(errors.Errorf("Invalid value for --merged-until"), err)
+		}
+		query.Before("updated_on", before)
+	}
+	return query, nil
+}
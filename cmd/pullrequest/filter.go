@@ -0,0 +1,165 @@
+package pullrequest
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/gildas/bitbucket-cli/cmd/branch"
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/gildas/bitbucket-cli/cmd/user"
+	"github.com/gildas/bitbucket-cli/cmd/workspace"
+	"github.com/gildas/go-errors"
+	"github.com/spf13/cobra"
+)
+
+// pullRequestFilter contains the filter flags that select pullrequests
+type pullRequestFilter struct {
+	Query           string
+	Author          string
+	Reviewer        string
+	Mine            bool
+	ReviewRequested bool
+	Draft           bool
+	Source          string
+	Destination     string
+	TitleContains   string
+	CreatedAfter    string
+	UpdatedBefore   string
+	flags           []string // the filter flags added to the command
+}
+
+// pullRequestFilterFlags are the names of the flags of a pullRequestFilter
+var pullRequestFilterFlags = []string{"query", "author", "reviewer", "mine", "review-requested", "draft", "source", "destination", "title-contains", "created-after", "updated-before"}
+
+// addFlags adds the filter flags to the given command, verb starts their usage (e.g. "List", "Decline")
+//
+// The flags the command already has (like --destination for update) are not added
+func (filter *pullRequestFilter) addFlags(cmd *cobra.Command, verb string) {
+	stringVar := func(value *string, name, usage string) {
+		if cmd.Flags().Lookup(name) == nil {
+			cmd.Flags().StringVar(value, name, "", usage)
+			filter.flags = append(filter.flags, name)
+		}
+	}
+	boolVar := func(value *bool, name, usage string) {
+		if cmd.Flags().Lookup(name) == nil {
+			cmd.Flags().BoolVar(value, name, false, usage)
+			filter.flags = append(filter.flags, name)
+		}
+	}
+
+	stringVar(&filter.Query, "query", "Query string to filter pull requests, ANDed with the other filters")
+	stringVar(&filter.Author, "author", verb+" pull requests created by this user")
+	stringVar(&filter.Reviewer, "reviewer", verb+" pull requests reviewed by this user")
+	boolVar(&filter.Mine, "mine", verb+" pull requests created by me")
+	boolVar(&filter.ReviewRequested, "review-requested", verb+" pull requests I am a reviewer of")
+	boolVar(&filter.Draft, "draft", verb+" draft pull requests only, use --draft=false for non draft pull requests only")
+	stringVar(&filter.Source, "source", verb+" pull requests from this source branch")
+	stringVar(&filter.Destination, "destination", verb+" pull requests to this destination branch")
+	stringVar(&filter.TitleContains, "title-contains", verb+" pull requests whose title contains this text")
+	stringVar(&filter.CreatedAfter, "created-after", verb+" pull requests created after this date")
+	stringVar(&filter.UpdatedBefore, "updated-before", verb+" pull requests last updated before this date")
+	cmd.MarkFlagsMutuallyExclusive("author", "mine")
+	cmd.MarkFlagsMutuallyExclusive("reviewer", "review-requested")
+	_ = cmd.RegisterFlagCompletionFunc("author", filterUserCompletion)
+	_ = cmd.RegisterFlagCompletionFunc("reviewer", filterUserCompletion)
+	_ = cmd.RegisterFlagCompletionFunc("source", filterBranchCompletion)
+	if slices.Contains(filter.flags, "destination") {
+		_ = cmd.RegisterFlagCompletionFunc("destination", filterBranchCompletion)
+	}
+}
+
+// isSet tells if any filter flag was given to the command
+func (filter pullRequestFilter) isSet(cmd *cobra.Command) bool {
+	for _, name := range filter.flags {
+		if cmd.Flag(name).Changed {
+			return true
+		}
+	}
+	return false
+}
+
+func filterUserCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	nicknames, err := GetReviewerNicknames(cmd.Context(), cmd, []string{}, toComplete)
+	if err != nil {
+		cobra.CompErrorln(err.Error())
+		return []string{}, cobra.ShellCompDirectiveError
+	}
+	return append([]string{"me"}, nicknames...), cobra.ShellCompDirectiveNoFileComp
+}
+
+func filterBranchCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names, err := branch.GetBranchNames(cmd.Context(), cmd, []string{}, toComplete)
+	if err != nil {
+		cobra.CompErrorln(err.Error())
+		return []string{}, cobra.ShellCompDirectiveError
+	}
+	return common.FilterValidArgs(names, []string{}, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// getQuery compiles the filter flags into a BBQL query
+func (filter pullRequestFilter) getQuery(ctx context.Context, cmd *cobra.Command, repository *repository.Repository) (query common.BBQL, err error) {
+	var members []workspace.Member
+
+	findUser := func(id string) (*user.User, error) {
+		if members == nil && repository.Workspace != nil {
+			members, _ = repository.Workspace.GetMembers(ctx, cmd)
+		}
+		found, err := FindUser(ctx, cmd, members, id)
+		if err != nil {
+			return nil, errors.Join(errors.Errorf("Cannot find user %s", id), err)
+		}
+		return found, nil
+	}
+
+	if filter.Mine {
+		filter.Author = "me"
+	}
+	if filter.ReviewRequested {
+		filter.Reviewer = "me"
+	}
+	if len(filter.Author) > 0 {
+		author, err := findUser(filter.Author)
+		if err != nil {
+			return nil, err
+		}
+		query.Equals("author.uuid", author.ID.String())
+	}
+	if len(filter.Reviewer) > 0 {
+		reviewer, err := findUser(filter.Reviewer)
+		if err != nil {
+			return nil, err
+		}
+		query.Equals("reviewers.uuid", reviewer.ID.String())
+	}
+	if slices.Contains(filter.flags, "draft") && cmd.Flag("draft").Changed {
+		query.Is("draft", filter.Draft)
+	}
+	if len(filter.Source) > 0 {
+		query.Equals("source.branch.name", filter.Source)
+	}
+	if len(filter.Destination) > 0 {
+		query.Equals("destination.branch.name", filter.Destination)
+	}
+	if len(filter.TitleContains) > 0 {
+		query.Contains("title", filter.TitleContains)
+	}
+	if len(filter.CreatedAfter) > 0 {
+		createdAfter, err := common.ParseBBQLTime(filter.CreatedAfter, time.Now())
+		if err != nil {
+			return nil, errors.Join(errors.Errorf("Invalid value for --created-after"), err)
+		}
+		query.After("created_on", createdAfter)
+	}
+	if len(filter.UpdatedBefore) > 0 {
+		updatedBefore, err := common.ParseBBQLTime(filter.UpdatedBefore, time.Now())
+		if err != nil {
+			return nil, errors.Join(errors.Errorf("Invalid value for --updated-before"), err)
+		}
+		query.Before("updated_on", updatedBefore)
+	}
+	query.Raw(filter.Query)
+	return query, nil
+}
@@ -17,6 +17,7 @@ var getCmd = &cobra.Command{
 	Use:               "get [flags] <pullrequest-id>",
 	Aliases:           []string{"show", "info", "display"},
 	Short:             "get a pullrequest by its <pullrequest-id>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: getValidArgs,
 	RunE:              getProcess,
@@ -24,6 +25,7 @@ var getCmd = &cobra.Command{
 
 var getOptions struct {
 	Columns *flags.EnumSliceFlag
+	Web     bool
 }
 
 func init() {
@@ -31,6 +33,7 @@ func init() {
 
 	getOptions.Columns = flags.NewEnumSliceFlag(columns.Columns()...)
 	getCmd.Flags().Var(getOptions.Columns, "columns", "Comma-separated list of columns to display")
+	getCmd.Flags().BoolVar(&getOptions.Web, "web", false, "Open the pullrequest in the web browser instead of displaying it")
 	_ = getCmd.RegisterFlagCompletionFunc(getOptions.Columns.CompletionFunc("columns"))
 }
 
@@ -76,5 +79,8 @@ func getProcess(cmd *cobra.Command, args []string) error {
 		return errors.Join(errors.Errorf("Failed to get pullrequest %s", args[0]), err)
 	}
 
+	if getOptions.Web {
+		return common.Browse(log.ToContext(cmd.Context()), cmd, pullrequest.Links.HTML, false)
+	}
 	return profile.Print(cmd.Context(), cmd, pullrequest)
 }
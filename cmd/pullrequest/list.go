@@ -17,14 +17,21 @@ import (
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "list all pullrequests",
-	Args:  cobra.NoArgs,
-	RunE:  listProcess,
+	Long: `List the pullrequests of a repository.
+
+The filters (--author, --reviewer, --mine, --review-requested, --draft, --source, --destination, --title-contains, --created-after, --updated-before)
+are compiled into a Bitbucket query, the --query flag is ANDed with them.
+Users can be given by Account ID, UUID, name, nickname, or "me".
+Dates can be given as 2025-12-31, 2025-12-31T12:00:00Z, today, yesterday, or as a duration before now like 72h, 7d, or 2w.`,
+	Annotations: map[string]string{profile.ScopesAnnotation: "pullrequest"},
+	Args:        cobra.NoArgs,
+	RunE:        listProcess,
 }
 
 var listOptions struct {
 	Commit     string
 	State      *flags.EnumFlag
-	Query      string
+	Filter     pullRequestFilter
 	Columns    *flags.EnumSliceFlag
 	SortBy     *flags.EnumFlag
 	PageLength int
@@ -38,12 +45,14 @@ func init() {
 	listOptions.SortBy = flags.NewEnumFlag(columns.Sorters()...)
 	listCmd.Flags().StringVar(&listOptions.Commit, "commit", "", "List pull requests by commit hash")
 	listCmd.Flags().Var(listOptions.State, "state", "Pull request state to fetch. Defaults to \"open\"")
-	listCmd.Flags().StringVar(&listOptions.Query, "query", "", "Query string to filter pull requests")
+	listOptions.Filter.addFlags(listCmd, "List")
 	listCmd.Flags().Var(listOptions.Columns, "columns", "Comma-separated list of columns to display")
 	listCmd.Flags().Var(listOptions.SortBy, "sort", "Column to sort by")
 	listCmd.Flags().IntVar(&listOptions.PageLength, "page-length", 0, "Number of items per page to retrieve from Bitbucket. Default is the profile's default page length")
 	listCmd.MarkFlagsMutuallyExclusive("commit", "state")
-	listCmd.MarkFlagsMutuallyExclusive("commit", "query")
+	for _, filter := range pullRequestFilterFlags {
+		listCmd.MarkFlagsMutuallyExclusive("commit", filter)
+	}
 	_ = listCmd.RegisterFlagCompletionFunc(listOptions.State.CompletionFunc("state"))
 	_ = listCmd.RegisterFlagCompletionFunc(listOptions.Columns.CompletionFunc("columns"))
 	_ = listCmd.RegisterFlagCompletionFunc(listOptions.SortBy.CompletionFunc("sort"))
@@ -61,10 +70,18 @@ func listProcess(cmd *cobra.Command, args []string) (err error) {
 
 	if len(listOptions.Commit) > 0 {
 		uripath = repository.GetPath("commit", listOptions.Commit, "pullrequests")
-	} else if len(listOptions.Query) > 0 {
-		uripath = repository.GetPath(fmt.Sprintf("pullrequests?state=%s&q=%s", url.QueryEscape(strings.ToUpper(listOptions.State.String())), url.QueryEscape(listOptions.Query)))
 	} else {
-		uripath = repository.GetPath(fmt.Sprintf("pullrequests?state=%s", url.QueryEscape(strings.ToUpper(listOptions.State.String()))))
+		query, err := listOptions.Filter.getQuery(log.ToContext(cmd.Context()), cmd, repository)
+		if err != nil {
+			return err
+		}
+		parameters := url.Values{}
+		parameters.Set("state", strings.ToUpper(listOptions.State.String()))
+		if !query.IsEmpty() {
+			log.Infof("Filtering pull requests with: %s", query)
+			parameters.Set("q", query.String())
+		}
+		uripath = repository.GetPath("pullrequests?" + parameters.Encode())
 	}
 
 	log.Infof("Listing %s pull requests for repository: %s", listOptions.State, repository)
@@ -1,10 +1,19 @@
 package pullrequest
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gildas/bitbucket-cli/cmd/branch"
 	"github.com/gildas/bitbucket-cli/cmd/common"
 	"github.com/gildas/bitbucket-cli/cmd/profile"
 	"github.com/gildas/bitbucket-cli/cmd/pullrequest/common"
 	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/gildas/go-core"
 	"github.com/gildas/go-errors"
 	"github.com/gildas/go-flags"
 	"github.com/gildas/go-logger"
@@ -14,6 +23,7 @@ import (
 var mergeCmd = &cobra.Command{
 	Use:               "merge [flags] <pullrequest-id>",
 	Short:             "merge a pullrequest by its <pullrequest-id>. If not provided, it will try to merge the only open pullrequest.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest:write"},
 	Args:              cobra.MaximumNArgs(1),
 	ValidArgsFunction: mergeValidArgs,
 	RunE:              mergeProcess,
@@ -24,17 +34,45 @@ var mergeOptions struct {
 	Message           string
 	MergeStrategy     *flags.EnumFlag
 	CloseSourceBranch bool
+	WhenReady         bool
+	Timeout           time.Duration
+	Interval          time.Duration
+	MinApprovals      int
+	Force             bool
+	Edit              bool
+	Bulk              bulkOptions
+}
+
+// mergePayload is the payload sent to Bitbucket to merge a pullrequest
+type mergePayload struct {
+	Message           string `json:"message,omitempty"`
+	CloseSourceBranch bool   `json:"close_source_branch"`
+	MergeStrategy     string `json:"merge_strategy"`
 }
 
+// mergeTaskPollInterval is the interval between two checks of an asynchronous merge task
+const mergeTaskPollInterval = 2 * time.Second
+
 func init() {
 	Command.AddCommand(mergeCmd)
 
 	mergeOptions.MergeStrategy = flags.NewEnumFlag("+merge_commit", "squash", "fast_forward")
-	mergeCmd.Flags().StringVar(&mergeOptions.Message, "message", "", "Message of the merge")
+	mergeCmd.Flags().StringVar(&mergeOptions.Message, "message", "", "Message of the merge. Default is the merge template of the merge strategy, if any")
+	mergeCmd.Flags().BoolVar(&mergeOptions.Edit, "edit", false, "Edit the merge message in $EDITOR before merging")
 	mergeCmd.Flags().BoolVar(&mergeOptions.CloseSourceBranch, "close-source-branch", false, "Close the source branch of the pullrequest")
 	mergeCmd.Flags().BoolVar(&mergeOptions.Async, "async", false, "Perform the merge asynchronously")
 	mergeCmd.Flags().Var(mergeOptions.MergeStrategy, "merge-strategy", "Merge strategy to use. Possible values are \"merge_commit\", \"squash\" or \"fast_forward\"")
+	mergeCmd.Flags().BoolVar(&mergeOptions.WhenReady, "when-ready", false, "Wait for the builds, approvals, tasks, and merge restrictions to pass before merging")
+	mergeCmd.Flags().DurationVar(&mergeOptions.Timeout, "timeout", 1*time.Hour, "How long to wait with --when-ready before giving up")
+	mergeCmd.Flags().DurationVar(&mergeOptions.Interval, "interval", 30*time.Second, "How often to check the pullrequest with --when-ready")
+	mergeCmd.Flags().IntVar(&mergeOptions.MinApprovals, "min-approvals", 1, "Minimum number of approvals with --when-ready, the merge restrictions of the destination branch can require more")
+	mergeCmd.Flags().BoolVar(&mergeOptions.Force, "force", false, "Merge even if conflicts are predicted")
+	mergeOptions.Bulk.addFlags(mergeCmd, "Merge")
 	_ = mergeCmd.RegisterFlagCompletionFunc(mergeOptions.MergeStrategy.CompletionFunc("merge-strategy"))
+	for _, filter := range mergeOptions.Bulk.Filter.flags {
+		mergeCmd.MarkFlagsMutuallyExclusive("when-ready", filter)
+		mergeCmd.MarkFlagsMutuallyExclusive("async", filter)
+	}
 }
 
 func mergeValidArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -63,6 +101,39 @@ func mergeProcess(cmd *cobra.Command, args []string) (err error) {
 		return errors.Join(errors.Errorf("Cannot merge Pull Request"), err)
 	}
 
+	payload := mergePayload{
+		Message:           mergeOptions.Message,
+		CloseSourceBranch: mergeOptions.CloseSourceBranch,
+		MergeStrategy:     mergeOptions.MergeStrategy.String(),
+	}
+
+	if mergeOptions.Bulk.isBulk(cmd) {
+		if len(args) > 0 {
+			return errors.ArgumentInvalid.With("pullrequest-id", "cannot be used with filters")
+		}
+		pullrequests, err := mergeOptions.Bulk.getPullRequests(log.ToContext(cmd.Context()), cmd, repository)
+		if err != nil {
+			return errors.Join(errors.Errorf("Cannot merge Pull Requests"), err)
+		}
+		return mergeOptions.Bulk.processBulk(log.ToContext(cmd.Context()), cmd, profile, "Merging", pullrequests, func(ctx context.Context, pullrequest PullRequest) error {
+			pullRequestID := strconv.FormatUint(pullrequest.ID, 10)
+			if err := checkConflicts(ctx, cmd, profile, repository, pullRequestID); err != nil {
+				return err
+			}
+			payload := payload
+			message, err := getMergeMessage(ctx, cmd, profile, repository, pullRequestID, payload.MergeStrategy)
+			if err != nil {
+				return err
+			}
+			payload.Message = message
+			var merged PullRequest
+			if err := profile.Post(ctx, cmd, repository.GetPath("pullrequests", pullRequestID, "merge"), payload, &merged); err != nil {
+				return errors.Join(errors.Errorf("Failed to merge Pull Request %d", pullrequest.ID), err)
+			}
+			return nil
+		})
+	}
+
 	pullRequestID, err := GetPullRequestIDFromArgs(cmd.Context(), cmd, repository, args)
 	if err != nil {
 		return errors.Join(errors.Errorf("Cannot merge Pull Request"), err)
@@ -74,21 +145,36 @@ func mergeProcess(cmd *cobra.Command, args []string) (err error) {
 		uripath += "?async=true"
 	}
 
-	payload := struct {
-		Message           string `json:"message,omitempty"`
-		CloseSourceBranch bool   `json:"close_source_branch"`
-		MergeStrategy     string `json:"merge_strategy"`
-	}{
-		Message:           mergeOptions.Message,
-		CloseSourceBranch: mergeOptions.CloseSourceBranch,
-		MergeStrategy:     mergeOptions.MergeStrategy.String(),
+	if mergeOptions.WhenReady {
+		if !common.WhatIf(log.ToContext(cmd.Context()), cmd, "Merging pullrequest %s when it is ready", pullRequestID) {
+			readiness, err := GetReadiness(log.ToContext(cmd.Context()), cmd, profile, repository, pullRequestID, mergeOptions.MinApprovals)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Dry run: pullrequest %s is %s\n", pullRequestID, readiness)
+			return nil
+		}
+		if err = waitUntilReady(log.ToContext(cmd.Context()), cmd, profile, repository, pullRequestID); err != nil {
+			return err
+		}
 	}
 
-	log.Record("payload", payload).Infof("Merging pullrequest %s", pullRequestID)
+	log.Infof("Merging pullrequest %s", pullRequestID)
 	if !common.WhatIf(log.ToContext(cmd.Context()), cmd, "Merging pullrequest %s", pullRequestID) {
 		return nil
 	}
 
+	// Predicting the conflicts fetches the branches, which is not done in a dry run
+	if err = checkConflicts(log.ToContext(cmd.Context()), cmd, profile, repository, pullRequestID); err != nil {
+		return err
+	}
+
+	// With --edit, the message is edited, which is not done in a dry run either
+	if payload.Message, err = getMergeMessage(log.ToContext(cmd.Context()), cmd, profile, repository, pullRequestID, payload.MergeStrategy); err != nil {
+		return err
+	}
+	log.Record("payload", payload).Debugf("Merge payload of pullrequest %s", pullRequestID)
+
 	if mergeOptions.Async {
 		result, err := profile.PostWithResult(log.ToContext(cmd.Context()), cmd, uripath, payload)
 		if err != nil {
@@ -99,6 +185,15 @@ func mergeProcess(cmd *cobra.Command, args []string) (err error) {
 			return errors.Join(errors.Errorf("Failed to get merge status for Pull Request %s", pullRequestID), err)
 		}
 		log.Infof("Merge request accepted, task ID: %s", status.ID)
+		if mergeOptions.WhenReady {
+			if status, err = followMergeTask(log.ToContext(cmd.Context()), cmd, profile, repository, pullRequestID, status.ID); err != nil {
+				return err
+			}
+			if err = profile.Print(cmd.Context(), cmd, status); err != nil {
+				return err
+			}
+			return status.GetError(pullRequestID)
+		}
 		return profile.Print(cmd.Context(), cmd, status)
 	} else {
 		var pullrequest PullRequest
@@ -110,3 +205,98 @@ func mergeProcess(cmd *cobra.Command, args []string) (err error) {
 		return profile.Print(cmd.Context(), cmd, pullrequest)
 	}
 }
+
+// checkConflicts predicts the conflicts of the pullrequest with the merge strategy, unless --force is used
+//
+// Returns an error with the common.ExitBlocked exit code if the pullrequest has conflicts.
+// If the conflicts cannot be predicted (e.g.: not in a git repository), a warning is shown and the merge can go on
+func checkConflicts(ctx context.Context, cmd *cobra.Command, currentProfile *profile.Profile, repository *repository.Repository, pullRequestID string) error {
+	log := logger.Must(logger.FromContext(ctx)).Child("pullrequest", "check_conflicts")
+
+	if mergeOptions.Force {
+		log.Infof("Not checking the conflicts of pullrequest %s (--force)", pullRequestID)
+		return nil
+	}
+	var pullrequest PullRequest
+	if err := currentProfile.Get(ctx, cmd, repository.GetPath("pullrequests", pullRequestID), &pullrequest); err != nil {
+		return errors.Join(errors.Errorf("Failed to get pullrequest %s", pullRequestID), err)
+	}
+	result, err := predictConflicts(ctx, cmd, currentProfile, repository, pullrequest, mergeOptions.MergeStrategy.String())
+	if err != nil {
+		log.Warnf("Failed to predict the conflicts of pullrequest %s: %s", pullRequestID, err)
+		fmt.Fprintf(os.Stderr, "Cannot predict the conflicts of pullrequest %s, merging anyway: %s\n", pullRequestID, err)
+		return nil
+	}
+	if result.IsClean() {
+		return nil
+	}
+	if len(result.Conflicts) == 0 {
+		return common.NewExitCodeError(common.ExitBlocked, errors.Errorf("Pullrequest %s cannot be fast-forwarded, use --force to merge anyway", pullRequestID))
+	}
+	paths := core.Map(result.Conflicts, func(conflict branch.MergeConflict) string { return conflict.Path })
+	return common.NewExitCodeError(common.ExitBlocked, errors.Errorf("Pullrequest %s has conflicts in %s, use --force to merge anyway", pullRequestID, strings.Join(paths, ", ")))
+}
+
+// waitUntilReady polls the pullrequest until it is ready to be merged
+//
+// Returns an error with the common.ExitBlocked exit code if the pullrequest is blocked,
+// or with the common.ExitTimedOut exit code if it is not ready before the timeout
+func waitUntilReady(ctx context.Context, cmd *cobra.Command, profile *profile.Profile, repository *repository.Repository, pullRequestID string) error {
+	log := logger.Must(logger.FromContext(ctx)).Child("pullrequest", "wait")
+	deadline := time.Now().Add(mergeOptions.Timeout)
+	last := ""
+
+	for {
+		readiness, err := GetReadiness(ctx, cmd, profile, repository, pullRequestID, mergeOptions.MinApprovals)
+		if err != nil {
+			return err
+		}
+		if readiness.IsBlocked() {
+			return common.NewExitCodeError(common.ExitBlocked, errors.Errorf("Pullrequest %s cannot be merged, it is %s", pullRequestID, readiness))
+		}
+		if readiness.IsReady() {
+			log.Infof("Pullrequest %s is ready to be merged", pullRequestID)
+			return nil
+		}
+		if status := readiness.String(); status != last {
+			fmt.Fprintf(os.Stderr, "Pullrequest %s is %s\n", pullRequestID, status)
+			last = status
+		}
+		if time.Now().Add(mergeOptions.Interval).After(deadline) {
+			return common.NewExitCodeError(common.ExitTimedOut, errors.Errorf("Pullrequest %s was not ready after %s, it is %s", pullRequestID, mergeOptions.Timeout, readiness))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(mergeOptions.Interval):
+		}
+	}
+}
+
+// followMergeTask polls an asynchronous merge task until it finishes
+//
+// The finished task is returned even if it failed, use its GetError method to check it
+func followMergeTask(ctx context.Context, cmd *cobra.Command, profile *profile.Profile, repository *repository.Repository, pullRequestID, taskID string) (*PullRequestMergeStatus, error) {
+	log := logger.Must(logger.FromContext(ctx)).Child("pullrequest", "follow")
+	deadline := time.Now().Add(mergeOptions.Timeout)
+
+	for {
+		var status PullRequestMergeStatus
+		if err := profile.Get(ctx, cmd, repository.GetPath("pullrequests", pullRequestID, "merge", "task-status", taskID), &status); err != nil {
+			return nil, errors.Join(errors.Errorf("Failed to get the merge status for Pull Request %s", pullRequestID), err)
+		}
+		status.ID = taskID
+		if status.Status != "PENDING" {
+			log.Infof("Merge task %s finished with %s", taskID, status.Status)
+			return &status, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, common.NewExitCodeError(common.ExitTimedOut, errors.Errorf("The merge task %s of pullrequest %s did not finish after %s", taskID, pullRequestID, mergeOptions.Timeout))
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(mergeTaskPollInterval):
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package pullrequest
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/gildas/bitbucket-cli/cmd/commit"
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/spf13/cobra"
+)
+
+// MergeMessageData is the data given to the merge message templates
+type MergeMessageData struct {
+	ID                uint64
+	Title             string
+	Description       string
+	Author            string
+	Approvers         []string
+	Commits           []string // the subjects of the commits, the oldest first
+	IssueKeys         []string // the issue keys found in the source branch name, e.g.: PROJ-123
+	SourceBranch      string
+	DestinationBranch string
+	Strategy          string
+}
+
+// issueKeyPattern matches issue keys like PROJ-123
+var issueKeyPattern = regexp.MustCompile(`\b[A-Z][A-Z0-9]+-[0-9]+\b`)
+
+// NewMergeMessageData creates the data of the merge message templates from a pullrequest and its commits
+func NewMergeMessageData(pullrequest PullRequest, commits []commit.Commit, strategy string) MergeMessageData {
+	data := MergeMessageData{
+		ID:                pullrequest.ID,
+		Title:             pullrequest.Title,
+		Description:       pullrequest.Description,
+		Author:            pullrequest.Author.Name,
+		Approvers:         []string{},
+		Commits:           make([]string, 0, len(commits)),
+		IssueKeys:         GetIssueKeys(pullrequest.Source.Branch.Name),
+		SourceBranch:      pullrequest.Source.Branch.Name,
+		DestinationBranch: pullrequest.Destination.Branch.Name,
+		Strategy:          strategy,
+	}
+	for _, participant := range pullrequest.Participants {
+		if participant.Approved {
+			data.Approvers = append(data.Approvers, participant.User.Name)
+		}
+	}
+	// Bitbucket gives the commits of a pullrequest the newest first
+	for index := len(commits) - 1; index >= 0; index-- {
+		subject, _, _ := strings.Cut(strings.TrimSpace(commits[index].Message), "\n")
+		data.Commits = append(data.Commits, strings.TrimSpace(subject))
+	}
+	return data
+}
+
+// GetIssueKeys gets the issue keys (like PROJ-123) found in a branch name, without duplicates
+func GetIssueKeys(branchName string) []string {
+	keys := []string{}
+	for _, key := range issueKeyPattern.FindAllString(branchName, -1) {
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// RenderMergeMessage renders a merge message template with the given data
+//
+// The templates use the text/template syntax, with the join function to join lists, e.g.: {{join .IssueKeys ", "}}
+func RenderMergeMessage(text string, data MergeMessageData) (string, error) {
+	parsed, err := template.New("merge").Funcs(template.FuncMap{"join": strings.Join}).Parse(text)
+	if err != nil {
+		return "", errors.Join(errors.Errorf("Invalid merge message template"), err)
+	}
+	var builder strings.Builder
+	if err := parsed.Execute(&builder, data); err != nil {
+		return "", errors.Join(errors.Errorf("Failed to render the merge message template"), err)
+	}
+	return strings.TrimSpace(builder.String()), nil
+}
+
+// getMergeTemplate gets the merge message template of the given strategy
+//
+// The template of the .bb.yml file of the repository wins over the template of the profile. Returns an empty string if there is none
+func getMergeTemplate(ctx context.Context, currentProfile *profile.Profile, strategy string) string {
+	if config, err := common.LoadRepositoryConfig(ctx); err == nil {
+		if text, found := config.MergeTemplates[strategy]; found {
+			return text
+		}
+	}
+	return currentProfile.MergeTemplates[strategy]
+}
+
+// getMergeMessage gets the message to merge the pullrequest with
+//
+// The message is --message if given, or the rendered merge template of the strategy.
+// With --edit, the message is edited before it is returned, an empty message cancels the merge.
+// As it may open an editor, it must be called after the dry run check
+func getMergeMessage(ctx context.Context, cmd *cobra.Command, currentProfile *profile.Profile, repository *repository.Repository, pullRequestID string, strategy string) (string, error) {
+	log := logger.Must(logger.FromContext(ctx)).Child("pullrequest", "merge_message")
+
+	message := mergeOptions.Message
+	if len(message) == 0 {
+		if text := getMergeTemplate(ctx, currentProfile, strategy); len(text) > 0 {
+			var pullrequest PullRequest
+			if err := currentProfile.Get(ctx, cmd, repository.GetPath("pullrequests", pullRequestID), &pullrequest); err != nil {
+				return "", errors.Join(errors.Errorf("Failed to get pullrequest %s", pullRequestID), err)
+			}
+			commits, err := getAllOf[commit.Commit](ctx, cmd, repository, pullRequestID, "commits")
+			if err != nil {
+				return "", err
+			}
+			if message, err = RenderMergeMessage(text, NewMergeMessageData(pullrequest, commits, strategy)); err != nil {
+				return "", err
+			}
+			log.Infof("Rendered the %s merge template of pullrequest %s", strategy, pullRequestID)
+		}
+	}
+	if mergeOptions.Edit {
+		text, err := common.EditText(ctx, "MERGE_MSG_"+pullRequestID+"_*.txt", message)
+		if err != nil {
+			return "", err
+		}
+		if message = strings.TrimSpace(text); len(message) == 0 {
+			return "", errors.Errorf("The merge message of pullrequest %s is empty, the merge is cancelled", pullRequestID)
+		}
+	}
+	return message, nil
+}
@@ -13,6 +13,7 @@ import (
 var mergeStatusCmd = &cobra.Command{
 	Use:               "merge-status <pull-request-id>",
 	Short:             "Get the status of a pull request merge task",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest"},
 	Args:              cobra.MaximumNArgs(1),
 	ValidArgsFunction: mergeStatusValidArgs,
 	RunE:              mergeStatusProcess,
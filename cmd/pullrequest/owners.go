@@ -0,0 +1,104 @@
+package pullrequest
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	prcommon "github.com/gildas/bitbucket-cli/cmd/pullrequest/common"
+	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/gildas/go-core"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/spf13/cobra"
+)
+
+var ownersCmd = &cobra.Command{
+	Use:   "owners [flags] <pullrequest-id>",
+	Short: "show the code owners of a pull request by its <pullrequest-id> and their approvals. If not provided, it will try to show the owners of the only open pullrequest.",
+	Long: `Show the code owners of the files changed by a pull request, and if they approved it.
+
+The owners come from the CODEOWNERS file of the destination branch (CODEOWNERS, .bitbucket/CODEOWNERS, .github/CODEOWNERS, or docs/CODEOWNERS).
+The groups (@@group or @workspace/group) must be declared with their members in the reviewer_groups of the .bb.yml file,
+as Bitbucket does not give the groups of a workspace. A group approved when one of its members approved.
+
+The command exits with 5 if some owners have not approved the pull request.
+The owners that cannot be resolved to workspace members (emails, unknown groups, or non-members) are reported but not checked.`,
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest"},
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: ownersValidArgs,
+	RunE:              ownersProcess,
+}
+
+func init() {
+	Command.AddCommand(ownersCmd)
+}
+
+func ownersValidArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	ids, err := prcommon.GetPullRequestIDsWithState(cmd.Context(), cmd, "OPEN")
+	if err != nil {
+		cobra.CompErrorln(err.Error())
+		return []string{}, cobra.ShellCompDirectiveError
+	}
+	return common.FilterValidArgs(ids, args, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func ownersProcess(cmd *cobra.Command, args []string) error {
+	log := logger.Must(logger.FromContext(cmd.Context())).Child(cmd.Parent().Name(), "owners")
+
+	currentProfile, err := profile.GetProfileFromCommand(cmd.Context(), cmd)
+	if err != nil {
+		return err
+	}
+
+	repository, err := repository.GetRepository(cmd.Context(), cmd)
+	if err != nil {
+		return errors.Join(errors.Errorf("Cannot show the code owners of the Pull Request"), err)
+	}
+
+	pullRequestID, err := GetPullRequestIDFromArgs(cmd.Context(), cmd, repository, args)
+	if err != nil {
+		return errors.Join(errors.Errorf("Cannot show the code owners of the Pull Request"), err)
+	}
+
+	log.Infof("Showing the code owners of pullrequest %s", pullRequestID)
+	if !common.WhatIf(log.ToContext(cmd.Context()), cmd, "Showing the code owners of pullrequest %s", pullRequestID) {
+		return nil
+	}
+
+	var pullrequest PullRequest
+	if err = currentProfile.Get(log.ToContext(cmd.Context()), cmd, repository.GetPath("pullrequests", pullRequestID), &pullrequest); err != nil {
+		return errors.Join(errors.Errorf("Failed to get pullrequest %s", pullRequestID), err)
+	}
+
+	owners, err := getCodeOwners(log.ToContext(cmd.Context()), cmd, currentProfile, repository, pullrequest)
+	if err != nil {
+		return errors.Join(errors.Errorf("Failed to get the code owners of pullrequest %s", pullRequestID), err)
+	}
+	if len(owners) == 0 {
+		fmt.Fprintf(os.Stderr, "The files of pullrequest %s have no code owner\n", pullRequestID)
+		return nil
+	}
+	if err := currentProfile.Print(cmd.Context(), cmd, owners); err != nil {
+		return err
+	}
+	// Unresolved owners can never approve, they would always fail the command
+	if unresolved := owners.GetUnresolved(); len(unresolved) > 0 {
+		names := core.Map(unresolved, func(owner CodeOwner) string { return owner.Owner })
+		fmt.Fprintf(os.Stderr, "Warning: %d code owners cannot be resolved to workspace members and are not checked: %s\n", len(unresolved), strings.Join(names, ", "))
+		if slices.ContainsFunc(names, func(name string) bool { return strings.HasPrefix(name, "@@") || strings.Contains(name, "/") }) {
+			fmt.Fprintf(os.Stderr, "The groups must be declared with their members in the reviewer_groups of the %s file\n", common.RepositoryConfigFilename)
+		}
+	}
+	if pending := owners.GetNotApproved(); len(pending) > 0 {
+		return common.NewExitCodeError(common.ExitNotApproved, errors.Errorf("%d of %d code owners have not approved pullrequest %s", len(pending), len(owners)-len(owners.GetUnresolved()), pullRequestID))
+	}
+	return nil
+}
@@ -17,6 +17,7 @@ import (
 var patchCmd = &cobra.Command{
 	Use:               "patch [flags] <pullrequest-id>",
 	Short:             "show the patch of a pull request by its <pullrequest-id>. If not provided, it will try to show the patch of the only open pullrequest.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest"},
 	Args:              cobra.MaximumNArgs(1),
 	ValidArgsFunction: validPatchArgs,
 	RunE:              patchProcess,
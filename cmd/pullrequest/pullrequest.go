@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -37,6 +38,7 @@ type PullRequest struct {
 	ClosedBy          user.User               `json:"closed_by"              mapstructure:"closed_by"`
 	Author            user.User               `json:"author"                 mapstructure:"author"`
 	Reviewers         []user.User             `json:"reviewers,omitempty"    mapstructure:"reviewers"`
+	Participants      []user.Participant      `json:"participants,omitempty" mapstructure:"participants"`
 	Reason            string                  `json:"reason"                 mapstructure:"reason"`
 	Destination       Endpoint                `json:"destination"            mapstructure:"destination"`
 	Source            Endpoint                `json:"source"                 mapstructure:"source"`
@@ -205,8 +207,14 @@ func (pullrequest PullRequest) String() string {
 }
 
 // GetPullRequestIDFromArgs gets the pullrequest ID from the command arguments or, if not provided, from the only open pullrequestA
+//
+// When running in Bitbucket Pipelines for a pull request, the ID of that pull request is used if no argument is provided
 func GetPullRequestIDFromArgs(ctx context.Context, cmd *cobra.Command, repository *repository.Repository, args []string) (pullRequestID string, err error) {
 	if len(args) == 0 {
+		if pullRequestID = common.GetPipelinesPullRequestID(); len(pullRequestID) > 0 {
+			logger.Must(logger.FromContext(ctx)).Debugf("Using pullrequest %s from Bitbucket Pipelines", pullRequestID)
+			return pullRequestID, nil
+		}
 		pullRequestIDs, err := prcommon.GetPullRequestIDsFromRepositoryWithState(cmd.Context(), cmd, repository, "OPEN")
 		if err != nil {
 			return "", err
@@ -225,6 +233,41 @@ func GetPullRequestIDFromArgs(ctx context.Context, cmd *cobra.Command, repositor
 	return args[0], nil
 }
 
+// GetOpenPullRequestForBranch gets the open pullrequest whose source is the given branch
+func GetOpenPullRequestForBranch(ctx context.Context, cmd *cobra.Command, repository *repository.Repository, branchName string) (*PullRequest, error) {
+	var query common.BBQL
+	query.Equals("source.branch.name", branchName).Equals("state", "OPEN")
+	pullrequests, err := profile.GetAll[PullRequest](ctx, cmd, repository.GetPath("pullrequests?q="+url.QueryEscape(query.String())))
+	if err != nil {
+		return nil, err
+	}
+	if len(pullrequests) == 0 {
+		return nil, errors.NotFound.With("pullrequest for branch", branchName)
+	}
+	return &pullrequests[0], nil
+}
+
+// FindUser finds a user by its Account ID, UUID, name, or nickname among the given workspace members
+//
+// If the user is not a member, it is fetched through the user package, which also understands "me"
+func FindUser(ctx context.Context, cmd *cobra.Command, members []workspace.Member, id string) (*user.User, error) {
+	isMember := func(member workspace.Member) bool {
+		if id, err := common.ParseUUID(id); err == nil {
+			return member.User.ID == id
+		}
+		return member.User.AccountID == id || strings.EqualFold(member.User.Nickname, id) || strings.EqualFold(member.User.Name, id)
+	}
+
+	if matches := core.Filter(members, isMember); len(matches) > 0 {
+		return &matches[0].User, nil
+	}
+	found, err := user.GetUser(ctx, cmd, id)
+	if err != nil || found == nil {
+		return nil, errors.Join(errors.NotFound.With("user", id), err)
+	}
+	return found, nil
+}
+
 // GetReviewerNicknames gets the reviewer nicknames for the current Workspace
 func GetReviewerNicknames(ctx context.Context, cmd *cobra.Command, args []string, toComplete string) (nicknames []string, err error) {
 	log := logger.Must(logger.FromContext(ctx)).Child(nil, "getreviewers")
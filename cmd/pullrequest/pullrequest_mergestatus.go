@@ -17,6 +17,13 @@ type PullRequestMergeStatus struct {
 	Status      string       `json:"task_status" mapstructure:"task_status"`
 	PullRequest PullRequest  `json:"merge_result" mapstructure:"merge_result"`
 	Links       common.Links `json:"links"       mapstructure:"links"`
+	Error       *MergeError  `json:"error,omitempty" mapstructure:"error"`
+}
+
+// MergeError is the error of a merge task that failed
+type MergeError struct {
+	Message string `json:"message"          mapstructure:"message"`
+	Detail  string `json:"detail,omitempty" mapstructure:"detail"`
 }
 
 // NewPullRequestMergeStatusFromLocation creates a new PullRequestMergeStatus from a URL location
@@ -39,6 +46,23 @@ func NewPullRequestMergeStatusFromLocation(location string) (mergeStatus *PullRe
 	return &PullRequestMergeStatus{ID: taskID, PullRequest: PullRequest{ID: uint64(pullrequestID)}}, nil
 }
 
+// GetError gets the error of a finished merge task
+//
+// Returns nil if the task succeeded, an error with the common.ExitMergeFailed exit code otherwise
+func (status PullRequestMergeStatus) GetError(pullRequestID string) error {
+	if status.Status == "SUCCESS" {
+		return nil
+	}
+	message := status.Status
+	if status.Error != nil && len(status.Error.Message) > 0 {
+		message = status.Error.Message
+		if len(status.Error.Detail) > 0 {
+			message += ": " + status.Error.Detail
+		}
+	}
+	return common.NewExitCodeError(common.ExitMergeFailed, errors.Errorf("The merge task %s of pullrequest %s failed: %s", status.ID, pullRequestID, message))
+}
+
 // GetHeaders gets the header for a table
 //
 // implements common.Tableable
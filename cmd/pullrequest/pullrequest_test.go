@@ -1,17 +1,30 @@
 package pullrequest_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
 
+	"github.com/gildas/bitbucket-cli/cmd/commit"
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/bitbucket-cli/cmd/pipeline"
+	"github.com/gildas/bitbucket-cli/cmd/profile"
 	"github.com/gildas/bitbucket-cli/cmd/pullrequest"
+	"github.com/gildas/bitbucket-cli/cmd/pullrequest/comment"
+	"github.com/gildas/bitbucket-cli/cmd/user"
+	"github.com/gildas/go-errors"
 	"github.com/gildas/go-logger"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -149,3 +162,565 @@ func (suite *PullRequestSuite) TestShouldFailCreatingPullRequestMergeStatusWithS
 	suite.Assert().Nil(mergeStatus)
 	suite.T().Logf("Expected error: %s", err.Error())
 }
+
+func (suite *PullRequestSuite) TestCanPrintDashboardAsTable() {
+	dashboard := pullrequest.Dashboard{
+		Current: &pullrequest.StatusEntry{Section: pullrequest.StatusSectionCurrent, Repository: "myself/repo", Approvals: 1, Builds: "SUCCESSFUL"},
+		Authored: []pullrequest.StatusEntry{
+			{Section: pullrequest.StatusSectionAuthored, Repository: "myself/repo", ChangesRequested: 1, Builds: "FAILED", Conflicts: true},
+		},
+		Reviewing: []pullrequest.StatusEntry{
+			{Section: pullrequest.StatusSectionReviewing, Repository: "myself/other"},
+		},
+	}
+	suite.Require().Equal(3, dashboard.Size())
+	headers := dashboard.GetHeaders(nil)
+	suite.Assert().Equal([]string{pullrequest.StatusSectionCurrent, "myself/repo", "0", "", " → ", "1 approved", "passed", "no"}, dashboard.GetRowAt(0, headers))
+	suite.Assert().Equal([]string{pullrequest.StatusSectionAuthored, "myself/repo", "0", "", " → ", "none, 1 changes requested", "failed", "yes"}, dashboard.GetRowAt(1, headers))
+	suite.Assert().Equal([]string{pullrequest.StatusSectionReviewing, "myself/other", "0", "", " → ", "none", "none", "no"}, dashboard.GetRowAt(2, headers))
+	suite.Assert().Empty(dashboard.GetRowAt(3, headers))
+}
+
+func (suite *PullRequestSuite) TestCanSummarizeChecks() {
+	checks := pullrequest.Checks{
+		{Status: commit.Status{Key: "lint", State: "SUCCESSFUL"}},
+		{Status: commit.Status{Key: "build", Name: "Pipeline #42", State: "INPROGRESS", URL: "https://bitbucket.org/myworkspace/myrepo/addon/pipelines/home#!/results/42"}, Pipeline: &pipeline.Pipeline{BuildNumber: 42, Duration: 90 * time.Second}},
+	}
+	suite.Assert().False(checks.IsFinished())
+	suite.Assert().Equal(commit.StatusInProgress, checks.State())
+	suite.Assert().Equal("1 successful, 1 inprogress", checks.String())
+	suite.Assert().Equal([]string{"lint", "SUCCESSFUL", "0s", ""}, checks.GetRowAt(0, checks.GetHeaders(nil)))
+	suite.Assert().Equal([]string{"Pipeline #42", "INPROGRESS", "1m30s", checks[1].Status.URL}, checks.GetRowAt(1, checks.GetHeaders(nil)))
+
+	checks[1].Status.State = "FAILED"
+	suite.Assert().True(checks.IsFinished())
+	suite.Assert().Equal(commit.StatusFailed, checks.State())
+	suite.Assert().Equal("no builds", pullrequest.Checks{}.String())
+}
+
+func (suite *PullRequestSuite) TestCanPrintStackAsTable() {
+	stack := pullrequest.Stack{
+		{Branch: "feature-1", PullRequest: pullrequest.PullRequest{ID: 12, Title: "Part 1", State: "OPEN", Destination: pullrequest.Endpoint{Branch: pullrequest.Branch{Name: "main"}}}},
+		{Branch: "feature-2", PullRequest: pullrequest.PullRequest{ID: 13, Title: "Part 2", State: "OPEN", Destination: pullrequest.Endpoint{Branch: pullrequest.Branch{Name: "feature-1"}}}},
+	}
+	suite.Assert().Equal("main", stack.Base())
+	suite.Require().Equal(2, stack.Size())
+	suite.Assert().Equal([]string{"2", "feature-2", "feature-1", "13", "OPEN", "Part 2"}, stack.GetRowAt(1, stack.GetHeaders(nil)))
+	suite.Assert().Empty(pullrequest.Stack{}.Base())
+}
+
+func (suite *PullRequestSuite) TestCanBuildStack() {
+	created := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	newPullRequest := func(id uint64, source, destination string, age int) pullrequest.PullRequest {
+		return pullrequest.PullRequest{
+			ID:          id,
+			Source:      pullrequest.Endpoint{Branch: pullrequest.Branch{Name: source}},
+			Destination: pullrequest.Endpoint{Branch: pullrequest.Branch{Name: destination}},
+			CreatedOn:   created.Add(-time.Duration(age) * time.Hour),
+		}
+	}
+	branches := func(stack pullrequest.Stack) []string {
+		names := []string{}
+		for _, entry := range stack {
+			names = append(names, entry.Branch)
+		}
+		return names
+	}
+
+	linear := []pullrequest.PullRequest{
+		newPullRequest(13, "feature-3", "feature-2", 1),
+		newPullRequest(12, "feature-2", "feature-1", 2),
+		newPullRequest(11, "feature-1", "main", 3),
+		newPullRequest(20, "other", "main", 4),
+	}
+	for _, branchName := range []string{"feature-1", "feature-2", "feature-3"} {
+		suite.Assert().Equal([]string{"feature-1", "feature-2", "feature-3"}, branches(pullrequest.BuildStack(linear, branchName, "main")), "from %s", branchName)
+	}
+	suite.Assert().Equal("main", pullrequest.BuildStack(linear, "feature-2", "main").Base())
+	suite.Assert().Empty(pullrequest.BuildStack(linear, "unknown", "main"))
+
+	// feature-1 is the destination of 2 pullrequests, the oldest one is followed
+	fork := []pullrequest.PullRequest{
+		newPullRequest(14, "feature-2b", "feature-1", 1),
+		newPullRequest(12, "feature-2a", "feature-1", 2),
+		newPullRequest(11, "feature-1", "main", 3),
+	}
+	suite.Assert().Equal([]string{"feature-1", "feature-2a"}, branches(pullrequest.BuildStack(fork, "feature-1", "main")))
+	suite.Assert().Equal([]string{"feature-1", "feature-2b"}, branches(pullrequest.BuildStack(fork, "feature-2b", "main")))
+
+	// feature-1 was merged, its stack now starts at feature-2
+	merged := []pullrequest.PullRequest{
+		newPullRequest(13, "feature-3", "feature-2", 1),
+		newPullRequest(12, "feature-2", "feature-1", 2),
+	}
+	stack := pullrequest.BuildStack(merged, "feature-3", "main")
+	suite.Assert().Equal([]string{"feature-2", "feature-3"}, branches(stack))
+	suite.Assert().Equal("feature-1", stack.Base())
+
+	// a cycle does not loop forever
+	cycle := []pullrequest.PullRequest{
+		newPullRequest(1, "a", "b", 1),
+		newPullRequest(2, "b", "a", 2),
+	}
+	suite.Assert().Len(pullrequest.BuildStack(cycle, "a", "main"), 2)
+}
+
+func (suite *PullRequestSuite) TestCanFindMergedBase() {
+	merges := map[string]string{"feature-1": "release", "release": "main"}
+	getMerged := func(branchName string) ([]pullrequest.PullRequest, error) {
+		if destination, found := merges[branchName]; found {
+			return []pullrequest.PullRequest{{ID: 1, Destination: pullrequest.Endpoint{Branch: pullrequest.Branch{Name: destination}}}}, nil
+		}
+		return []pullrequest.PullRequest{}, nil
+	}
+
+	base, err := pullrequest.FindMergedBase("feature-1", "main", getMerged)
+	suite.Require().NoError(err)
+	suite.Assert().Equal("main", base)
+
+	base, err = pullrequest.FindMergedBase("feature-2", "main", getMerged)
+	suite.Require().NoError(err)
+	suite.Assert().Equal("feature-2", base, "a branch that was not merged stays the base")
+
+	merges = map[string]string{"feature-1": "release", "release": "feature-1"}
+	base, err = pullrequest.FindMergedBase("feature-1", "main", getMerged)
+	suite.Require().NoError(err)
+	suite.Assert().Equal("feature-1", base, "a cycle of merges stops")
+
+	_, err = pullrequest.FindMergedBase("feature-1", "main", func(string) ([]pullrequest.PullRequest, error) { return nil, errors.HTTPNotFound })
+	suite.Require().Error(err)
+}
+
+func (suite *PullRequestSuite) TestCanPlanStackRebase() {
+	stack := pullrequest.Stack{{Branch: "feature-2"}, {Branch: "feature-3"}, {Branch: "feature-4"}}
+	head2 := plumbing.NewHash("2222222222222222222222222222222222222222")
+	head3 := plumbing.NewHash("3333333333333333333333333333333333333333")
+
+	plan, missing := pullrequest.PlanStackRebase(stack, map[string]plumbing.Hash{"feature-2": head2, "feature-3": head3}, "origin/main", "1111111111111111111111111111111111111111")
+	suite.Assert().Equal("feature-4", missing)
+	suite.Assert().Equal([]pullrequest.StackRebase{
+		{Branch: "feature-2", Onto: "origin/main", Upstream: "1111111111111111111111111111111111111111"},
+		{Branch: "feature-3", Onto: "feature-2", Upstream: head2.String()},
+	}, plan)
+
+	plan, missing = pullrequest.PlanStackRebase(stack, map[string]plumbing.Hash{"feature-3": head3}, "origin/main", "")
+	suite.Assert().Equal("feature-2", missing)
+	suite.Assert().Empty(plan)
+}
+
+func (suite *PullRequestSuite) TestCanRenderConversation() {
+	start := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	comments := comment.Comments{
+		{ID: 3, User: user.User{Name: "Jane"}, Content: common.RenderedText{Raw: "Done in `next` commit"}, Parent: &comment.Comment{ID: 1}, CreatedOn: start.Add(2 * time.Hour)},
+		{ID: 1, User: user.User{Name: "John"}, Content: common.RenderedText{Raw: "Please **rename** this"}, Anchor: &common.FileAnchor{Path: "main.go", To: 3}, CreatedOn: start},
+		{ID: 2, User: user.User{Name: "Jane"}, Content: common.RenderedText{Raw: "Looks good"}, CreatedOn: start.Add(time.Hour), Resolution: &comment.Resolution{User: user.User{Name: "John"}}},
+	}
+	threads := comments.Threads()
+	suite.Require().Len(threads, 2)
+	suite.Assert().Equal(1, threads[0].Comment.ID)
+	suite.Require().Len(threads[0].Replies, 1)
+	suite.Assert().Equal(3, threads[0].Replies[0].Comment.ID)
+	suite.Assert().Equal("open", threads[0].Status())
+	suite.Assert().Equal("resolved", threads[1].Status())
+	suite.Require().Len(threads.Unresolved(), 1)
+
+	diff, err := common.ParseDiff(strings.NewReader("diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1,3 +1,3 @@\n package main\n-func foo() {}\n+func bar() {}\n func main() {}\n"))
+	suite.Require().NoError(err)
+	var output strings.Builder
+	err = threads.Unresolved().Render(&output, diff, comment.ThreadRenderOptions{Context: 1})
+	suite.Require().NoError(err)
+	expected := `● main.go:3 [open]
+     2 +func bar() {}
+▶    3  func main() {}
+  John · 2026-01-15 10:00
+    Please rename this
+    ↳ Jane · 2026-01-15 12:00
+      Done in next commit
+`
+	suite.Assert().Equal(expected, output.String())
+}
+
+func (suite *PullRequestSuite) TestCanWriteReports() {
+	reports := pullrequest.Reports{
+		ExportedOn: time.Date(2026, 2, 1, 8, 0, 0, 0, time.UTC),
+		Reports: []pullrequest.Report{{
+			Repository: "myworkspace/myrepo",
+			PullRequest: pullrequest.PullRequest{
+				ID: 42, Title: "Add <export>", State: "MERGED",
+				Author:       user.User{Name: "John"},
+				Source:       pullrequest.Endpoint{Branch: pullrequest.Branch{Name: "feature"}},
+				Destination:  pullrequest.Endpoint{Branch: pullrequest.Branch{Name: "main"}},
+				Participants: []user.Participant{{User: user.User{Name: "Jane"}, Role: "REVIEWER", Approved: true}},
+			},
+			Comments:  []comment.Comment{{ID: 1, User: user.User{Name: "Jane"}, Content: common.RenderedText{Raw: "Nice"}}},
+			DiffStats: []pullrequest.DiffStat{{Status: "modified", LinesAdded: 3, LinesRemoved: 1, New: &pullrequest.DiffStatFile{Path: "main.go"}}},
+			Statuses:  []commit.Status{{Key: "build", State: "SUCCESSFUL"}},
+		}},
+	}
+	suite.Assert().Equal("Pull Request #42: Add <export>", reports.Title())
+
+	var markdown strings.Builder
+	suite.Require().NoError(reports.WriteMarkdown(&markdown))
+	suite.Assert().Contains(markdown.String(), "# Pull Request #42: Add <export>\n\nExported on 2026-02-01T08:00:00Z\n")
+	suite.Assert().Contains(markdown.String(), "| Branches | feature → main |\n")
+	suite.Assert().Contains(markdown.String(), "| Jane | reviewer | approved |  |\n")
+	suite.Assert().Contains(markdown.String(), "| build | SUCCESSFUL |")
+	suite.Assert().Contains(markdown.String(), "| main.go | modified | 3 | 1 |\n")
+	suite.Assert().Contains(markdown.String(), "- **Jane**, 0001-01-01T00:00:00Z [open]\n  > Nice\n")
+	suite.Assert().Contains(markdown.String(), "### Tasks\n\nNo tasks\n")
+
+	var html strings.Builder
+	suite.Require().NoError(reports.WriteHTML(&html))
+	suite.Assert().Contains(html.String(), "<h2>#42 Add &lt;export&gt;</h2>")
+	suite.Assert().Contains(html.String(), "<td>Jane</td><td>reviewer</td><td>approved</td>")
+	suite.Assert().Contains(html.String(), "<pre>Nice</pre>")
+
+	var payload strings.Builder
+	suite.Require().NoError(reports.WriteJSON(&payload))
+	var decoded map[string]any
+	suite.Require().NoError(json.Unmarshal([]byte(payload.String()), &decoded))
+	suite.Assert().Len(decoded["pullrequests"], 1)
+}
+
+func (suite *PullRequestSuite) TestCanGetMergedQuery() {
+	now := time.Date(2026, 4, 15, 12, 0, 0, 0, time.UTC)
+
+	query, err := pullrequest.GetMergedQuery("2026-03-01", "2026-03-31", now)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(`state = "MERGED" AND updated_on > 2026-03-01T00:00:00Z AND updated_on < 2026-04-01T00:00:00Z`, query.String())
+
+	query, err = pullrequest.GetMergedQuery("2026-03-01", "2026-03-31T12:00:00Z", now)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(`state = "MERGED" AND updated_on > 2026-03-01T00:00:00Z AND updated_on < 2026-03-31T12:00:00Z`, query.String())
+
+	query, err = pullrequest.GetMergedQuery("7d", "", now)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(`state = "MERGED" AND updated_on > 2026-04-08T12:00:00Z`, query.String())
+
+	_, err = pullrequest.GetMergedQuery("2026-03-01", "someday", now)
+	suite.Require().Error(err)
+}
+
+func (suite *PullRequestSuite) TestCanFollowActivities() {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	approval := func(name string, minutes int) pullrequest.Activity {
+		return pullrequest.Activity{
+			PullRequest: pullrequest.PullRequestReference{ID: 7},
+			Approval:    &pullrequest.ActivityApproval{Date: start.Add(time.Duration(minutes) * time.Minute), User: user.User{Name: name}},
+		}
+	}
+	reply := pullrequest.Activity{
+		PullRequest: pullrequest.PullRequestReference{ID: 7},
+		Comment:     &comment.Comment{ID: 12, User: user.User{Name: "Jane"}, CreatedOn: start.Add(3 * time.Minute), Content: common.RenderedText{Raw: "Looks good"}},
+	}
+
+	follower := pullrequest.NewActivityFollower([]pullrequest.Activity{approval("john", 1)})
+	suite.Assert().Empty(follower.Next([]pullrequest.Activity{approval("john", 1)}))
+
+	// Bitbucket sends the newest first
+	next := follower.Next([]pullrequest.Activity{reply, approval("jane", 2), approval("john", 1)})
+	suite.Require().Len(next, 2)
+	suite.Assert().Equal("approval", next[0].GetType())
+	suite.Assert().Equal("comment", next[1].GetType())
+	suite.Assert().Empty(follower.Next([]pullrequest.Activity{reply, approval("jane", 2)}))
+
+	hook, err := pullrequest.ParseActivityHook("comment=notify-send \"$BB_EVENT_SUMMARY\"")
+	suite.Require().NoError(err)
+	suite.Assert().True(hook.Matches(reply))
+	suite.Assert().False(hook.Matches(approval("jane", 2)))
+	_, err = pullrequest.ParseActivityHook("merge=echo")
+	suite.Assert().Error(err)
+	_, err = pullrequest.ParseActivityHook("approval")
+	suite.Assert().Error(err)
+}
+
+func (suite *PullRequestSuite) TestCanSummarizeBulkResults() {
+	results := pullrequest.BulkResults{
+		{ID: 12, Title: "Stale work", Status: "succeeded"},
+		{ID: 15, Title: "Old experiment", Status: "failed", Error: "Conflict"},
+		{ID: 18, Title: "Forgotten", Status: "succeeded"},
+	}
+	suite.Assert().Equal("Declining: 2 succeeded, 1 failed (#15)", results.Summary("Declining"))
+	suite.Assert().Equal(3, results.Size())
+	headers := results.GetHeaders(nil)
+	suite.Assert().Equal([]string{"15", "Old experiment", "failed", "Conflict"}, results.GetRowAt(1, headers))
+	suite.Assert().Equal("Merging: 0 succeeded, 0 failed", pullrequest.BulkResults{}.Summary("Merging"))
+}
+
+func (suite *PullRequestSuite) TestCanConfirm() {
+	answers := map[string]bool{
+		"y\n":     true,
+		"Yes\n":   true,
+		" YES \n": true,
+		"y":       true, // EOF without a newline
+		"n\n":     false,
+		"no\n":    false,
+		"\n":      false,
+		"":        false, // EOF
+		"maybe\n": false,
+	}
+	for answer, expected := range answers {
+		var question strings.Builder
+		confirmed, err := pullrequest.Confirm(strings.NewReader(answer), &question, "Declining these 2 pullrequests?")
+		suite.Require().NoError(err, "answer %q", answer)
+		suite.Assert().Equal(expected, confirmed, "answer %q", answer)
+		suite.Assert().Equal("Declining these 2 pullrequests? [y/N] ", question.String())
+	}
+
+	_, err := pullrequest.Confirm(iotest.ErrReader(errors.New("broken pipe")), io.Discard, "Declining?")
+	suite.Require().Error(err)
+}
+
+func (suite *PullRequestSuite) TestCanWritePreview() {
+	var preview strings.Builder
+	pullrequest.WritePreview(&preview, []pullrequest.PullRequest{{
+		ID:          12,
+		Title:       "Stale work",
+		Author:      user.User{Name: "John"},
+		Source:      pullrequest.Endpoint{Branch: pullrequest.Branch{Name: "feature"}},
+		Destination: pullrequest.Endpoint{Branch: pullrequest.Branch{Name: "main"}},
+		UpdatedOn:   time.Date(2026, 1, 15, 10, 30, 0, 0, time.Local),
+	}})
+	lines := strings.Split(strings.TrimSuffix(preview.String(), "\n"), "\n")
+	suite.Require().Len(lines, 2)
+	suite.Assert().Equal([]string{"ID", "Title", "Author", "Branches", "Updated"}, strings.Fields(lines[0]))
+	suite.Assert().Equal("12  Stale work  John    feature → main  2026-01-15 10:30", lines[1])
+}
+
+func (suite *PullRequestSuite) TestCanRunBulk() {
+	ctx := suite.Logger.ToContext(context.Background())
+	pullrequests := []pullrequest.PullRequest{{ID: 12, Title: "First"}, {ID: 15, Title: "Fails"}, {ID: 18, Title: "Last"}}
+	newCommand := func(flag string) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().Bool("stop-on-error", false, "")
+		cmd.Flags().Bool("warn-on-error", false, "")
+		cmd.Flags().Bool("ignore-errors", false, "")
+		if len(flag) > 0 {
+			suite.Require().NoError(cmd.Flags().Set(flag, "true"))
+		}
+		return cmd
+	}
+	run := func(cmd *cobra.Command, currentProfile *profile.Profile) (pullrequest.BulkResults, []uint64, error) {
+		processed := []uint64{}
+		results, err := pullrequest.RunBulk(ctx, cmd, currentProfile, "Declining", pullrequests, func(ctx context.Context, pullrequest pullrequest.PullRequest) error {
+			processed = append(processed, pullrequest.ID)
+			if pullrequest.ID == 15 {
+				return errors.HTTPBadRequest.With("pullrequest", "15")
+			}
+			return nil
+		})
+		return results, processed, err
+	}
+
+	results, processed, err := run(newCommand(""), &profile.Profile{})
+	suite.Require().Error(err, "The profile stops on error by default")
+	suite.Assert().ErrorIs(err, errors.HTTPBadRequest)
+	suite.Assert().Equal([]uint64{12, 15}, processed)
+	suite.Assert().Equal("Declining: 1 succeeded, 1 failed (#15)", results.Summary("Declining"))
+
+	results, processed, err = run(newCommand("warn-on-error"), &profile.Profile{})
+	suite.Require().NoError(err)
+	suite.Assert().Equal([]uint64{12, 15, 18}, processed)
+	suite.Assert().Equal("Declining: 2 succeeded, 1 failed (#15)", results.Summary("Declining"))
+	suite.Assert().Equal("failed", results[1].Status)
+	suite.Assert().NotEmpty(results[1].Error)
+
+	results, processed, err = run(newCommand("ignore-errors"), &profile.Profile{})
+	suite.Require().NoError(err)
+	suite.Assert().Equal([]uint64{12, 15, 18}, processed)
+	suite.Assert().Len(results, 3)
+
+	_, processed, err = run(newCommand(""), &profile.Profile{ErrorProcessing: common.IgnoreErrors})
+	suite.Require().NoError(err, "The profile can ignore errors")
+	suite.Assert().Equal([]uint64{12, 15, 18}, processed)
+
+	_, processed, err = run(newCommand("stop-on-error"), &profile.Profile{ErrorProcessing: common.WarnOnError})
+	suite.Require().Error(err, "--stop-on-error overrides the profile")
+	suite.Assert().Equal([]uint64{12, 15}, processed)
+}
+
+func (suite *PullRequestSuite) TestCanSelectReviewers() {
+	candidate := func(uuid, name string, load int) pullrequest.ReviewerCandidate {
+		id, err := common.ParseUUID(uuid)
+		suite.Require().NoError(err)
+		return pullrequest.ReviewerCandidate{User: user.User{ID: id, Name: name}, Load: load}
+	}
+	candidates := []pullrequest.ReviewerCandidate{
+		candidate("{33333333-3333-3333-3333-333333333333}", "Carol", 0),
+		candidate("{11111111-1111-1111-1111-111111111111}", "Alice", 4),
+		candidate("{22222222-2222-2222-2222-222222222222}", "Bob", 1),
+	}
+	names := func(users []user.User) []string {
+		result := []string{}
+		for _, user := range users {
+			result = append(result, user.Name)
+		}
+		return result
+	}
+
+	suite.Assert().Equal([]string{"Carol", "Alice", "Bob"}, names(pullrequest.SelectReviewers(candidates, "all", 1, 0)))
+	suite.Assert().Equal([]string{"Alice"}, names(pullrequest.SelectReviewers(candidates, "round-robin", 1, 0)))
+	suite.Assert().Equal([]string{"Bob", "Carol"}, names(pullrequest.SelectReviewers(candidates, "round-robin", 2, 1)))
+	suite.Assert().Equal([]string{"Carol", "Alice"}, names(pullrequest.SelectReviewers(candidates, "round-robin", 2, 5)))
+	suite.Assert().Equal([]string{"Carol", "Bob"}, names(pullrequest.SelectReviewers(candidates, "least-loaded", 2, 0)))
+	suite.Assert().Equal([]string{"Carol", "Bob", "Alice"}, names(pullrequest.SelectReviewers(candidates, "least-loaded", 5, 0)))
+}
+
+func (suite *PullRequestSuite) TestCanRenderMergeMessage() {
+	pr := pullrequest.PullRequest{
+		ID:           42,
+		Title:        "Add login page",
+		Author:       user.User{Name: "Alice"},
+		Participants: []user.Participant{{User: user.User{Name: "Bob"}, Approved: true}, {User: user.User{Name: "Carol"}}},
+		Source:       pullrequest.Endpoint{Branch: pullrequest.Branch{Name: "feature/PROJ-12-PROJ-7-login"}},
+		Destination:  pullrequest.Endpoint{Branch: pullrequest.Branch{Name: "main"}},
+	}
+	commits := []commit.Commit{{Message: "Add the form\n\nWith validation"}, {Message: "Add the route"}}
+	data := pullrequest.NewMergeMessageData(pr, commits, "squash")
+	suite.Assert().Equal([]string{"PROJ-12", "PROJ-7"}, data.IssueKeys)
+	suite.Assert().Equal([]string{"Add the route", "Add the form"}, data.Commits, "The commits should be the oldest first")
+
+	message, err := pullrequest.RenderMergeMessage("{{join .IssueKeys \", \"}}: {{.Title}} (#{{.ID}})\n\n{{range .Commits}}* {{.}}\n{{end}}\nApproved-by: {{join .Approvers \", \"}}\n", data)
+	suite.Require().NoError(err)
+	suite.Assert().Equal("PROJ-12, PROJ-7: Add login page (#42)\n\n* Add the route\n* Add the form\n\nApproved-by: Bob", message)
+
+	_, err = pullrequest.RenderMergeMessage("{{.Unknown}}", data)
+	suite.Assert().Error(err)
+}
+
+func (suite *PullRequestSuite) TestCanParseCodeOwners() {
+	file, err := pullrequest.ParseCodeOwners(strings.NewReader(`# Owners of the repository
+* @alice
+
+[Documentation]
+docs/ @@writers # the writers group
+*.go @bob @acme/backend
+/cmd/profile/ @carol
+cmd/profile/vault.go
+`))
+	suite.Require().NoError(err)
+	suite.Require().Len(file.Rules, 5)
+	suite.Assert().Equal(7, file.Rules[3].Line)
+
+	suite.Assert().Equal([]string{"@alice"}, file.GetOwners("README.md"))
+	suite.Assert().Equal([]string{"@@writers"}, file.GetOwners("docs/guide/index.md"))
+	suite.Assert().Equal([]string{"@bob", "@acme/backend"}, file.GetOwners("cmd/root.go"))
+	suite.Assert().Equal([]string{"@carol"}, file.GetOwners("cmd/profile/profile.go"), "The last matching rule should win")
+	suite.Assert().Empty(file.GetOwners("cmd/profile/vault.go"), "A rule without owners should remove the owners")
+
+	owners := file.GetCodeOwners([]string{"main.go", "docs/index.md", "cmd/root.go"})
+	suite.Require().Len(owners, 3)
+	suite.Assert().Equal("@bob", owners[0].Owner)
+	suite.Assert().Equal([]string{"main.go", "cmd/root.go"}, owners[0].Files)
+	suite.Assert().Equal("@acme/backend", owners[1].Owner)
+	suite.Assert().Equal("@@writers", owners[2].Owner)
+	suite.Assert().False(owners[0].IsApproved())
+}
+
+func (suite *PullRequestSuite) TestCanComputePullRequestStats() {
+	newUser := func(uuid, name string) user.User {
+		id, err := common.ParseUUID(uuid)
+		suite.Require().NoError(err)
+		return user.User{ID: id, Name: name}
+	}
+	alice := newUser("{11111111-1111-1111-1111-111111111111}", "Alice")
+	bob := newUser("{22222222-2222-2222-2222-222222222222}", "Bob")
+	created := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	update := func(hours int, hash, state string) pullrequest.Activity {
+		return pullrequest.Activity{Update: &pullrequest.ActivityUpdate{
+			Date:   created.Add(time.Duration(hours) * time.Hour),
+			State:  state,
+			Author: alice,
+			Source: pullrequest.Endpoint{Commit: &commit.CommitReference{Hash: hash}},
+		}}
+	}
+	activities := []pullrequest.Activity{
+		{Approval: &pullrequest.ActivityApproval{Date: created.Add(30 * time.Hour), User: bob}},
+		update(0, "aaa", "OPEN"),
+		{Comment: &comment.Comment{User: alice, CreatedOn: created.Add(1 * time.Hour)}},
+		{Comment: &comment.Comment{User: bob, CreatedOn: created.Add(4 * time.Hour)}},
+		update(6, "bbb", "OPEN"),
+		update(32, "bbb", "MERGED"),
+	}
+	pr := pullrequest.PullRequest{ID: 7, Title: "Add stats", State: "MERGED", Author: alice, CreatedOn: created}
+	diffstats := []pullrequest.DiffStat{{LinesAdded: 10, LinesRemoved: 2}, {LinesAdded: 5}}
+
+	stats := pullrequest.NewPullRequestStats(pr, activities, diffstats)
+	suite.Require().NotNil(stats.HoursToFirstReview)
+	suite.Assert().Equal(4.0, *stats.HoursToFirstReview, "The comments of the author are not reviews")
+	suite.Require().NotNil(stats.HoursToApproval)
+	suite.Assert().Equal(30.0, *stats.HoursToApproval)
+	suite.Require().NotNil(stats.HoursToMerge)
+	suite.Assert().Equal(32.0, *stats.HoursToMerge)
+	suite.Assert().Equal(2, stats.ReviewRounds, "The approval after the new commits is a second round")
+	suite.Assert().Equal(2, stats.Files)
+	suite.Assert().Equal(uint64(15), stats.LinesAdded)
+
+	declined := pullrequest.NewPullRequestStats(pullrequest.PullRequest{ID: 8, State: "DECLINED", Author: alice, CreatedOn: created}, nil, nil)
+	suite.Assert().Nil(declined.HoursToFirstReview)
+	suite.Assert().Equal(0, declined.ReviewRounds)
+
+	summaries := pullrequest.PullRequestsStats{stats, declined}.Summarize(func(stats pullrequest.PullRequestStats) string { return stats.Author })
+	suite.Require().Len(summaries, 1)
+	suite.Assert().Equal(2, summaries[0].PullRequests)
+	suite.Assert().Equal(0.5, summaries[0].DeclineRate)
+	suite.Require().NotNil(summaries[0].MedianHoursToMerge)
+	suite.Assert().Equal(32.0, *summaries[0].MedianHoursToMerge)
+	suite.Assert().Equal(1.0, summaries[0].AverageReviewRounds)
+}
+
+func (suite *PullRequestSuite) TestCanGetMergeTaskError() {
+	var status pullrequest.PullRequestMergeStatus
+	err := json.Unmarshal([]byte(`{"task_status": "SUCCESS", "merge_result": {"id": 1, "state": "MERGED"}}`), &status)
+	suite.Require().NoError(err)
+	suite.Assert().NoError(status.GetError("1"))
+
+	status = pullrequest.PullRequestMergeStatus{}
+	err = json.Unmarshal([]byte(`{"task_status": "FAILED", "error": {"message": "Merge failed", "detail": "The destination branch changed"}}`), &status)
+	suite.Require().NoError(err)
+	status.ID = "6a0ddb61"
+	err = status.GetError("1")
+	suite.Require().Error(err)
+	suite.Assert().Equal(common.ExitMergeFailed, common.GetExitCode(err))
+	suite.Assert().Contains(err.Error(), "The destination branch changed")
+}
+
+func (suite *PullRequestSuite) TestShouldValidateCodeOwnersMode() {
+	folder := suite.T().TempDir()
+	suite.Require().NoError(os.Mkdir(filepath.Join(folder, ".git"), 0755))
+	suite.T().Chdir(folder)
+	ctx := suite.Logger.ToContext(context.Background())
+	cmd := &cobra.Command{}
+
+	mode, err := pullrequest.GetCodeOwnersMode(ctx, cmd)
+	suite.Require().NoError(err)
+	suite.Assert().Equal("none", mode, "Without .bb.yml, the code owners should be ignored")
+
+	suite.Require().NoError(os.WriteFile(filepath.Join(folder, ".bb.yml"), []byte("code_owners: suggest\n"), 0644))
+	mode, err = pullrequest.GetCodeOwnersMode(ctx, cmd)
+	suite.Require().NoError(err)
+	suite.Assert().Equal("suggest", mode)
+
+	suite.Require().NoError(os.WriteFile(filepath.Join(folder, ".bb.yml"), []byte("code_owners: sugest\n"), 0644))
+	_, err = pullrequest.GetCodeOwnersMode(ctx, cmd)
+	suite.Require().Error(err, "A typo in code_owners should not add reviewers")
+	suite.Assert().ErrorIs(err, errors.ArgumentInvalid)
+}
+
+func (suite *PullRequestSuite) TestShouldNotCheckUnresolvedCodeOwners() {
+	alice := user.User{Name: "Alice"}
+	owners := pullrequest.CodeOwners{
+		{Owner: "@alice", Type: "user", Users: []user.User{alice}, ApprovedBy: []string{"Alice"}},
+		{Owner: "@@backend", Type: "group", Users: []user.User{alice}, ApprovedBy: []string{}},
+		{Owner: "docs@acme.com", Type: "unknown", ApprovedBy: []string{}},
+	}
+	notApproved := owners.GetNotApproved()
+	suite.Require().Len(notApproved, 1)
+	suite.Assert().Equal("@@backend", notApproved[0].Owner)
+	unresolved := owners.GetUnresolved()
+	suite.Require().Len(unresolved, 1)
+	suite.Assert().Equal("docs@acme.com", unresolved[0].Owner)
+	suite.Assert().Equal([]string{"docs@acme.com", "unknown", "unresolved", "", "0"}, owners.GetRowAt(2, owners.GetHeaders(nil)))
+
+	suite.Assert().Empty(owners[2:].GetNotApproved(), "Only unresolved owners should not fail the check")
+}
@@ -0,0 +1,160 @@
+package pullrequest
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/gildas/bitbucket-cli/cmd/commit"
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/spf13/cobra"
+)
+
+// Readiness tells if a pullrequest can be merged
+//
+// Blockers cannot be solved by waiting (failed builds, changes requested, pullrequest not open),
+// Pending are the conditions we are still waiting for (running builds, missing approvals, open tasks)
+type Readiness struct {
+	PullRequest       PullRequest     `json:"-"`
+	Approvals         int             `json:"approvals"`
+	RequiredApprovals int             `json:"required_approvals"`
+	RequiredBuilds    int             `json:"required_builds"`
+	Builds            commit.Statuses `json:"builds"`
+	Blockers          []string        `json:"blockers,omitempty"`
+	Pending           []string        `json:"pending,omitempty"`
+}
+
+// branchRestriction is a branch restriction (merge check) of a repository
+type branchRestriction struct {
+	Kind            string `json:"kind"              mapstructure:"kind"`
+	BranchMatchKind string `json:"branch_match_kind" mapstructure:"branch_match_kind"`
+	Pattern         string `json:"pattern"           mapstructure:"pattern"`
+	Value           *int   `json:"value,omitempty"   mapstructure:"value"`
+}
+
+// IsReady tells if the pullrequest can be merged now
+func (readiness Readiness) IsReady() bool {
+	return len(readiness.Blockers) == 0 && len(readiness.Pending) == 0
+}
+
+// IsBlocked tells if the pullrequest cannot be merged without someone acting on it
+func (readiness Readiness) IsBlocked() bool {
+	return len(readiness.Blockers) > 0
+}
+
+// String gets a string representation of this Readiness
+//
+// implements fmt.Stringer
+func (readiness Readiness) String() string {
+	switch {
+	case readiness.IsBlocked():
+		return "blocked: " + strings.Join(readiness.Blockers, ", ")
+	case readiness.IsReady():
+		return "ready"
+	default:
+		return "waiting for " + strings.Join(readiness.Pending, ", ")
+	}
+}
+
+// GetReadiness checks the builds, approvals, tasks, and merge restrictions of a pullrequest
+//
+// minApprovals is the minimum number of approvals, the merge restrictions of the destination branch can require more.
+// Reading the merge restrictions requires admin access to the repository, they are skipped otherwise.
+func GetReadiness(ctx context.Context, cmd *cobra.Command, profile *profile.Profile, repository *repository.Repository, pullRequestID string, minApprovals int) (*Readiness, error) {
+	log := logger.Must(logger.FromContext(ctx)).Child("pullrequest", "readiness")
+	readiness := Readiness{RequiredApprovals: minApprovals}
+
+	if err := profile.Get(ctx, cmd, repository.GetPath("pullrequests", pullRequestID), &readiness.PullRequest); err != nil {
+		return nil, errors.Join(errors.Errorf("Failed to get pullrequest %s", pullRequestID), err)
+	}
+	pullrequest := readiness.PullRequest
+	if pullrequest.State != "OPEN" {
+		readiness.Blockers = append(readiness.Blockers, fmt.Sprintf("pullrequest is %s", strings.ToLower(pullrequest.State)))
+		return &readiness, nil
+	}
+
+	restrictions, err := getMergeRestrictions(ctx, cmd, repository, pullrequest.Destination.Branch.Name)
+	if err != nil {
+		log.Warnf("Cannot read the merge restrictions of %s, using the defaults: %s", repository.FullName, err)
+	} else {
+		for _, restriction := range restrictions {
+			value := 0
+			if restriction.Value != nil {
+				value = *restriction.Value
+			}
+			switch restriction.Kind {
+			case "require_approvals_to_merge", "require_default_reviewer_approvals_to_merge":
+				readiness.RequiredApprovals = max(readiness.RequiredApprovals, value)
+			case "require_passing_builds_to_merge":
+				readiness.RequiredBuilds = max(readiness.RequiredBuilds, value)
+			}
+		}
+	}
+
+	changesRequested := 0
+	for _, participant := range pullrequest.Participants {
+		if participant.Approved {
+			readiness.Approvals++
+		}
+		if participant.State == "changes_requested" {
+			changesRequested++
+		}
+	}
+	if changesRequested > 0 {
+		readiness.Blockers = append(readiness.Blockers, fmt.Sprintf("%d reviewers requested changes", changesRequested))
+	}
+	if readiness.Approvals < readiness.RequiredApprovals {
+		readiness.Pending = append(readiness.Pending, fmt.Sprintf("%d/%d approvals", readiness.Approvals, readiness.RequiredApprovals))
+	}
+	if pullrequest.TaskCount > 0 {
+		readiness.Pending = append(readiness.Pending, fmt.Sprintf("%d open tasks", pullrequest.TaskCount))
+	}
+
+	if pullrequest.Source.Commit != nil {
+		if readiness.Builds, err = commit.GetStatuses(ctx, cmd, repository, pullrequest.Source.Commit.Hash); err != nil {
+			return nil, errors.Join(errors.Errorf("Failed to get the builds of pullrequest %s", pullRequestID), err)
+		}
+	}
+	running, failed, successful := 0, 0, 0
+	for _, build := range readiness.Builds {
+		switch {
+		case !build.IsFinished():
+			running++
+		case build.IsSuccessful():
+			successful++
+		default:
+			failed++
+		}
+	}
+	if failed > 0 {
+		readiness.Blockers = append(readiness.Blockers, fmt.Sprintf("%d failed builds", failed))
+	}
+	if running > 0 {
+		readiness.Pending = append(readiness.Pending, fmt.Sprintf("%d running builds", running))
+	} else if successful < readiness.RequiredBuilds {
+		readiness.Pending = append(readiness.Pending, fmt.Sprintf("%d/%d successful builds", successful, readiness.RequiredBuilds))
+	}
+	log.Infof("Pullrequest %s is %s", pullRequestID, readiness)
+	return &readiness, nil
+}
+
+// getMergeRestrictions gets the branch restrictions of a repository that apply to merging in the given branch
+func getMergeRestrictions(ctx context.Context, cmd *cobra.Command, repository *repository.Repository, branchName string) (restrictions []branchRestriction, err error) {
+	all, err := profile.GetAll[branchRestriction](ctx, cmd, repository.GetPath("branch-restrictions"))
+	if err != nil {
+		return nil, err
+	}
+	for _, restriction := range all {
+		if restriction.BranchMatchKind != "glob" {
+			continue // branching model restrictions are not supported yet
+		}
+		if matched, _ := path.Match(restriction.Pattern, branchName); matched {
+			restrictions = append(restrictions, restriction)
+		}
+	}
+	return restrictions, nil
+}
@@ -14,6 +14,7 @@ var removeRequestChangesCmd = &cobra.Command{
 	Use:               "remove-request-changes [flags] <pullrequest-id>",
 	Aliases:           []string{"removeRequestChanges", "remove-requestChanges", "removerequestchanges", "cancel-request-changes"},
 	Short:             "Remove request changes on a pullrequest by its <pullrequest-id>. If not provided, it will try to remove request changes on the only open pullrequest.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest:write"},
 	Args:              cobra.MaximumNArgs(1),
 	ValidArgsFunction: removeRequestChangesValidArgs,
 	RunE:              removeRequestChangesProcess,
@@ -0,0 +1,395 @@
+package pullrequest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gildas/bitbucket-cli/cmd/commit"
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/gildas/bitbucket-cli/cmd/pullrequest/activity"
+	"github.com/gildas/bitbucket-cli/cmd/pullrequest/comment"
+	"github.com/gildas/bitbucket-cli/cmd/pullrequest/task"
+	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/gildas/bitbucket-cli/cmd/user"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/spf13/cobra"
+)
+
+// Report is the record of a pullrequest with everything that happened to it
+type Report struct {
+	Repository  string              `json:"repository"`
+	PullRequest PullRequest         `json:"pullrequest"`
+	Activities  []activity.Activity `json:"activities"`
+	Comments    []comment.Comment   `json:"comments"`
+	Tasks       []task.Task         `json:"tasks"`
+	Commits     []commit.Commit     `json:"commits"`
+	DiffStats   []DiffStat          `json:"diffstat"`
+	Statuses    []commit.Status     `json:"statuses"`
+}
+
+// Reports is a collection of Report, exported as one document
+type Reports struct {
+	ExportedOn time.Time `json:"exported_on"`
+	Reports    []Report  `json:"pullrequests"`
+}
+
+// GetReport gathers the report of a pullrequest
+func GetReport(ctx context.Context, cmd *cobra.Command, profile *profile.Profile, repository *repository.Repository, pullRequestID string) (report *Report, err error) {
+	log := logger.Must(logger.FromContext(ctx)).Child("pullrequest", "report", "pullrequest", pullRequestID)
+	report = &Report{Repository: repository.FullName}
+
+	if err = profile.Get(ctx, cmd, repository.GetPath("pullrequests", pullRequestID), &report.PullRequest); err != nil {
+		return nil, errors.Join(errors.Errorf("Failed to get pullrequest %s", pullRequestID), err)
+	}
+	log.Debugf("Gathering the activity, comments, tasks, commits, diffstat, and builds")
+	if report.Activities, err = getAllOf[activity.Activity](ctx, cmd, repository, pullRequestID, "activity"); err != nil {
+		return nil, err
+	}
+	if report.Comments, err = getAllOf[comment.Comment](ctx, cmd, repository, pullRequestID, "comments"); err != nil {
+		return nil, err
+	}
+	if report.Tasks, err = getAllOf[task.Task](ctx, cmd, repository, pullRequestID, "tasks"); err != nil {
+		return nil, err
+	}
+	if report.Commits, err = getAllOf[commit.Commit](ctx, cmd, repository, pullRequestID, "commits"); err != nil {
+		// The commits of a merged pullrequest are gone when its source branch was deleted
+		log.Warnf("Failed to get the commits of pullrequest %s: %s", pullRequestID, err)
+		report.Commits = []commit.Commit{}
+	}
+	if report.DiffStats, err = getAllOf[DiffStat](ctx, cmd, repository, pullRequestID, "diffstat"); err != nil {
+		log.Warnf("Failed to get the diffstat of pullrequest %s: %s", pullRequestID, err)
+		report.DiffStats = []DiffStat{}
+	}
+	if report.Statuses, err = getAllOf[commit.Status](ctx, cmd, repository, pullRequestID, "statuses"); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// getAllOf gets all the items of a collection of a pullrequest
+func getAllOf[T any](ctx context.Context, cmd *cobra.Command, repository *repository.Repository, pullRequestID, collection string) ([]T, error) {
+	items, err := profile.GetAll[T](ctx, cmd, repository.GetPath("pullrequests", pullRequestID, collection))
+	if err != nil {
+		return nil, errors.Join(errors.Errorf("Failed to get the %s of pullrequest %s", collection, pullRequestID), err)
+	}
+	if items == nil {
+		items = []T{}
+	}
+	return items, nil
+}
+
+// Title gets the title of the document
+func (reports Reports) Title() string {
+	if len(reports.Reports) == 1 {
+		report := reports.Reports[0]
+		return fmt.Sprintf("Pull Request #%d: %s", report.PullRequest.ID, report.PullRequest.Title)
+	}
+	return fmt.Sprintf("Pull Requests Report (%d)", len(reports.Reports))
+}
+
+// WriteJSON writes the reports as a JSON document
+func (reports Reports) WriteJSON(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return errors.JSONMarshalError.Wrap(encoder.Encode(reports))
+}
+
+// WriteMarkdown writes the reports as a Markdown document
+func (reports Reports) WriteMarkdown(writer io.Writer) error {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "# %s\n\nExported on %s\n", reports.Title(), reports.ExportedOn.Format(time.RFC3339))
+	for _, report := range reports.Reports {
+		report.writeMarkdown(&builder)
+	}
+	_, err := io.WriteString(writer, builder.String())
+	return err
+}
+
+func (report Report) writeMarkdown(builder *strings.Builder) {
+	pullrequest := report.PullRequest
+	cell := func(value string) string {
+		return strings.ReplaceAll(strings.ReplaceAll(value, "|", `\|`), "\n", " ")
+	}
+
+	fmt.Fprintf(builder, "\n## #%d %s\n\n", pullrequest.ID, pullrequest.Title)
+	fmt.Fprintf(builder, "| Field | Value |\n|---|---|\n")
+	fmt.Fprintf(builder, "| Repository | %s |\n", cell(report.Repository))
+	fmt.Fprintf(builder, "| State | %s |\n", pullrequest.State)
+	fmt.Fprintf(builder, "| Author | %s |\n", cell(pullrequest.Author.Name))
+	fmt.Fprintf(builder, "| Branches | %s → %s |\n", cell(pullrequest.Source.Branch.Name), cell(pullrequest.Destination.Branch.Name))
+	fmt.Fprintf(builder, "| Created On | %s |\n", pullrequest.CreatedOn.Format(time.RFC3339))
+	fmt.Fprintf(builder, "| Updated On | %s |\n", pullrequest.UpdatedOn.Format(time.RFC3339))
+	if pullrequest.MergeCommit != nil {
+		fmt.Fprintf(builder, "| Merge Commit | %s |\n", pullrequest.MergeCommit.Hash)
+	}
+	if len(pullrequest.ClosedBy.Name) > 0 {
+		fmt.Fprintf(builder, "| Closed By | %s |\n", cell(pullrequest.ClosedBy.Name))
+	}
+	if link := pullrequest.Links.HTML; link != nil {
+		fmt.Fprintf(builder, "| Link | %s |\n", link.HREF.String())
+	}
+	if len(strings.TrimSpace(pullrequest.Description)) > 0 {
+		fmt.Fprintf(builder, "\n### Description\n\n%s\n", strings.TrimSpace(pullrequest.Description))
+	}
+
+	fmt.Fprintf(builder, "\n### Reviewers\n\n")
+	if len(pullrequest.Participants) == 0 && len(pullrequest.Reviewers) == 0 {
+		builder.WriteString("No reviewers\n")
+	} else {
+		builder.WriteString("| User | Role | Decision | Date |\n|---|---|---|---|\n")
+		for _, row := range report.ReviewerRows() {
+			fmt.Fprintf(builder, "| %s | %s | %s | %s |\n", cell(row[0]), row[1], row[2], row[3])
+		}
+	}
+
+	fmt.Fprintf(builder, "\n### Builds\n\n")
+	if len(report.Statuses) == 0 {
+		builder.WriteString("No builds\n")
+	} else {
+		builder.WriteString("| Name | State | Updated On | URL |\n|---|---|---|---|\n")
+		for _, status := range report.Statuses {
+			name := status.Name
+			if len(name) == 0 {
+				name = status.Key
+			}
+			fmt.Fprintf(builder, "| %s | %s | %s | %s |\n", cell(name), status.State, status.UpdatedOn.Format(time.RFC3339), status.URL)
+		}
+	}
+
+	fmt.Fprintf(builder, "\n### Commits\n\n")
+	if len(report.Commits) == 0 {
+		builder.WriteString("No commits\n")
+	}
+	for _, commit := range report.Commits {
+		fmt.Fprintf(builder, "- `%s` %s (%s, %s)\n", shortHash(commit.Hash), firstLine(commit.Message), authorName(commit.Author), commit.Date.Format(time.RFC3339))
+	}
+
+	fmt.Fprintf(builder, "\n### Files\n\n")
+	if len(report.DiffStats) == 0 {
+		builder.WriteString("No files\n")
+	} else {
+		builder.WriteString("| File | Status | Added | Removed |\n|---|---|---|---|\n")
+		for _, diffstat := range report.DiffStats {
+			fmt.Fprintf(builder, "| %s | %s | %d | %d |\n", cell(diffstat.Path()), diffstat.Status, diffstat.LinesAdded, diffstat.LinesRemoved)
+		}
+	}
+
+	fmt.Fprintf(builder, "\n### Tasks\n\n")
+	if len(report.Tasks) == 0 {
+		builder.WriteString("No tasks\n")
+	}
+	for _, task := range report.Tasks {
+		check := " "
+		if task.State == "RESOLVED" {
+			check = "x"
+		}
+		fmt.Fprintf(builder, "- [%s] %s (%s)\n", check, firstLine(task.Content.Raw), task.Creator.Name)
+	}
+
+	fmt.Fprintf(builder, "\n### Comments\n\n")
+	threads := comment.Comments(report.Comments).Threads()
+	if len(threads) == 0 {
+		builder.WriteString("No comments\n")
+	}
+	for _, thread := range threads {
+		writeMarkdownThread(builder, thread, 0)
+	}
+
+	fmt.Fprintf(builder, "\n### Activity\n\n")
+	if len(report.Activities) == 0 {
+		builder.WriteString("No activity\n")
+	} else {
+		builder.WriteString("| Date | Activity |\n|---|---|\n")
+		for _, row := range report.ActivityRows() {
+			fmt.Fprintf(builder, "| %s | %s |\n", row[0], cell(row[1]))
+		}
+	}
+}
+
+func writeMarkdownThread(builder *strings.Builder, thread comment.Thread, depth int) {
+	indent := strings.Repeat("  ", depth)
+	location := ""
+	if thread.Comment.Anchor != nil {
+		location = fmt.Sprintf(" on `%s`", thread.Comment.Anchor.String())
+	}
+	status := ""
+	if depth == 0 {
+		status = " [" + thread.Status() + "]"
+	}
+	fmt.Fprintf(builder, "%s- **%s**%s, %s%s\n", indent, thread.Comment.User.Name, location, thread.Comment.CreatedOn.Format(time.RFC3339), status)
+	for _, line := range strings.Split(strings.TrimSpace(thread.Comment.Content.Raw), "\n") {
+		fmt.Fprintf(builder, "%s  > %s\n", indent, strings.TrimRight(line, "\r"))
+	}
+	for _, reply := range thread.Replies {
+		writeMarkdownThread(builder, reply, depth+1)
+	}
+}
+
+// ReviewerRows gets the reviewers and participants with their decision: user, role, decision, date
+func (report Report) ReviewerRows() (rows [][]string) {
+	seen := map[string]bool{}
+	for _, participant := range report.PullRequest.Participants {
+		decision := "none"
+		switch {
+		case participant.Approved:
+			decision = "approved"
+		case len(participant.State) > 0:
+			decision = strings.ReplaceAll(participant.State, "_", " ")
+		}
+		date := ""
+		if !participant.ParticipatedOn.IsZero() {
+			date = participant.ParticipatedOn.Format(time.RFC3339)
+		}
+		seen[participant.User.ID.String()] = true
+		rows = append(rows, []string{participant.User.Name, strings.ToLower(participant.Role), decision, date})
+	}
+	for _, reviewer := range report.PullRequest.Reviewers {
+		if !seen[reviewer.ID.String()] {
+			rows = append(rows, []string{reviewer.Name, "reviewer", "none", ""})
+		}
+	}
+	return rows
+}
+
+// ActivityRows gets the activity log, the oldest first: date, description
+func (report Report) ActivityRows() (rows [][]string) {
+	for index := len(report.Activities) - 1; index >= 0; index-- {
+		item := report.Activities[index]
+		switch {
+		case item.Approval != nil:
+			rows = append(rows, []string{item.Approval.Date.Format(time.RFC3339), fmt.Sprintf("%s approved", item.Approval.User.Name)})
+		case item.Update != nil:
+			rows = append(rows, []string{item.Update.Date.Format(time.RFC3339), fmt.Sprintf("%s updated the pull request: %s, %s → %s", item.Update.Author.Name, item.Update.State, item.Update.Source.Branch.Name, item.Update.Destination.Branch.Name)})
+		case item.Comment != nil:
+			rows = append(rows, []string{item.Comment.CreatedOn.Format(time.RFC3339), fmt.Sprintf("%s commented: %s", item.Comment.User.Name, firstLine(item.Comment.Content.Raw))})
+		}
+	}
+	return rows
+}
+
+// WriteHTML writes the reports as a self-contained HTML document
+func (reports Reports) WriteHTML(writer io.Writer) error {
+	page, err := template.New("report").Funcs(template.FuncMap{
+		"date":      func(value time.Time) string { return value.Format(time.RFC3339) },
+		"short":     shortHash,
+		"firstLine": firstLine,
+		"author":    authorName,
+		"link":      func(link *common.Link) string { return link.HREF.String() },
+		"threads":   func(comments []comment.Comment) comment.Threads { return comment.Comments(comments).Threads() },
+		"rendered": func(text string, html string) template.HTML {
+			if len(html) > 0 {
+				return template.HTML(html) // #nosec G203 -- Bitbucket sends sanitized HTML
+			}
+			return template.HTML("<pre>" + template.HTMLEscapeString(text) + "</pre>") // #nosec G203 -- escaped above
+		},
+	}).Parse(reportHTMLTemplate)
+	if err != nil {
+		return errors.RuntimeError.Wrap(err)
+	}
+	return page.Execute(writer, reports)
+}
+
+// shortHash gets the short version of a commit hash
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
+// firstLine gets the first line of a text
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return strings.TrimRight(line, "\r")
+}
+
+// authorName gets the name of a commit author, the Bitbucket user if known
+func authorName(author user.Author) string {
+	if len(author.User.Name) > 0 {
+		return author.User.Name
+	}
+	return author.Raw
+}
+
+const reportHTMLTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 1100px; color: #172b4d; }
+h1, h2 { border-bottom: 1px solid #dfe1e6; padding-bottom: .3em; }
+table { border-collapse: collapse; margin: .5em 0 1em; width: 100%; }
+th, td { border: 1px solid #dfe1e6; padding: .3em .6em; text-align: left; vertical-align: top; }
+th { background: #f4f5f7; }
+code, pre { background: #f4f5f7; padding: .1em .3em; }
+.thread { border-left: 3px solid #dfe1e6; margin: .5em 0; padding-left: 1em; }
+.reply { margin-left: 1.5em; }
+.meta { color: #6b778c; font-size: .9em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="meta">Exported on {{date .ExportedOn}}</p>
+{{range .Reports}}{{$report := .}}{{with .PullRequest}}
+<h2>#{{.ID}} {{.Title}}</h2>
+<table>
+<tr><th>Repository</th><td>{{$report.Repository}}</td></tr>
+<tr><th>State</th><td>{{.State}}</td></tr>
+<tr><th>Author</th><td>{{.Author.Name}}</td></tr>
+<tr><th>Branches</th><td>{{.Source.Branch.Name}} → {{.Destination.Branch.Name}}</td></tr>
+<tr><th>Created On</th><td>{{date .CreatedOn}}</td></tr>
+<tr><th>Updated On</th><td>{{date .UpdatedOn}}</td></tr>
+{{with .MergeCommit}}<tr><th>Merge Commit</th><td><code>{{.Hash}}</code></td></tr>{{end}}
+{{if .ClosedBy.Name}}<tr><th>Closed By</th><td>{{.ClosedBy.Name}}</td></tr>{{end}}
+{{with .Links.HTML}}<tr><th>Link</th><td><a href="{{link .}}">{{link .}}</a></td></tr>{{end}}
+</table>
+{{if .Description}}<h3>Description</h3>
+{{rendered .Description .Summary.HTML}}{{end}}
+{{end}}
+<h3>Reviewers</h3>
+{{with $report.ReviewerRows}}<table>
+<tr><th>User</th><th>Role</th><th>Decision</th><th>Date</th></tr>
+{{range .}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>{{else}}<p>No reviewers</p>{{end}}
+<h3>Builds</h3>
+{{with $report.Statuses}}<table>
+<tr><th>Name</th><th>State</th><th>Updated On</th><th>URL</th></tr>
+{{range .}}<tr><td>{{if .Name}}{{.Name}}{{else}}{{.Key}}{{end}}</td><td>{{.State}}</td><td>{{date .UpdatedOn}}</td><td>{{if .URL}}<a href="{{.URL}}">{{.URL}}</a>{{end}}</td></tr>
+{{end}}</table>{{else}}<p>No builds</p>{{end}}
+<h3>Commits</h3>
+{{with $report.Commits}}<ul>
+{{range .}}<li><code>{{short .Hash}}</code> {{firstLine .Message}} <span class="meta">({{author .Author}}, {{date .Date}})</span></li>
+{{end}}</ul>{{else}}<p>No commits</p>{{end}}
+<h3>Files</h3>
+{{with $report.DiffStats}}<table>
+<tr><th>File</th><th>Status</th><th>Added</th><th>Removed</th></tr>
+{{range .}}<tr><td>{{.Path}}</td><td>{{.Status}}</td><td>{{.LinesAdded}}</td><td>{{.LinesRemoved}}</td></tr>
+{{end}}</table>{{else}}<p>No files</p>{{end}}
+<h3>Tasks</h3>
+{{with $report.Tasks}}<ul>
+{{range .}}<li><input type="checkbox" disabled{{if eq .State "RESOLVED"}} checked{{end}}> {{firstLine .Content.Raw}} <span class="meta">({{.Creator.Name}})</span></li>
+{{end}}</ul>{{else}}<p>No tasks</p>{{end}}
+<h3>Comments</h3>
+{{with threads $report.Comments}}{{range .}}<div class="thread">{{template "thread" .}}<p class="meta">{{.Status}}</p></div>
+{{end}}{{else}}<p>No comments</p>{{end}}
+<h3>Activity</h3>
+{{with $report.ActivityRows}}<table>
+<tr><th>Date</th><th>Activity</th></tr>
+{{range .}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>{{else}}<p>No activity</p>{{end}}
+{{end}}
+</body>
+</html>
+{{define "thread"}}<p class="meta"><strong>{{.Comment.User.Name}}</strong>{{with .Comment.Anchor}} on <code>{{.String}}</code>{{end}}, {{date .Comment.CreatedOn}}</p>
+{{rendered .Comment.Content.Raw .Comment.Content.HTML}}
+{{range .Replies}}<div class="reply">{{template "thread" .}}</div>{{end}}{{end}}
+`
@@ -15,6 +15,7 @@ var requestChangesCmd = &cobra.Command{
 	Use:               "request-changes [flags] <pullrequest-id>",
 	Aliases:           []string{"requestChanges", "requestchanges"},
 	Short:             "Request changes on a pullrequest by its <pullrequest-id>. If not provided, it will try to request changes on the only open pullrequest.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest:write"},
 	Args:              cobra.MaximumNArgs(1),
 	ValidArgsFunction: requestChangesValidArgs,
 	RunE:              requestChangesProcess,
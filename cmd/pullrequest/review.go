@@ -0,0 +1,417 @@
+package pullrequest
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/gildas/bitbucket-cli/cmd/pullrequest/comment"
+	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/gildas/bitbucket-cli/cmd/user"
+	"github.com/gildas/go-core"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/spf13/cobra"
+)
+
+var reviewCmd = &cobra.Command{
+	Use:   "review [flags] <pullrequest-id>",
+	Short: "review a pullrequest interactively by its <pullrequest-id>. If not provided, it will try to review the only open pullrequest.",
+	Long: `Review a pullrequest interactively.
+The diff is shown file by file and hunk by hunk. At any point you can leave inline comments, which are posted as pending comments,
+mark a file as seen, or jump to another file.
+When you finish, the pending comments are published and the pullrequest is approved or changes are requested.`,
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest:write"},
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: approveValidArgs,
+	RunE:              reviewProcess,
+}
+
+const reviewHelp = `Commands:
+  n, <enter>  next hunk
+  p           previous hunk
+  c           comment on a line of the current hunk
+  s           mark the file as seen and go to the next unseen file
+  f           list the files and jump to one of them
+  q           finish the review
+  ?           show this help`
+
+// reviewSession is an interactive review of the diff of a pullrequest
+type reviewSession struct {
+	cmd           *cobra.Command
+	profile       *profile.Profile
+	repository    *repository.Repository
+	pullRequestID string
+	diff          common.Diff
+	seen          []bool
+	file          int
+	hunk          int
+	commented     int
+	input         *bufio.Reader
+	output        io.Writer
+}
+
+func init() {
+	Command.AddCommand(reviewCmd)
+}
+
+func reviewProcess(cmd *cobra.Command, args []string) (err error) {
+	log := logger.Must(logger.FromContext(cmd.Context())).Child(cmd.Parent().Name(), "review")
+	ctx := log.ToContext(cmd.Context())
+
+	if !common.CanEdit() {
+		return errors.Errorf("Reviewing a pullrequest requires an interactive terminal")
+	}
+
+	profile, err := profile.GetProfileFromCommand(ctx, cmd)
+	if err != nil {
+		return errors.Join(errors.Errorf("Cannot review Pull Request"), err)
+	}
+
+	repository, err := repository.GetRepository(ctx, cmd)
+	if err != nil {
+		return errors.Join(errors.Errorf("Cannot review Pull Request"), err)
+	}
+
+	pullRequestID, err := GetPullRequestIDFromArgs(ctx, cmd, repository, args)
+	if err != nil {
+		return errors.Join(errors.Errorf("Cannot review Pull Request"), err)
+	}
+
+	var pullrequest PullRequest
+	if err = profile.Get(ctx, cmd, repository.GetPath("pullrequests", pullRequestID), &pullrequest); err != nil {
+		return errors.Join(errors.Errorf("Failed to get pullrequest %s", pullRequestID), err)
+	}
+
+	raw, err := profile.GetRaw(ctx, cmd, repository.GetPath("pullrequests", pullRequestID, "diff"))
+	if err != nil {
+		return errors.Join(errors.Errorf("Failed to get the diff of pullrequest %s", pullRequestID), err)
+	}
+	diff, err := common.ParseDiff(raw)
+	if err != nil {
+		return errors.Join(errors.Errorf("Failed to parse the diff of pullrequest %s", pullRequestID), err)
+	}
+	if len(diff) == 0 {
+		return errors.Errorf("Pullrequest %s has no changes to review", pullRequestID)
+	}
+
+	session := reviewSession{
+		cmd:           cmd,
+		profile:       profile,
+		repository:    repository,
+		pullRequestID: pullRequestID,
+		diff:          diff,
+		seen:          make([]bool, len(diff)),
+		input:         bufio.NewReader(cmd.InOrStdin()),
+		output:        cmd.OutOrStdout(),
+	}
+	fmt.Fprintf(session.output, "Reviewing pullrequest %s: %s (%s => %s), %d files\n", pullRequestID, pullrequest.Title, pullrequest.Source.Branch.Name, pullrequest.Destination.Branch.Name, len(diff))
+	fmt.Fprintln(session.output, reviewHelp)
+	return session.run(ctx)
+}
+
+// run runs the review session until the reviewer finishes it
+func (session *reviewSession) run(ctx context.Context) error {
+	session.showHunk()
+	for {
+		answer, err := session.prompt("[n,p,c,s,f,q,?] ")
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return session.finish(ctx)
+			}
+			return err
+		}
+		switch strings.ToLower(answer) {
+		case "", "n":
+			if !session.next() {
+				fmt.Fprintln(session.output, "This was the last hunk.")
+				return session.finish(ctx)
+			}
+			session.showHunk()
+		case "p":
+			if !session.previous() {
+				fmt.Fprintln(session.output, "This is the first hunk.")
+				continue
+			}
+			session.showHunk()
+		case "c":
+			if err := session.comment(ctx); err != nil {
+				fmt.Fprintf(session.output, "Failed to comment: %s\n", err)
+			}
+		case "s":
+			session.seen[session.file] = true
+			if !session.nextUnseenFile() {
+				fmt.Fprintln(session.output, "All files were seen.")
+				return session.finish(ctx)
+			}
+			session.showHunk()
+		case "f":
+			if session.jumpToFile() {
+				session.showHunk()
+			}
+		case "q":
+			return session.finish(ctx)
+		case "?", "h":
+			fmt.Fprintln(session.output, reviewHelp)
+		default:
+			fmt.Fprintf(session.output, "Unknown command %s\n", answer)
+			fmt.Fprintln(session.output, reviewHelp)
+		}
+	}
+}
+
+// prompt prints the prompt and reads the answer of the reviewer
+func (session *reviewSession) prompt(format string, args ...any) (string, error) {
+	fmt.Fprintf(session.output, format, args...)
+	answer, err := session.input.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || len(answer) == 0) {
+		return "", err
+	}
+	return strings.TrimSpace(answer), nil
+}
+
+// next moves to the next hunk, or the first hunk of the next file
+func (session *reviewSession) next() bool {
+	if session.hunk+1 < len(session.diff[session.file].Hunks) {
+		session.hunk++
+		return true
+	}
+	if session.file+1 < len(session.diff) {
+		session.seen[session.file] = true
+		session.file++
+		session.hunk = 0
+		return true
+	}
+	session.seen[session.file] = true
+	return false
+}
+
+// previous moves to the previous hunk, or the last hunk of the previous file
+func (session *reviewSession) previous() bool {
+	if session.hunk > 0 {
+		session.hunk--
+		return true
+	}
+	if session.file > 0 {
+		session.file--
+		session.hunk = max(len(session.diff[session.file].Hunks)-1, 0)
+		return true
+	}
+	return false
+}
+
+// nextUnseenFile moves to the first hunk of the next file that was not seen yet
+func (session *reviewSession) nextUnseenFile() bool {
+	for offset := 1; offset < len(session.diff); offset++ {
+		index := (session.file + offset) % len(session.diff)
+		if !session.seen[index] {
+			session.file = index
+			session.hunk = 0
+			return true
+		}
+	}
+	return false
+}
+
+// jumpToFile lists the files and moves to the one the reviewer chooses
+func (session *reviewSession) jumpToFile() bool {
+	for index, file := range session.diff {
+		marker := " "
+		if session.seen[index] {
+			marker = "✓"
+		}
+		added, removed := file.Stat()
+		fmt.Fprintf(session.output, "%s %3d. %s (%s, +%d -%d)\n", marker, index+1, file, file.Status(), added, removed)
+	}
+	answer, err := session.prompt("File number: ")
+	if err != nil || len(answer) == 0 {
+		return false
+	}
+	index, err := strconv.Atoi(answer)
+	if err != nil || index < 1 || index > len(session.diff) {
+		fmt.Fprintf(session.output, "Invalid file number %s\n", answer)
+		return false
+	}
+	session.file = index - 1
+	session.hunk = 0
+	return true
+}
+
+// showHunk prints the current hunk with the line numbers of both sides
+func (session *reviewSession) showHunk() {
+	file := session.diff[session.file]
+	added, removed := file.Stat()
+	seen := ""
+	if session.seen[session.file] {
+		seen = " [seen]"
+	}
+	fmt.Fprintf(session.output, "\n[file %d/%d] %s (%s, +%d -%d)%s\n", session.file+1, len(session.diff), file, file.Status(), added, removed, seen)
+	if file.IsBinary || len(file.Hunks) == 0 {
+		fmt.Fprintln(session.output, "(no textual changes)")
+		return
+	}
+	hunk := file.Hunks[session.hunk]
+	fmt.Fprintf(session.output, "[hunk %d/%d] %s\n", session.hunk+1, len(file.Hunks), hunk.Header)
+	for _, line := range hunk.Lines {
+		if line.Kind == '\\' {
+			fmt.Fprintf(session.output, "%13s%s\n", "", line.Text)
+			continue
+		}
+		fmt.Fprintf(session.output, "%5s %5s %c%s\n", lineNumber(line.OldNumber), lineNumber(line.NewNumber), line.Kind, line.Text)
+	}
+}
+
+// comment posts a pending inline comment on a line of the current hunk
+func (session *reviewSession) comment(ctx context.Context) error {
+	log := logger.Must(logger.FromContext(ctx)).Child("review", "comment")
+	file := session.diff[session.file]
+
+	anchor := common.FileAnchor{Path: file.Path()}
+	defaultLine := ""
+	if len(file.Hunks) > 0 {
+		defaultLine = defaultCommentLine(file.Hunks[session.hunk])
+	}
+	answer, err := session.prompt("Line (new line number, or -<number> for a removed line) [%s]: ", defaultLine)
+	if err != nil {
+		return err
+	}
+	if len(answer) == 0 {
+		answer = defaultLine
+	}
+	if len(answer) > 0 {
+		number, err := strconv.ParseInt(answer, 10, 64)
+		if err != nil || number == 0 {
+			return errors.ArgumentInvalid.With("line", answer)
+		}
+		if number < 0 {
+			anchor.From = uint64(-number)
+		} else {
+			anchor.To = uint64(number)
+		}
+	}
+
+	text, err := session.prompt("Comment (empty to open the editor): ")
+	if err != nil {
+		return err
+	}
+	if len(text) == 0 {
+		if text, err = common.EditText(ctx, "COMMENT_*.md", ""); err != nil {
+			return err
+		}
+		text = strings.TrimSpace(text)
+	}
+	if len(text) == 0 {
+		fmt.Fprintln(session.output, "Empty comment, nothing was posted")
+		return nil
+	}
+
+	pending := true
+	payload := comment.CommentCreator{
+		Content: comment.ContentCreator{Raw: text},
+		Anchor:  &anchor,
+		Pending: &pending,
+	}
+	if !common.WhatIf(ctx, session.cmd, "Commenting on %s of pullrequest %s", anchor, session.pullRequestID) {
+		return nil
+	}
+	var created comment.Comment
+	log.Record("payload", payload).Infof("Creating pending comment on %s", anchor)
+	if err := session.profile.Post(ctx, session.cmd, session.repository.GetPath("pullrequests", session.pullRequestID, "comments"), payload, &created); err != nil {
+		return err
+	}
+	session.commented++
+	fmt.Fprintf(session.output, "Pending comment %d added on %s\n", created.ID, anchor)
+	return nil
+}
+
+// finish publishes the pending comments and approves or requests changes on the pullrequest
+func (session *reviewSession) finish(ctx context.Context) error {
+	log := logger.Must(logger.FromContext(ctx)).Child("review", "finish")
+
+	comments, err := profile.GetAll[comment.Comment](ctx, session.cmd, session.repository.GetPath("pullrequests", session.pullRequestID, "comments"))
+	if err != nil {
+		return errors.Join(errors.Errorf("Failed to get the comments of pullrequest %s", session.pullRequestID), err)
+	}
+	// Pending comments are only visible to their author
+	pendingComments := core.Filter(comments, func(comment comment.Comment) bool { return comment.IsPending && !comment.IsDeleted })
+	seen := len(core.Filter(session.seen, func(seen bool) bool { return seen }))
+	fmt.Fprintf(session.output, "\nYou saw %d of %d files and have %d pending comments (%d from this session).\n", seen, len(session.diff), len(pendingComments), session.commented)
+
+	var decision string
+	for len(decision) == 0 {
+		answer, err := session.prompt("[a]pprove, [r]equest changes, [p]ublish the comments only, or [k]eep the comments pending: ")
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		switch strings.ToLower(answer) {
+		case "a", "approve":
+			decision = "approve"
+		case "r", "request-changes":
+			decision = "request-changes"
+		case "p", "publish":
+			decision = "publish"
+		case "k", "keep":
+			fmt.Fprintf(session.output, "The pending comments are kept, run bb pullrequest review %s again to publish them\n", session.pullRequestID)
+			return nil
+		}
+		if errors.Is(err, io.EOF) && len(decision) == 0 {
+			return nil
+		}
+	}
+
+	if !common.WhatIf(ctx, session.cmd, "Publishing %d comments and %s on pullrequest %s", len(pendingComments), decision, session.pullRequestID) {
+		return nil
+	}
+	published := false
+	for _, pendingComment := range pendingComments {
+		payload := comment.CommentUpdator{
+			Content: comment.ContentUpdator{Raw: pendingComment.Content.Raw},
+			Pending: &published,
+		}
+		log.Infof("Publishing comment %d", pendingComment.ID)
+		if err := session.profile.Put(ctx, session.cmd, session.repository.GetPath("pullrequests", session.pullRequestID, "comments", fmt.Sprintf("%d", pendingComment.ID)), payload, nil); err != nil {
+			return errors.Join(errors.Errorf("Failed to publish comment %d", pendingComment.ID), err)
+		}
+	}
+	fmt.Fprintf(session.output, "Published %d comments\n", len(pendingComments))
+
+	if decision == "publish" {
+		return nil
+	}
+	var participant user.Participant
+	if err := session.profile.Post(ctx, session.cmd, session.repository.GetPath("pullrequests", session.pullRequestID, decision), nil, &participant); err != nil {
+		return errors.Join(errors.Errorf("Failed to %s pullrequest %s", decision, session.pullRequestID), err)
+	}
+	if decision == "approve" {
+		fmt.Fprintf(session.output, "Approved pullrequest %s\n", session.pullRequestID)
+	} else {
+		fmt.Fprintf(session.output, "Requested changes on pullrequest %s\n", session.pullRequestID)
+	}
+	return nil
+}
+
+// defaultCommentLine gets the line to comment on by default: the first changed line of the hunk
+func defaultCommentLine(hunk common.DiffHunk) string {
+	for _, line := range hunk.Lines {
+		switch line.Kind {
+		case '+':
+			return strconv.FormatUint(line.NewNumber, 10)
+		case '-':
+			return "-" + strconv.FormatUint(line.OldNumber, 10)
+		}
+	}
+	return ""
+}
+
+// lineNumber formats a line number of a diff, 0 means the line is not on that side
+func lineNumber(number uint64) string {
+	if number == 0 {
+		return ""
+	}
+	return strconv.FormatUint(number, 10)
+}
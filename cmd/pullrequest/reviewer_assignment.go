@@ -0,0 +1,206 @@
+package pullrequest
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/gildas/bitbucket-cli/cmd/project/reviewer"
+	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/gildas/bitbucket-cli/cmd/user"
+	"github.com/gildas/bitbucket-cli/cmd/workspace"
+	"github.com/gildas/go-core"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/spf13/cobra"
+)
+
+// ReviewerCandidate is a user who can be assigned as a reviewer
+type ReviewerCandidate struct {
+	User user.User `json:"user"`
+	Load int       `json:"load"` // the number of open pullrequests the user is reviewing
+}
+
+// SelectReviewers selects count reviewers among the candidates with the given strategy
+//
+// The strategies are:
+//   - all: all the candidates, count is ignored
+//   - round-robin: the candidates in turn, starting at offset
+//   - least-loaded: the candidates with the lowest load, the ties are broken in round-robin order
+//
+// The candidates are ordered by UUID first, so everyone computes the same turns.
+func SelectReviewers(candidates []ReviewerCandidate, strategy string, count, offset int) []user.User {
+	if strategy == "all" || count <= 0 || len(candidates) == 0 {
+		return core.Map(candidates, func(candidate ReviewerCandidate) user.User { return candidate.User })
+	}
+
+	ordered := make([]ReviewerCandidate, len(candidates))
+	copy(ordered, candidates)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].User.ID.String() < ordered[j].User.ID.String() })
+	if offset = offset % len(ordered); offset < 0 {
+		offset += len(ordered)
+	}
+	ordered = append(ordered[offset:], ordered[:offset]...)
+	if strategy == "least-loaded" {
+		sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Load < ordered[j].Load })
+	}
+	if count > len(ordered) {
+		count = len(ordered)
+	}
+	return core.Map(ordered[:count], func(candidate ReviewerCandidate) user.User { return candidate.User })
+}
+
+// assignReviewers gets the reviewers of a new pullrequest from the candidate pool with the reviewer strategy
+//
+// The author of the pullrequest is never assigned.
+// If the pool has fewer candidates than --reviewer-count, an error is returned unless the profile or the flags warn on or ignore errors
+func assignReviewers(ctx context.Context, cmd *cobra.Command, currentProfile *profile.Profile, repository *repository.Repository) ([]user.User, error) {
+	log := logger.Must(logger.FromContext(ctx)).Child("pullrequest", "assign_reviewers")
+
+	// Find me
+	log.Debugf("Finding current user")
+	me, errMe := user.GetMe(ctx, cmd)
+	if errMe != nil {
+		// RAT (repo scoped tokens) do not have access to that API endpoint usually
+		log.Warnf("Failed to get current user, this may be a RAT client. Error: %s", errMe.Error())
+	} else {
+		log.Infof("Current user: %s (%s)", me.Username, me.ID)
+	}
+
+	candidates, err := getReviewerPool(ctx, cmd, repository, createOptions.ReviewerPool)
+	if err != nil {
+		return nil, errors.Join(err, errMe)
+	}
+	if me != nil {
+		// Removing myself from the reviewers since I cannot be a reviewer of my own pullrequest
+		candidates = core.Filter(candidates, func(candidate ReviewerCandidate) bool { return candidate.User.ID != me.ID })
+		log.Debugf("Filtered reviewers to remove current user: %d reviewers remaining", len(candidates))
+	}
+
+	strategy := createOptions.ReviewerStrategy.Value
+	if strategy == "all" {
+		return SelectReviewers(candidates, strategy, 0, 0), nil
+	}
+	if createOptions.ReviewerCount < 1 {
+		return nil, errors.ArgumentInvalid.With("reviewer-count", createOptions.ReviewerCount)
+	}
+	if createOptions.ReviewerCount > len(candidates) {
+		err := errors.Errorf("Only %d reviewers are available in the %s pool, %d were requested", len(candidates), createOptions.ReviewerPool, createOptions.ReviewerCount)
+		if currentProfile.ShouldStopOnError(cmd) {
+			return nil, err
+		} else if currentProfile.ShouldWarnOnError(cmd) {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+		} else {
+			log.Warnf("%s, but ignoring errors", err)
+		}
+	}
+	if len(candidates) == 0 {
+		return []user.User{}, nil
+	}
+
+	// The turn is given by the number of pullrequests of the repository, so it is shared by everyone
+	offset, err := countPullRequests(ctx, cmd, currentProfile, repository, url.Values{"state": {"OPEN", "MERGED", "DECLINED", "SUPERSEDED"}})
+	if err != nil {
+		log.Warnf("Failed to count the pullrequests of %s, starting the rotation at the first reviewer: %s", repository, err)
+	}
+	if strategy == "least-loaded" {
+		for index, candidate := range candidates {
+			var query common.BBQL
+			query.Equals("state", "OPEN").Equals("reviewers.uuid", candidate.User.ID.String())
+			load, err := countPullRequests(ctx, cmd, currentProfile, repository, url.Values{"q": {query.String()}})
+			if err != nil {
+				return nil, errors.Join(errors.Errorf("Failed to count the pullrequests reviewed by %s", candidate.User.Name), err)
+			}
+			log.Debugf("Reviewer %s is reviewing %d pullrequests", candidate.User.Name, load)
+			candidates[index].Load = load
+		}
+	}
+	reviewers := SelectReviewers(candidates, strategy, createOptions.ReviewerCount, offset)
+	log.Infof("Assigned %d reviewers with the %s strategy", len(reviewers), strategy)
+	return reviewers, nil
+}
+
+// getReviewerPool gets the candidate reviewers of the given pool
+//
+// The pool is "default" for the default reviewers of the repository or project, "members" for the members of the workspace,
+// or "group:name" for a group of reviewers of the .bb.yml file of the repository
+func getReviewerPool(ctx context.Context, cmd *cobra.Command, repository *repository.Repository, pool string) ([]ReviewerCandidate, error) {
+	log := logger.Must(logger.FromContext(ctx)).Child("pullrequest", "reviewer_pool", "pool", pool)
+
+	switch {
+	case pool == "default":
+		log.Debugf("Trying to get effective default reviewers from the repository")
+		reviewers, err := repository.GetEffectiveDefaultReviewers(ctx, cmd)
+		if err != nil {
+			log.Errorf("Failed to get default reviewers", err)
+			return nil, errors.Join(errors.New("Failed to get the default reviewers"), err)
+		}
+		log.Debugf("Found %d default reviewers", len(reviewers))
+		return core.Map(reviewers, func(reviewer reviewer.Reviewer) ReviewerCandidate { return ReviewerCandidate{User: reviewer.User} }), nil
+	case pool == "members":
+		membersWorkspace, err := repository.GetWorkspace(ctx, cmd)
+		if err != nil {
+			return nil, errors.Join(errors.Errorf("Failed to get the workspace of repository %s", repository), err)
+		}
+		members, err := membersWorkspace.GetMembers(ctx, cmd)
+		if err != nil {
+			return nil, errors.Join(errors.Errorf("Failed to get the members of workspace %s", membersWorkspace), err)
+		}
+		return core.Map(members, func(member workspace.Member) ReviewerCandidate { return ReviewerCandidate{User: member.User} }), nil
+	case strings.HasPrefix(pool, "group:"):
+		name := strings.TrimPrefix(pool, "group:")
+		config, err := common.LoadRepositoryConfig(ctx)
+		if err != nil {
+			return nil, errors.Join(errors.Errorf("Cannot get the reviewer group %s", name), err)
+		}
+		ids, err := config.GetReviewerGroup(name)
+		if err != nil {
+			return nil, errors.Join(errors.Errorf("The reviewer group %s is not in %s", name, config.Filename), err)
+		}
+		var members []workspace.Member
+		if groupWorkspace, err := repository.GetWorkspace(ctx, cmd); err == nil {
+			members, _ = groupWorkspace.GetMembers(ctx, cmd)
+		}
+		candidates := make([]ReviewerCandidate, 0, len(ids))
+		for _, id := range ids {
+			found, err := FindUser(ctx, cmd, members, id)
+			if err != nil {
+				log.Errorf("Reviewer %s is not a member of the workspace", id)
+				fmt.Fprintf(os.Stderr, "Reviewer %s of group %s is not a member of the workspace\n", id, name)
+				continue
+			}
+			candidates = append(candidates, ReviewerCandidate{User: *found})
+		}
+		return candidates, nil
+	default:
+		return nil, errors.ArgumentInvalid.With("reviewer-pool", pool)
+	}
+}
+
+// countPullRequests counts the pullrequests of the repository matching the given parameters
+func countPullRequests(ctx context.Context, cmd *cobra.Command, currentProfile *profile.Profile, repository *repository.Repository, parameters url.Values) (int, error) {
+	var page profile.PaginatedResources[PullRequest]
+
+	parameters.Set("pagelen", "1")
+	parameters.Set("fields", "size")
+	if err := currentProfile.Get(ctx, cmd, repository.GetPath("pullrequests?"+parameters.Encode()), &page); err != nil {
+		return 0, err
+	}
+	return page.Size, nil
+}
+
+// reviewerPoolCompletion completes the values of --reviewer-pool with the groups of the .bb.yml file
+func reviewerPoolCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	pools := []string{"default", "members"}
+	if config, err := common.LoadRepositoryConfig(cmd.Context()); err == nil {
+		for name := range config.ReviewerGroups {
+			pools = append(pools, "group:"+name)
+		}
+	}
+	return common.FilterValidArgs(pools, args, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,201 @@
+package pullrequest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gildas/bitbucket-cli/cmd/branch"
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/gildas/go-core"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/spf13/cobra"
+)
+
+// StackEntry is a pullrequest of a Stack
+type StackEntry struct {
+	Branch      string      `json:"branch"`
+	PullRequest PullRequest `json:"pullrequest"`
+}
+
+// Stack is a chain of pullrequests where each pullrequest targets the source branch of the previous one, the bottom first
+//
+// e.g.: feature-1 → main, feature-2 → feature-1, feature-3 → feature-2
+type Stack []StackEntry
+
+var stackCmd = &cobra.Command{
+	Use:   "stack",
+	Short: "Manage stacks of pull requests",
+	Long: `Manage stacks of pull requests.
+
+A stack is a chain of pull requests where each pull request targets the source branch of the previous one (feature-1 → main, feature-2 → feature-1, ...).
+The stack is found from the open pull requests, there is nothing to store locally.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Stack requires a subcommand:")
+		for _, command := range cmd.Commands() {
+			fmt.Println(command.Name())
+		}
+	},
+}
+
+func init() {
+	Command.AddCommand(stackCmd)
+}
+
+// GetStack gets the stack of open pullrequests the given branch belongs to
+//
+// See BuildStack for how the stack is followed.
+func GetStack(ctx context.Context, cmd *cobra.Command, repository *repository.Repository, branchName string) (Stack, error) {
+	log := logger.Must(logger.FromContext(ctx)).Child("pullrequest", "stack", "branch", branchName)
+
+	var query common.BBQL
+	query.Equals("state", "OPEN")
+	pullrequests, err := getPullRequestsWithQuery(ctx, cmd, repository, query.String())
+	if err != nil {
+		return nil, errors.Join(errors.Errorf("Failed to get the open pullrequests"), err)
+	}
+
+	stack := BuildStack(pullrequests, branchName, repository.MainBranch)
+	if len(stack) == 0 {
+		return nil, errors.NotFound.With("stack of branch", branchName)
+	}
+	for _, entry := range stack[:len(stack)-1] {
+		if children := getStackChildren(pullrequests, entry.Branch); len(children) > 1 {
+			log.Warnf("Branch %s is the destination of %d pullrequests, following the oldest one", entry.Branch, len(children))
+		}
+	}
+	return stack, nil
+}
+
+// BuildStack builds the stack the given branch belongs to from the open pullrequests of a repository
+//
+// The stack is followed down through the destination branches, and up through the pullrequests that target the branch, until the main branch.
+// When several pullrequests target the same branch, the oldest one (by creation date) is followed.
+func BuildStack(pullrequests []PullRequest, branchName, mainBranch string) Stack {
+	seen := map[string]bool{}
+	stack := Stack{}
+
+	for current := branchName; !seen[current]; {
+		seen[current] = true
+		index := slices.IndexFunc(pullrequests, func(pullrequest PullRequest) bool { return pullrequest.Source.Branch.Name == current })
+		if index < 0 {
+			break
+		}
+		stack = append(Stack{{Branch: current, PullRequest: pullrequests[index]}}, stack...)
+		current = pullrequests[index].Destination.Branch.Name
+	}
+
+	for current := branchName; current != mainBranch; {
+		children := getStackChildren(pullrequests, current)
+		if len(children) == 0 || seen[children[0].Source.Branch.Name] {
+			break
+		}
+		child := children[0]
+		seen[child.Source.Branch.Name] = true
+		stack = append(stack, StackEntry{Branch: child.Source.Branch.Name, PullRequest: child})
+		current = child.Source.Branch.Name
+	}
+	return stack
+}
+
+// getStackChildren gets the pullrequests that target the given branch, the oldest first
+func getStackChildren(pullrequests []PullRequest, branchName string) []PullRequest {
+	children := core.Filter(pullrequests, func(pullrequest PullRequest) bool { return pullrequest.Destination.Branch.Name == branchName })
+	sort.SliceStable(children, func(i, j int) bool {
+		if children[i].CreatedOn.Equal(children[j].CreatedOn) {
+			return children[i].ID < children[j].ID
+		}
+		return children[i].CreatedOn.Before(children[j].CreatedOn)
+	})
+	return children
+}
+
+// stackBranchArgs gets the branch of the stack commands from their arguments, the current branch by default
+func stackBranchArgs(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	current, err := branch.GetCurrentBranch()
+	if err != nil {
+		return "", errors.Join(errors.Errorf("Cannot find the current branch, please provide a branch of the stack"), err)
+	}
+	return current.Name, nil
+}
+
+// stackBranchValidArgs completes the branch argument of the stack commands
+func stackBranchValidArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return stackCreateValidArgs(cmd, args, toComplete)
+}
+
+// retargetPullRequest changes the destination branch of a pullrequest
+//
+// The full pullrequest is fetched first, as the update replaces the reviewers and the lists do not contain them
+func retargetPullRequest(ctx context.Context, cmd *cobra.Command, profile *profile.Profile, repository *repository.Repository, pullRequestID uint64, destination string) (*PullRequest, error) {
+	var pullrequest PullRequest
+
+	if err := profile.Get(ctx, cmd, repository.GetPath("pullrequests", strconv.FormatUint(pullRequestID, 10)), &pullrequest); err != nil {
+		return nil, errors.Join(errors.Errorf("Failed to get pullrequest %d", pullRequestID), err)
+	}
+	pullrequest.Destination = Endpoint{Branch: Branch{Name: destination}}
+	return UpdatePullRequest(ctx, cmd, profile, repository, pullrequest)
+}
+
+// Base gets the branch the bottom of the stack targets
+func (stack Stack) Base() string {
+	if len(stack) == 0 {
+		return ""
+	}
+	return stack[0].PullRequest.Destination.Branch.Name
+}
+
+// GetHeaders gets the header for a table
+//
+// implements common.Tableables
+func (stack Stack) GetHeaders(cmd *cobra.Command) []string {
+	return []string{"#", "Branch", "Destination", "ID", "State", "Title"}
+}
+
+// GetRowAt gets the row for a table
+//
+// implements common.Tableables
+func (stack Stack) GetRowAt(index int, headers []string) []string {
+	if index < 0 || index >= len(stack) {
+		return []string{}
+	}
+	entry := stack[index]
+	var row []string
+
+	for _, header := range headers {
+		switch strings.ToLower(header) {
+		case "#":
+			row = append(row, strconv.Itoa(index+1))
+		case "branch":
+			row = append(row, entry.Branch)
+		case "destination":
+			row = append(row, entry.PullRequest.Destination.Branch.Name)
+		case "id":
+			row = append(row, strconv.FormatUint(entry.PullRequest.ID, 10))
+		case "state":
+			row = append(row, entry.PullRequest.State)
+		case "title":
+			row = append(row, entry.PullRequest.Title)
+		}
+	}
+	return row
+}
+
+// Size gets the number of elements
+//
+// implements common.Tableables
+func (stack Stack) Size() int {
+	return len(stack)
+}
@@ -0,0 +1,126 @@
+package pullrequest
+
+import (
+	"github.com/gildas/bitbucket-cli/cmd/branch"
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-flags"
+	"github.com/gildas/go-logger"
+	"github.com/spf13/cobra"
+)
+
+var stackCreateCmd = &cobra.Command{
+	Use:   "create [flags] <branch>...",
+	Short: "create a stack of pullrequests from an ordered list of branches, the bottom first",
+	Long: `Create a stack of pullrequests from an ordered list of branches, the bottom first.
+
+The first branch targets the base branch (the main branch of the repository by default), each following branch targets the previous one.
+The branches are pushed if needed. If a branch already has an open pullrequest, its destination is updated instead.`,
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest:write"},
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: stackCreateValidArgs,
+	RunE:              stackCreateProcess,
+}
+
+var stackCreateOptions struct {
+	Base   *flags.EnumFlag
+	Draft  bool
+	NoPush bool
+}
+
+func init() {
+	stackCmd.AddCommand(stackCreateCmd)
+
+	stackCreateOptions.Base = flags.NewEnumFlagWithFunc(stackCreateCmd, "", branch.GetBranchNames)
+	stackCreateCmd.Flags().Var(stackCreateOptions.Base, "base", "Branch the bottom of the stack targets. Default is the main branch of the repository")
+	stackCreateCmd.Flags().BoolVar(&stackCreateOptions.Draft, "draft", false, "Create the pullrequests as drafts")
+	stackCreateCmd.Flags().BoolVar(&stackCreateOptions.NoPush, "no-push", false, "Do not push the branches, they must already be on Bitbucket")
+	_ = stackCreateCmd.RegisterFlagCompletionFunc(stackCreateOptions.Base.CompletionFunc("base"))
+}
+
+func stackCreateValidArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names, err := branch.GetBranchNames(cmd.Context(), cmd, args, toComplete)
+	if err != nil {
+		cobra.CompErrorln(err.Error())
+		return []string{}, cobra.ShellCompDirectiveError
+	}
+	return common.FilterValidArgs(names, args, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func stackCreateProcess(cmd *cobra.Command, args []string) (err error) {
+	log := logger.Must(logger.FromContext(cmd.Context())).Child("stack", "create")
+	ctx := log.ToContext(cmd.Context())
+
+	profile, err := profile.GetProfileFromCommand(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	repository, err := repository.GetRepository(ctx, cmd)
+	if err != nil {
+		return errors.Join(errors.Errorf("Cannot create the stack of pullrequests"), err)
+	}
+
+	destination := stackCreateOptions.Base.Value
+	if len(destination) == 0 {
+		destination = repository.MainBranch
+	}
+	stack := Stack{}
+	for _, source := range args {
+		if source == destination {
+			return errors.Errorf("Branch %s cannot target itself", source)
+		}
+		existing, err := GetOpenPullRequestForBranch(ctx, cmd, repository, source)
+		if err != nil && !errors.Is(err, errors.NotFound) {
+			return err
+		}
+		if existing != nil {
+			if existing.Destination.Branch.Name != destination {
+				log.Infof("Retargeting pullrequest %d from %s to %s", existing.ID, existing.Destination.Branch.Name, destination)
+				if common.WhatIf(ctx, cmd, "Retargeting pullrequest %d of branch %s to %s", existing.ID, source, destination) {
+					if existing, err = retargetPullRequest(ctx, cmd, profile, repository, existing.ID, destination); err != nil {
+						return err
+					}
+				}
+			}
+			stack = append(stack, StackEntry{Branch: source, PullRequest: *existing})
+			destination = source
+			continue
+		}
+
+		title, description, err := prepareFromLocalRepository(ctx, repository, source, destination)
+		if err != nil {
+			return err
+		}
+		if !stackCreateOptions.NoPush {
+			if err := pushSourceBranch(ctx, cmd, profile, repository, source); err != nil {
+				return err
+			}
+		}
+		payload := PullRequestCreator{
+			Title:       title,
+			Description: description,
+			Source:      Endpoint{Branch: Branch{Name: source}},
+			Destination: &Endpoint{Branch: Branch{Name: destination}},
+			Draft:       stackCreateOptions.Draft,
+		}
+		log.Record("payload", payload).Infof("Creating pullrequest from %s to %s", source, destination)
+		if !common.WhatIf(ctx, cmd, "Creating pullrequest from %s to %s", source, destination) {
+			destination = source
+			continue
+		}
+		var pullrequest PullRequest
+		if err = profile.Post(ctx, cmd, repository.GetPath("pullrequests"), payload, &pullrequest); err != nil {
+			return errors.Join(errors.Errorf("Failed to create the pullrequest from %s to %s", source, destination), err)
+		}
+		common.Verbose(ctx, cmd, "Created pullrequest %d from %s to %s", pullrequest.ID, source, destination)
+		stack = append(stack, StackEntry{Branch: source, PullRequest: pullrequest})
+		destination = source
+	}
+	if len(stack) == 0 {
+		return nil
+	}
+	return profile.Print(cmd.Context(), cmd, stack)
+}
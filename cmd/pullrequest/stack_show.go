@@ -0,0 +1,54 @@
+package pullrequest
+
+import (
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/spf13/cobra"
+)
+
+var stackShowCmd = &cobra.Command{
+	Use:               "show [flags] [branch]",
+	Aliases:           []string{"get", "list", "ls"},
+	Short:             "show the stack of pullrequests of a branch, the current branch by default",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest"},
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: stackBranchValidArgs,
+	RunE:              stackShowProcess,
+}
+
+func init() {
+	stackCmd.AddCommand(stackShowCmd)
+}
+
+func stackShowProcess(cmd *cobra.Command, args []string) (err error) {
+	log := logger.Must(logger.FromContext(cmd.Context())).Child("stack", "show")
+	ctx := log.ToContext(cmd.Context())
+
+	profile, err := profile.GetProfileFromCommand(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	repository, err := repository.GetRepository(ctx, cmd)
+	if err != nil {
+		return errors.Join(errors.Errorf("Cannot show the stack of pullrequests"), err)
+	}
+
+	branchName, err := stackBranchArgs(args)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Showing the stack of branch %s", branchName)
+	if !common.WhatIf(ctx, cmd, "Showing the stack of branch %s", branchName) {
+		return nil
+	}
+	stack, err := GetStack(ctx, cmd, repository, branchName)
+	if err != nil {
+		return errors.Join(errors.Errorf("Cannot find the stack of branch %s", branchName), err)
+	}
+	return profile.Print(cmd.Context(), cmd, stack)
+}
@@ -0,0 +1,248 @@
+package pullrequest
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gildas/bitbucket-cli/cmd/branch"
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/cobra"
+)
+
+var stackSyncCmd = &cobra.Command{
+	Use:   "sync [flags] [branch]",
+	Short: "update a stack of pullrequests after its bottom pullrequest was merged",
+	Long: `Update the stack of pullrequests of a branch, the current branch by default, after its bottom pullrequest was merged.
+
+The bottom open pullrequest of the stack is retargeted to the branch its merged destination was merged into.
+With --rebase, the local branches of the stack are rebased, each one onto the previous one, the bottom one onto the new base.
+With --push, the branches are pushed (force pushed after a rebase).`,
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest:write"},
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: stackBranchValidArgs,
+	RunE:              stackSyncProcess,
+}
+
+var stackSyncOptions struct {
+	Rebase bool
+	Push   bool
+}
+
+func init() {
+	stackCmd.AddCommand(stackSyncCmd)
+
+	stackSyncCmd.Flags().BoolVar(&stackSyncOptions.Rebase, "rebase", false, "Rebase the local branches of the stack")
+	stackSyncCmd.Flags().BoolVar(&stackSyncOptions.Push, "push", false, "Push the branches of the stack, force push them after --rebase")
+}
+
+func stackSyncProcess(cmd *cobra.Command, args []string) (err error) {
+	log := logger.Must(logger.FromContext(cmd.Context())).Child("stack", "sync")
+	ctx := log.ToContext(cmd.Context())
+
+	profile, err := profile.GetProfileFromCommand(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	repository, err := repository.GetRepository(ctx, cmd)
+	if err != nil {
+		return errors.Join(errors.Errorf("Cannot synchronize the stack of pullrequests"), err)
+	}
+
+	branchName, err := stackBranchArgs(args)
+	if err != nil {
+		return err
+	}
+
+	stack, err := GetStack(ctx, cmd, repository, branchName)
+	if err != nil {
+		return errors.Join(errors.Errorf("Cannot find the stack of branch %s", branchName), err)
+	}
+
+	oldBase := stack.Base()
+	newBase, err := getMergedBase(cmd, repository, oldBase)
+	if err != nil {
+		return err
+	}
+	if newBase != oldBase {
+		bottom := stack[0].PullRequest
+		fmt.Fprintf(os.Stderr, "Branch %s was merged into %s, retargeting pullrequest %d (%s)\n", oldBase, newBase, bottom.ID, stack[0].Branch)
+		if common.WhatIf(ctx, cmd, "Retargeting pullrequest %d from %s to %s", bottom.ID, oldBase, newBase) {
+			updated, err := retargetPullRequest(ctx, cmd, profile, repository, bottom.ID, newBase)
+			if err != nil {
+				return err
+			}
+			stack[0].PullRequest = *updated
+		}
+	} else {
+		log.Infof("The base %s of the stack was not merged, nothing to retarget", oldBase)
+	}
+
+	if stackSyncOptions.Rebase || stackSyncOptions.Push {
+		if err := syncLocalBranches(cmd, profile, repository, stack, oldBase, newBase); err != nil {
+			return err
+		}
+	}
+	return profile.Print(cmd.Context(), cmd, stack)
+}
+
+// getMergedBase follows the merged pullrequests from the given base branch to the branch the stack should target now
+//
+// e.g.: if feature-1 was merged into main, the stack on feature-1 should target main
+func getMergedBase(cmd *cobra.Command, repository *repository.Repository, base string) (string, error) {
+	log := logger.Must(logger.FromContext(cmd.Context())).Child("stack", "merged_base")
+
+	return FindMergedBase(base, repository.MainBranch, func(branchName string) ([]PullRequest, error) {
+		var query common.BBQL
+		query.Equals("source.branch.name", branchName).Equals("state", "MERGED")
+		merged, err := getPullRequestsWithQuery(cmd.Context(), cmd, repository, query.String())
+		if err != nil {
+			return nil, errors.Join(errors.Errorf("Failed to get the merged pullrequests of branch %s", branchName), err)
+		}
+		if len(merged) > 0 {
+			log.Infof("Branch %s was merged into %s by pullrequest %d", branchName, merged[0].Destination.Branch.Name, merged[0].ID)
+		}
+		return merged, nil
+	})
+}
+
+// FindMergedBase follows the merged pullrequests from the given base branch until a branch that was not merged, or the main branch
+//
+// getMerged gets the merged pullrequests whose source is the given branch, the most recent first
+func FindMergedBase(base, mainBranch string, getMerged func(branchName string) ([]PullRequest, error)) (string, error) {
+	seen := map[string]bool{}
+
+	for base != mainBranch && !seen[base] {
+		seen[base] = true
+		merged, err := getMerged(base)
+		if err != nil {
+			return "", err
+		}
+		if len(merged) == 0 {
+			break
+		}
+		base = merged[0].Destination.Branch.Name
+	}
+	return base, nil
+}
+
+// StackRebase is the rebase of a branch of a stack
+type StackRebase struct {
+	Branch   string
+	Onto     string
+	Upstream string // the commit the branch was based on, the commits after it are rebased
+}
+
+// PlanStackRebase plans the rebases of the local branches of a stack, each one onto the previous one, the bottom one onto onto
+//
+// heads are the local heads of the branches before rebasing, upstream is the commit the bottom branch was based on (empty if unknown).
+// The plan stops at the first branch that is not a local branch, which is returned as missing
+func PlanStackRebase(stack Stack, heads map[string]plumbing.Hash, onto, upstream string) (plan []StackRebase, missing string) {
+	plan = []StackRebase{}
+	for _, entry := range stack {
+		head, found := heads[entry.Branch]
+		if !found {
+			return plan, entry.Branch
+		}
+		plan = append(plan, StackRebase{Branch: entry.Branch, Onto: onto, Upstream: upstream})
+		onto, upstream = entry.Branch, head.String()
+	}
+	return plan, ""
+}
+
+// syncLocalBranches rebases and/or pushes the local branches of the stack
+func syncLocalBranches(cmd *cobra.Command, profile *profile.Profile, repository *repository.Repository, stack Stack, oldBase, newBase string) error {
+	log := logger.Must(logger.FromContext(cmd.Context())).Child("stack", "sync_local")
+	ctx := log.ToContext(cmd.Context())
+
+	repo, err := branch.OpenLocalRepository()
+	if err != nil {
+		return err
+	}
+	remoteName, remoteURL := findGitRemote(repo, repository.FullName)
+	if len(remoteName) == 0 {
+		return errors.Errorf("None of the git remotes is the repository %s", repository.FullName)
+	}
+	auth, err := getGitAuth(ctx, profile, remoteURL)
+	if err != nil {
+		return err
+	}
+
+	rebased := map[string]bool{}
+	leases := map[string]plumbing.Hash{}
+	if stackSyncOptions.Rebase {
+		if dirty, err := branch.IsWorktreeDirty(repo); err != nil {
+			return err
+		} else if dirty {
+			return errors.Errorf("The worktree has uncommitted changes, commit or stash them before rebasing the stack")
+		}
+		current, err := branch.GetCurrentBranch()
+		if err != nil {
+			return errors.Join(errors.Errorf("Cannot find the current branch"), err)
+		}
+		if common.WhatIf(ctx, cmd, "Fetching %s", remoteName) {
+			if err := branch.Fetch(ctx, repo, remoteName, auth); err != nil {
+				return err
+			}
+		}
+
+		// The heads before rebasing tell which commits belong to each branch,
+		// the remote heads are the leases of the force pushes
+		heads := map[string]plumbing.Hash{}
+		for _, entry := range stack {
+			if head, err := branch.ResolveLocalBranch(repo, "", entry.Branch); err == nil {
+				heads[entry.Branch] = head
+			}
+			leases[entry.Branch] = branch.GetRemoteBranchHash(repo, remoteName, entry.Branch)
+		}
+		upstream := ""
+		if newBase != oldBase {
+			if head, err := branch.ResolveLocalBranch(repo, remoteName, oldBase); err == nil {
+				upstream = head.String()
+			} else {
+				log.Warnf("Cannot find the merged branch %s locally, rebasing %s onto %s/%s without it", oldBase, stack[0].Branch, remoteName, newBase)
+			}
+		}
+		plan, missing := PlanStackRebase(stack, heads, remoteName+"/"+newBase, upstream)
+		for _, rebase := range plan {
+			if common.WhatIf(ctx, cmd, "Rebasing branch %s onto %s", rebase.Branch, rebase.Onto) {
+				if err := branch.Rebase(ctx, rebase.Branch, rebase.Onto, rebase.Upstream); err != nil {
+					return err
+				}
+				common.Verbose(ctx, cmd, "Rebased branch %s onto %s", rebase.Branch, rebase.Onto)
+			}
+			rebased[rebase.Branch] = true
+		}
+		if len(missing) > 0 {
+			fmt.Fprintf(os.Stderr, "Branch %s is not a local branch, the branches above it are not rebased\n", missing)
+		}
+		if err := branch.Switch(repo, current.Name); err != nil {
+			log.Warnf("Failed to switch back to branch %s: %s", current.Name, err)
+		}
+	}
+
+	if stackSyncOptions.Push {
+		for _, entry := range stack {
+			switch {
+			case rebased[entry.Branch]:
+				if common.WhatIf(ctx, cmd, "Force pushing branch %s to %s with lease %s", entry.Branch, remoteName, leases[entry.Branch]) {
+					if err := branch.ForcePush(ctx, repo, remoteName, entry.Branch, leases[entry.Branch], auth); err != nil {
+						return err
+					}
+				}
+			case !branch.IsPushed(repo, remoteName, entry.Branch):
+				if common.WhatIf(ctx, cmd, "Pushing branch %s to %s", entry.Branch, remoteName) {
+					if err := branch.Push(ctx, repo, remoteName, entry.Branch, auth); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
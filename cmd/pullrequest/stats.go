@@ -0,0 +1,467 @@
+package pullrequest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/spf13/cobra"
+)
+
+// Stats are the flow metrics of the pullrequests created during a period
+type Stats struct {
+	Since        time.Time         `json:"since"`
+	Until        time.Time         `json:"until"`
+	Repositories StatsSummaries    `json:"repositories"`
+	Authors      StatsSummaries    `json:"authors"`
+	PullRequests PullRequestsStats `json:"pullrequests"`
+}
+
+// PullRequestStats are the flow metrics of a pullrequest
+//
+// The times are in hours, they are nil when the event did not happen
+type PullRequestStats struct {
+	Repository         string    `json:"repository"`
+	ID                 uint64    `json:"id"`
+	Title              string    `json:"title"`
+	Author             string    `json:"author"`
+	State              string    `json:"state"`
+	CreatedOn          time.Time `json:"created_on"`
+	HoursToFirstReview *float64  `json:"hours_to_first_review,omitempty"`
+	HoursToApproval    *float64  `json:"hours_to_approval,omitempty"`
+	HoursToMerge       *float64  `json:"hours_to_merge,omitempty"`
+	ReviewRounds       int       `json:"review_rounds"`
+	Files              int       `json:"files"`
+	LinesAdded         uint64    `json:"lines_added"`
+	LinesRemoved       uint64    `json:"lines_removed"`
+}
+
+// PullRequestsStats is a slice of PullRequestStats
+type PullRequestsStats []PullRequestStats
+
+// StatsSummary are the flow metrics of a group of pullrequests (a repository or an author)
+//
+// The times are medians in hours, the review rounds and sizes are averages
+type StatsSummary struct {
+	Name                     string   `json:"name"`
+	PullRequests             int      `json:"pullrequests"`
+	Merged                   int      `json:"merged"`
+	Declined                 int      `json:"declined"`
+	DeclineRate              float64  `json:"decline_rate"` // declined / (merged + declined)
+	MedianHoursToFirstReview *float64 `json:"median_hours_to_first_review,omitempty"`
+	MedianHoursToApproval    *float64 `json:"median_hours_to_approval,omitempty"`
+	MedianHoursToMerge       *float64 `json:"median_hours_to_merge,omitempty"`
+	AverageReviewRounds      float64  `json:"average_review_rounds"`
+	AverageSize              float64  `json:"average_size"` // lines added + removed
+}
+
+// StatsSummaries is a slice of StatsSummary
+type StatsSummaries []StatsSummary
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "show the review flow metrics of the pull requests created during a period",
+	Long: `Show the review flow metrics of the pull requests created during a period, by repository and by author:
+  - the time to the first review (the first comment or approval of someone else than the author),
+  - the time to the first approval,
+  - the time to merge,
+  - the number of review rounds (a review, then new commits, then another review is 2 rounds),
+  - the size of the pull request (lines added and removed),
+  - the decline rate (declined / (merged + declined)).
+
+The times are medians in hours. With the table output, the metrics are aggregated by repository and by author.
+With --pullrequests, or the csv, tsv, and ndjson outputs, the metrics of each pull request are shown instead.
+With the json and yaml outputs, both are shown.
+
+By default, only the current repository is checked. When --workspace is given, all the repositories of the workspace are checked.`,
+	Annotations: map[string]string{profile.ScopesAnnotation: "pullrequest"},
+	Args:        cobra.NoArgs,
+	RunE:        statsProcess,
+}
+
+var statsOptions struct {
+	Since        string
+	Until        string
+	PullRequests bool
+}
+
+func init() {
+	Command.AddCommand(statsCmd)
+
+	statsCmd.Flags().StringVar(&statsOptions.Since, "since", "30d", "Start of the period: a date (2025-12-31), a date and time, today, yesterday, or a duration before now (7d, 2w)")
+	statsCmd.Flags().StringVar(&statsOptions.Until, "until", "", "End of the period, same format as --since (a date includes that whole day). Default is now")
+	statsCmd.Flags().BoolVar(&statsOptions.PullRequests, "pullrequests", false, "Show the metrics of each pull request instead of the aggregates")
+}
+
+func statsProcess(cmd *cobra.Command, args []string) (err error) {
+	log := logger.Must(logger.FromContext(cmd.Context())).Child(cmd.Parent().Name(), "stats")
+	ctx := log.ToContext(cmd.Context())
+
+	currentProfile, err := profile.GetProfileFromCommand(ctx, cmd)
+	if err != nil {
+		return errors.Join(errors.Errorf("Failed to get the profile"), err)
+	}
+
+	now := time.Now()
+	stats := Stats{Until: now}
+	untilLabel := now.Format(time.DateOnly)
+	if stats.Since, err = common.ParseBBQLTime(statsOptions.Since, now); err != nil {
+		return errors.Join(errors.Errorf("Invalid value for --since"), err)
+	}
+	if len(statsOptions.Until) > 0 {
+		if stats.Until, err = common.ParseBBQLEndTime(statsOptions.Until, now); err != nil {
+			return errors.Join(errors.Errorf("Invalid value for --until"), err)
+		}
+		untilLabel = statsOptions.Until
+	}
+	if !stats.Since.Before(stats.Until) {
+		return errors.ArgumentInvalid.With("since", statsOptions.Since)
+	}
+
+	var repositories []repository.Repository
+	if flag := cmd.Flag("workspace"); flag != nil && flag.Changed {
+		if repositories, err = repository.GetRepositories(ctx, cmd); err != nil {
+			return errors.Join(errors.Errorf("Failed to get the repositories of the workspace"), err)
+		}
+	} else {
+		currentRepository, err := repository.GetRepository(ctx, cmd)
+		if err != nil {
+			return errors.Join(errors.Errorf("Cannot compute the pull request metrics"), err)
+		}
+		repositories = []repository.Repository{*currentRepository}
+	}
+
+	if !common.WhatIf(ctx, cmd, "Computing the metrics of the pull requests created between %s and %s in %d repositories", stats.Since.Format(time.DateOnly), untilLabel, len(repositories)) {
+		return nil
+	}
+
+	var merr errors.MultiError
+	stats.PullRequests = PullRequestsStats{}
+	for _, repo := range repositories {
+		repoStats, err := getRepositoryStats(ctx, cmd, currentProfile, &repo, stats.Since, stats.Until, &merr)
+		if err != nil {
+			return err
+		}
+		stats.PullRequests = append(stats.PullRequests, repoStats...)
+	}
+	if !merr.IsEmpty() {
+		if currentProfile.ShouldWarnOnError(cmd) {
+			fmt.Fprintf(os.Stderr, "These pullrequests are missing from the metrics: %s\n", merr)
+		} else if currentProfile.ShouldIgnoreErrors(cmd) {
+			log.Warnf("These pullrequests are missing from the metrics, but ignoring errors: %s", merr)
+		} else {
+			return merr.AsError()
+		}
+	}
+	stats.Repositories = stats.PullRequests.Summarize(func(pullrequest PullRequestStats) string { return pullrequest.Repository })
+	stats.Authors = stats.PullRequests.Summarize(func(pullrequest PullRequestStats) string { return pullrequest.Author })
+
+	switch format := getOutputFormat(cmd, currentProfile); {
+	case format == "json" || format == "yaml":
+		return currentProfile.Print(ctx, cmd, stats)
+	case statsOptions.PullRequests || format != "table":
+		return currentProfile.Print(ctx, cmd, stats.PullRequests)
+	}
+	if len(stats.PullRequests) == 0 {
+		fmt.Fprintf(os.Stderr, "No pull request was created between %s and %s\n", stats.Since.Format(time.DateOnly), untilLabel)
+		return nil
+	}
+	fmt.Println("By repository:")
+	if err := currentProfile.Print(ctx, cmd, stats.Repositories); err != nil {
+		return err
+	}
+	fmt.Println()
+	fmt.Println("By author:")
+	return currentProfile.Print(ctx, cmd, stats.Authors)
+}
+
+// getRepositoryStats gets the metrics of the pullrequests of a repository created between since and until
+//
+// Unless the profile stops on errors, the pullrequests (or the whole repository) that fail are skipped and their errors are added to merr
+func getRepositoryStats(ctx context.Context, cmd *cobra.Command, currentProfile *profile.Profile, repository *repository.Repository, since, until time.Time, merr *errors.MultiError) (PullRequestsStats, error) {
+	log := logger.Must(logger.FromContext(ctx)).Child("pullrequest", "stats", "repository", repository.FullName)
+
+	var query common.BBQL
+	query.After("created_on", since).Before("created_on", until)
+	parameters := url.Values{"q": {query.String()}, "state": {"OPEN", "MERGED", "DECLINED", "SUPERSEDED"}}
+	pullrequests, err := profile.GetAll[PullRequest](ctx, cmd, repository.GetPath("pullrequests?"+parameters.Encode()))
+	if err != nil {
+		err = errors.Join(errors.Errorf("Failed to get the pullrequests of %s", repository.FullName), err)
+		if currentProfile.ShouldStopOnError(cmd) {
+			return nil, err
+		}
+		merr.Append(err)
+		return PullRequestsStats{}, nil
+	}
+	log.Infof("Found %d pullrequests in %s", len(pullrequests), repository.FullName)
+
+	stats := make(PullRequestsStats, 0, len(pullrequests))
+	for _, pullrequest := range pullrequests {
+		pullRequestID := strconv.FormatUint(pullrequest.ID, 10)
+		activities, err := getAllOf[Activity](ctx, cmd, repository, pullRequestID, "activity")
+		if err != nil {
+			err = errors.Join(errors.Errorf("Failed to get the activity of pullrequest %d in %s", pullrequest.ID, repository.FullName), err)
+			if currentProfile.ShouldStopOnError(cmd) {
+				return nil, err
+			}
+			merr.Append(err)
+			continue
+		}
+		diffstats, err := getAllOf[DiffStat](ctx, cmd, repository, pullRequestID, "diffstat")
+		if err != nil {
+			log.Warnf("Failed to get the diffstat of pullrequest %d, its size is unknown: %s", pullrequest.ID, err)
+		}
+		pullRequestStats := NewPullRequestStats(pullrequest, activities, diffstats)
+		pullRequestStats.Repository = repository.FullName
+		stats = append(stats, pullRequestStats)
+	}
+	return stats, nil
+}
+
+// NewPullRequestStats computes the metrics of a pullrequest from its activities and its diffstat
+//
+// A review is a comment or an approval of someone else than the author.
+// A new review round starts when someone reviews after the source branch got new commits.
+func NewPullRequestStats(pullrequest PullRequest, activities []Activity, diffstats []DiffStat) PullRequestStats {
+	stats := PullRequestStats{
+		ID:        pullrequest.ID,
+		Title:     pullrequest.Title,
+		Author:    pullrequest.Author.Name,
+		State:     pullrequest.State,
+		CreatedOn: pullrequest.CreatedOn,
+	}
+	hoursSinceCreation := func(date time.Time) *float64 {
+		hours := math.Round(date.Sub(pullrequest.CreatedOn).Hours()*10) / 10
+		return &hours
+	}
+
+	events := slices.Clone(activities)
+	sort.SliceStable(events, func(i, j int) bool { return events[i].GetDate().Before(events[j].GetDate()) })
+	sourceHash := ""
+	newCommits := false
+	for _, event := range events {
+		switch {
+		case event.Update != nil:
+			if event.Update.Source.Commit != nil && len(event.Update.Source.Commit.Hash) > 0 {
+				if len(sourceHash) > 0 && sourceHash != event.Update.Source.Commit.Hash && stats.ReviewRounds > 0 {
+					newCommits = true
+				}
+				sourceHash = event.Update.Source.Commit.Hash
+			}
+			if event.Update.State == "MERGED" && stats.HoursToMerge == nil {
+				stats.HoursToMerge = hoursSinceCreation(event.Update.Date)
+			}
+		case event.Approval != nil || event.Comment != nil:
+			if event.GetUser().ID == pullrequest.Author.ID {
+				continue
+			}
+			if stats.HoursToFirstReview == nil {
+				stats.HoursToFirstReview = hoursSinceCreation(event.GetDate())
+			}
+			if event.Approval != nil && stats.HoursToApproval == nil {
+				stats.HoursToApproval = hoursSinceCreation(event.GetDate())
+			}
+			if stats.ReviewRounds == 0 || newCommits {
+				stats.ReviewRounds++
+				newCommits = false
+			}
+		}
+	}
+	if pullrequest.State == "MERGED" && stats.HoursToMerge == nil && !pullrequest.UpdatedOn.IsZero() {
+		stats.HoursToMerge = hoursSinceCreation(pullrequest.UpdatedOn)
+	}
+
+	for _, diffstat := range diffstats {
+		stats.Files++
+		stats.LinesAdded += diffstat.LinesAdded
+		stats.LinesRemoved += diffstat.LinesRemoved
+	}
+	return stats
+}
+
+// Summarize aggregates the metrics of the pullrequests by the key given by group, sorted by key
+func (pullrequests PullRequestsStats) Summarize(group func(PullRequestStats) string) StatsSummaries {
+	groups := map[string]PullRequestsStats{}
+	for _, pullrequest := range pullrequests {
+		key := group(pullrequest)
+		groups[key] = append(groups[key], pullrequest)
+	}
+
+	summaries := make(StatsSummaries, 0, len(groups))
+	for name, members := range groups {
+		summary := StatsSummary{Name: name, PullRequests: len(members)}
+		var toFirstReview, toApproval, toMerge []float64
+		rounds, size := 0, uint64(0)
+		for _, pullrequest := range members {
+			switch pullrequest.State {
+			case "MERGED":
+				summary.Merged++
+			case "DECLINED":
+				summary.Declined++
+			}
+			if pullrequest.HoursToFirstReview != nil {
+				toFirstReview = append(toFirstReview, *pullrequest.HoursToFirstReview)
+			}
+			if pullrequest.HoursToApproval != nil {
+				toApproval = append(toApproval, *pullrequest.HoursToApproval)
+			}
+			if pullrequest.HoursToMerge != nil {
+				toMerge = append(toMerge, *pullrequest.HoursToMerge)
+			}
+			rounds += pullrequest.ReviewRounds
+			size += pullrequest.LinesAdded + pullrequest.LinesRemoved
+		}
+		if closed := summary.Merged + summary.Declined; closed > 0 {
+			summary.DeclineRate = math.Round(float64(summary.Declined)/float64(closed)*100) / 100
+		}
+		summary.MedianHoursToFirstReview = median(toFirstReview)
+		summary.MedianHoursToApproval = median(toApproval)
+		summary.MedianHoursToMerge = median(toMerge)
+		summary.AverageReviewRounds = math.Round(float64(rounds)/float64(len(members))*10) / 10
+		summary.AverageSize = math.Round(float64(size)/float64(len(members))*10) / 10
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return strings.ToLower(summaries[i].Name) < strings.ToLower(summaries[j].Name) })
+	return summaries
+}
+
+// median gets the median of the values, nil if there is none
+func median(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	middle := len(sorted) / 2
+	result := sorted[middle]
+	if len(sorted)%2 == 0 {
+		result = (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return &result
+}
+
+// formatHours formats a number of hours for a table, empty if there is none
+func formatHours(hours *float64) string {
+	if hours == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*hours, 'f', 1, 64)
+}
+
+// GetHeaders gets the header for a table
+//
+// implements common.Tableables
+func (summaries StatsSummaries) GetHeaders(cmd *cobra.Command) []string {
+	return []string{"Name", "Pull Requests", "Merged", "Declined", "Decline Rate", "First Review (h)", "Approval (h)", "Merge (h)", "Review Rounds", "Size"}
+}
+
+// GetRowAt gets the row for a table
+//
+// implements common.Tableables
+func (summaries StatsSummaries) GetRowAt(index int, headers []string) []string {
+	if index < 0 || index >= len(summaries) {
+		return []string{}
+	}
+	summary := summaries[index]
+	row := make([]string, 0, len(headers))
+	for _, header := range headers {
+		switch strings.ToLower(header) {
+		case "name":
+			row = append(row, summary.Name)
+		case "pull requests":
+			row = append(row, strconv.Itoa(summary.PullRequests))
+		case "merged":
+			row = append(row, strconv.Itoa(summary.Merged))
+		case "declined":
+			row = append(row, strconv.Itoa(summary.Declined))
+		case "decline rate":
+			row = append(row, fmt.Sprintf("%.0f%%", summary.DeclineRate*100))
+		case "first review (h)":
+			row = append(row, formatHours(summary.MedianHoursToFirstReview))
+		case "approval (h)":
+			row = append(row, formatHours(summary.MedianHoursToApproval))
+		case "merge (h)":
+			row = append(row, formatHours(summary.MedianHoursToMerge))
+		case "review rounds":
+			row = append(row, strconv.FormatFloat(summary.AverageReviewRounds, 'f', 1, 64))
+		case "size":
+			row = append(row, strconv.FormatFloat(summary.AverageSize, 'f', 0, 64))
+		}
+	}
+	return row
+}
+
+// Size gets the number of elements
+//
+// implements common.Tableables
+func (summaries StatsSummaries) Size() int {
+	return len(summaries)
+}
+
+// GetHeaders gets the header for a table
+//
+// implements common.Tableables
+func (pullrequests PullRequestsStats) GetHeaders(cmd *cobra.Command) []string {
+	return []string{"Repository", "ID", "Title", "Author", "State", "Created On", "First Review (h)", "Approval (h)", "Merge (h)", "Review Rounds", "Files", "Lines Added", "Lines Removed"}
+}
+
+// GetRowAt gets the row for a table
+//
+// implements common.Tableables
+func (pullrequests PullRequestsStats) GetRowAt(index int, headers []string) []string {
+	if index < 0 || index >= len(pullrequests) {
+		return []string{}
+	}
+	pullrequest := pullrequests[index]
+	row := make([]string, 0, len(headers))
+	for _, header := range headers {
+		switch strings.ToLower(header) {
+		case "repository":
+			row = append(row, pullrequest.Repository)
+		case "id":
+			row = append(row, strconv.FormatUint(pullrequest.ID, 10))
+		case "title":
+			row = append(row, pullrequest.Title)
+		case "author":
+			row = append(row, pullrequest.Author)
+		case "state":
+			row = append(row, pullrequest.State)
+		case "created on":
+			row = append(row, pullrequest.CreatedOn.Local().Format("2006-01-02 15:04"))
+		case "first review (h)":
+			row = append(row, formatHours(pullrequest.HoursToFirstReview))
+		case "approval (h)":
+			row = append(row, formatHours(pullrequest.HoursToApproval))
+		case "merge (h)":
+			row = append(row, formatHours(pullrequest.HoursToMerge))
+		case "review rounds":
+			row = append(row, strconv.Itoa(pullrequest.ReviewRounds))
+		case "files":
+			row = append(row, strconv.Itoa(pullrequest.Files))
+		case "lines added":
+			row = append(row, strconv.FormatUint(pullrequest.LinesAdded, 10))
+		case "lines removed":
+			row = append(row, strconv.FormatUint(pullrequest.LinesRemoved, 10))
+		}
+	}
+	return row
+}
+
+// Size gets the number of elements
+//
+// implements common.Tableables
+func (pullrequests PullRequestsStats) Size() int {
+	return len(pullrequests)
+}
@@ -0,0 +1,308 @@
+package pullrequest
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gildas/bitbucket-cli/cmd/branch"
+	"github.com/gildas/bitbucket-cli/cmd/commit"
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/gildas/bitbucket-cli/cmd/user"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/spf13/cobra"
+)
+
+// Dashboard is the list of pullrequests that need the attention of the current user
+type Dashboard struct {
+	User      string        `json:"user"`
+	Current   *StatusEntry  `json:"current,omitempty"`
+	Authored  []StatusEntry `json:"authored"`
+	Reviewing []StatusEntry `json:"reviewing"`
+	colored   bool
+}
+
+// StatusEntry is a pullrequest of the Dashboard with its approval, build, and conflict state
+type StatusEntry struct {
+	Section          string      `json:"section"`
+	Repository       string      `json:"repository"`
+	PullRequest      PullRequest `json:"pullrequest"`
+	Approvals        int         `json:"approvals"`
+	ChangesRequested int         `json:"changes_requested"`
+	Builds           string      `json:"builds,omitempty"`
+	Conflicts        bool        `json:"conflicts"`
+}
+
+const (
+	// StatusSectionCurrent is the section of the pullrequest of the current branch
+	StatusSectionCurrent = "current branch"
+	// StatusSectionAuthored is the section of the pullrequests authored by the current user
+	StatusSectionAuthored = "authored"
+	// StatusSectionReviewing is the section of the pullrequests the current user has to review
+	StatusSectionReviewing = "review requested"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the pull requests that need your attention",
+	Long: `Show the pull requests that need your attention:
+  - the pull request of the current branch,
+  - the open pull requests you authored, with their approvals, builds, and conflicts,
+  - the open pull requests you are a reviewer of and have not approved yet.
+
+By default, only the current repository is checked. When --workspace is given, all the repositories of the workspace are checked.`,
+	Annotations: map[string]string{profile.ScopesAnnotation: "pullrequest"},
+	Args:        cobra.NoArgs,
+	RunE:        statusProcess,
+}
+
+func init() {
+	Command.AddCommand(statusCmd)
+}
+
+func statusProcess(cmd *cobra.Command, args []string) (err error) {
+	log := logger.Must(logger.FromContext(cmd.Context())).Child(cmd.Parent().Name(), "status")
+
+	profile, err := profile.GetProfileFromCommand(cmd.Context(), cmd)
+	if err != nil {
+		return errors.Join(errors.Errorf("Failed to get the profile"), err)
+	}
+
+	me, err := user.GetMe(log.ToContext(cmd.Context()), cmd)
+	if err != nil {
+		return errors.Join(errors.Errorf("Failed to get the current user"), err)
+	}
+
+	var repositories []repository.Repository
+	currentRepository, currentErr := repository.GetRepository(log.ToContext(cmd.Context()), cmd)
+	if flag := cmd.Flag("workspace"); flag != nil && flag.Changed {
+		if repositories, err = repository.GetRepositories(log.ToContext(cmd.Context()), cmd); err != nil {
+			return errors.Join(errors.Errorf("Failed to get the repositories of the workspace"), err)
+		}
+	} else if currentErr != nil {
+		return errors.Join(errors.Errorf("Cannot show the status of the pull requests"), currentErr)
+	} else {
+		repositories = []repository.Repository{*currentRepository}
+	}
+
+	if !common.WhatIf(log.ToContext(cmd.Context()), cmd, "Showing the pull requests of %s in %d repositories", me.Name, len(repositories)) {
+		return nil
+	}
+
+	dashboard := Dashboard{User: me.Name, Authored: []StatusEntry{}, Reviewing: []StatusEntry{}}
+	dashboard.colored = common.CanColor() && getOutputFormat(cmd, profile) == "table"
+
+	if currentErr == nil {
+		if currentBranch, err := branch.GetCurrentBranch(); err == nil {
+			if pullrequest, err := GetOpenPullRequestForBranch(log.ToContext(cmd.Context()), cmd, currentRepository, currentBranch.Name); err == nil {
+				details := getPullRequestDetails(log.ToContext(cmd.Context()), cmd, profile, currentRepository, *pullrequest)
+				entry := getStatusEntry(log.ToContext(cmd.Context()), cmd, currentRepository, details, StatusSectionCurrent)
+				dashboard.Current = &entry
+			} else {
+				log.Debugf("No open pullrequest for branch %s: %s", currentBranch.Name, err)
+			}
+		}
+	}
+
+	for _, repo := range repositories {
+		log.Infof("Getting the pullrequests of %s in %s", me.Name, repo.FullName)
+		var authoredQuery common.BBQL
+		authoredQuery.Equals("author.uuid", me.ID.String()).Equals("state", "OPEN")
+		authored, err := getPullRequestsWithQuery(log.ToContext(cmd.Context()), cmd, &repo, authoredQuery.String())
+		if err != nil {
+			return errors.Join(errors.Errorf("Failed to get the pullrequests authored by %s in %s", me.Name, repo.FullName), err)
+		}
+		for _, pullrequest := range authored {
+			if dashboard.Current != nil && dashboard.Current.Repository == repo.FullName && dashboard.Current.PullRequest.ID == pullrequest.ID {
+				continue
+			}
+			pullrequest = getPullRequestDetails(log.ToContext(cmd.Context()), cmd, profile, &repo, pullrequest)
+			dashboard.Authored = append(dashboard.Authored, getStatusEntry(log.ToContext(cmd.Context()), cmd, &repo, pullrequest, StatusSectionAuthored))
+		}
+
+		var reviewingQuery common.BBQL
+		reviewingQuery.Equals("reviewers.uuid", me.ID.String()).Equals("state", "OPEN")
+		reviewing, err := getPullRequestsWithQuery(log.ToContext(cmd.Context()), cmd, &repo, reviewingQuery.String())
+		if err != nil {
+			return errors.Join(errors.Errorf("Failed to get the pullrequests reviewed by %s in %s", me.Name, repo.FullName), err)
+		}
+		for _, pullrequest := range reviewing {
+			pullrequest = getPullRequestDetails(log.ToContext(cmd.Context()), cmd, profile, &repo, pullrequest)
+			if !hasApproved(pullrequest, *me) {
+				dashboard.Reviewing = append(dashboard.Reviewing, getStatusEntry(log.ToContext(cmd.Context()), cmd, &repo, pullrequest, StatusSectionReviewing))
+			}
+		}
+	}
+	return profile.Print(cmd.Context(), cmd, dashboard)
+}
+
+// getPullRequestsWithQuery gets the pullrequests of a repository that match a BBQL query, the most recently updated first
+func getPullRequestsWithQuery(ctx context.Context, cmd *cobra.Command, repository *repository.Repository, query string) ([]PullRequest, error) {
+	return profile.GetAll[PullRequest](ctx, cmd, repository.GetPath("pullrequests?sort=-updated_on&q="+url.QueryEscape(query)))
+}
+
+// getPullRequestDetails gets the full pullrequest, as the lists do not contain the participants
+//
+// The given pullrequest is returned if the full pullrequest cannot be fetched
+func getPullRequestDetails(ctx context.Context, cmd *cobra.Command, profile *profile.Profile, repository *repository.Repository, pullrequest PullRequest) PullRequest {
+	var details PullRequest
+	if err := profile.Get(ctx, cmd, repository.GetPath("pullrequests", strconv.FormatUint(pullrequest.ID, 10)), &details); err != nil {
+		logger.Must(logger.FromContext(ctx)).Warnf("Failed to get the details of pullrequest %d: %s", pullrequest.ID, err)
+		return pullrequest
+	}
+	return details
+}
+
+// getStatusEntry gets the approval, build, and conflict state of a pullrequest
+//
+// Failing to get the builds or the conflicts is not fatal, the dashboard shows what it can
+func getStatusEntry(ctx context.Context, cmd *cobra.Command, repository *repository.Repository, pullrequest PullRequest, section string) StatusEntry {
+	log := logger.Must(logger.FromContext(ctx)).Child("pullrequest", "status_entry", "pullrequest", pullrequest.ID)
+	entry := StatusEntry{Section: section, Repository: repository.FullName, PullRequest: pullrequest}
+
+	for _, participant := range pullrequest.Participants {
+		if participant.Approved {
+			entry.Approvals++
+		}
+		if participant.State == "changes_requested" {
+			entry.ChangesRequested++
+		}
+	}
+	if pullrequest.Source.Commit != nil {
+		if builds, err := commit.GetStatuses(ctx, cmd, repository, pullrequest.Source.Commit.Hash); err == nil {
+			entry.Builds = builds.State()
+		} else {
+			log.Warnf("Failed to get the builds of pullrequest %d: %s", pullrequest.ID, err)
+		}
+	}
+	if conflicts, err := hasConflicts(ctx, cmd, repository, pullrequest.ID); err == nil {
+		entry.Conflicts = conflicts
+	} else {
+		log.Warnf("Failed to get the diffstat of pullrequest %d: %s", pullrequest.ID, err)
+	}
+	return entry
+}
+
+// hasConflicts tells if the diffstat of a pullrequest reports merge conflicts
+func hasConflicts(ctx context.Context, cmd *cobra.Command, repository *repository.Repository, pullRequestID uint64) (bool, error) {
+	diffstats, err := GetDiffStats(ctx, cmd, repository, pullRequestID)
+	if err != nil {
+		return false, err
+	}
+	for _, diffstat := range diffstats {
+		if diffstat.HasConflict() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// hasApproved tells if the given user approved the pullrequest
+func hasApproved(pullrequest PullRequest, reviewer user.User) bool {
+	for _, participant := range pullrequest.Participants {
+		if participant.User.ID == reviewer.ID {
+			return participant.Approved
+		}
+	}
+	return false
+}
+
+// getOutputFormat gets the output format of the command
+func getOutputFormat(cmd *cobra.Command, profile *profile.Profile) string {
+	if flag := cmd.Flag("output"); flag != nil && flag.Changed {
+		return flag.Value.String()
+	}
+	return profile.OutputFormat
+}
+
+// GetHeaders gets the header for a table
+//
+// implements common.Tableables
+func (dashboard Dashboard) GetHeaders(cmd *cobra.Command) []string {
+	return []string{"Section", "Repository", "ID", "Title", "Branches", "Approvals", "Builds", "Conflicts"}
+}
+
+// GetRowAt gets the row for a table
+//
+// implements common.Tableables
+func (dashboard Dashboard) GetRowAt(index int, headers []string) []string {
+	entries := dashboard.entries()
+	if index < 0 || index >= len(entries) {
+		return []string{}
+	}
+	return entries[index].getRow(headers, dashboard.colored)
+}
+
+// Size gets the number of elements
+//
+// implements common.Tableables
+func (dashboard Dashboard) Size() int {
+	return len(dashboard.entries())
+}
+
+// entries gets all the entries of the dashboard, the current branch first
+func (dashboard Dashboard) entries() (entries []StatusEntry) {
+	if dashboard.Current != nil {
+		entries = append(entries, *dashboard.Current)
+	}
+	entries = append(entries, dashboard.Authored...)
+	return append(entries, dashboard.Reviewing...)
+}
+
+// getRow gets the row for a table, colored or not
+func (entry StatusEntry) getRow(headers []string, colored bool) []string {
+	colorize := func(color common.Color, text string) string {
+		if colored {
+			return common.Colorize(color, text)
+		}
+		return text
+	}
+	var row []string
+
+	for _, header := range headers {
+		switch strings.ToLower(header) {
+		case "section":
+			row = append(row, colorize(common.ColorBold, entry.Section))
+		case "repository":
+			row = append(row, entry.Repository)
+		case "id":
+			row = append(row, strconv.FormatUint(entry.PullRequest.ID, 10))
+		case "title":
+			row = append(row, entry.PullRequest.Title)
+		case "branches":
+			row = append(row, fmt.Sprintf("%s → %s", entry.PullRequest.Source.Branch.Name, entry.PullRequest.Destination.Branch.Name))
+		case "approvals":
+			approvals := colorize(common.ColorGray, "none")
+			if entry.Approvals > 0 {
+				approvals = colorize(common.ColorGreen, fmt.Sprintf("%d approved", entry.Approvals))
+			}
+			if entry.ChangesRequested > 0 {
+				approvals += ", " + colorize(common.ColorRed, fmt.Sprintf("%d changes requested", entry.ChangesRequested))
+			}
+			row = append(row, approvals)
+		case "builds":
+			switch entry.Builds {
+			case commit.StatusSuccessful:
+				row = append(row, colorize(common.ColorGreen, "passed"))
+			case commit.StatusFailed:
+				row = append(row, colorize(common.ColorRed, "failed"))
+			case commit.StatusInProgress:
+				row = append(row, colorize(common.ColorYellow, "running"))
+			default:
+				row = append(row, colorize(common.ColorGray, "none"))
+			}
+		case "conflicts":
+			if entry.Conflicts {
+				row = append(row, colorize(common.ColorRed, "yes"))
+			} else {
+				row = append(row, "no")
+			}
+		}
+	}
+	return row
+}
@@ -18,6 +18,7 @@ var deleteCmd = &cobra.Command{
 	Use:               "delete [flags] <task-id...>",
 	Aliases:           []string{"remove", "rm"},
 	Short:             "delete pullrequest tasks by their <task-id>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest:write"},
 	Args:              cobra.MinimumNArgs(1),
 	ValidArgsFunction: deleteValidArgs,
 	RunE:              deleteProcess,
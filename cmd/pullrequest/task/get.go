@@ -17,6 +17,7 @@ var getCmd = &cobra.Command{
 	Use:               "get [flags] <task-id>",
 	Aliases:           []string{"show", "info", "display"},
 	Short:             "get a pullrequest task by its <task-id>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: getValidArgs,
 	RunE:              getProcess,
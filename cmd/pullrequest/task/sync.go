@@ -0,0 +1,354 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/bitbucket-cli/cmd/profile"
+	prcommon "github.com/gildas/bitbucket-cli/cmd/pullrequest/common"
+	"github.com/gildas/bitbucket-cli/cmd/repository"
+	"github.com/gildas/go-core"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/spf13/cobra"
+)
+
+// ChecklistItem is a "- [ ] item" of a pullrequest description
+type ChecklistItem struct {
+	Line    int    `json:"line"    mapstructure:"line"` // the line of the item in the description, starting at 1
+	Content string `json:"content" mapstructure:"content"`
+	Checked bool   `json:"checked" mapstructure:"checked"`
+}
+
+// SyncAction is an action to synchronize a checklist item with its task
+type SyncAction struct {
+	Action  string `json:"action"            mapstructure:"action"` // create, resolve, check, uncheck, add, or unmatched
+	TaskID  int    `json:"task_id,omitempty" mapstructure:"task_id"`
+	Line    int    `json:"line"              mapstructure:"line"`
+	Content string `json:"content"           mapstructure:"content"`
+}
+
+// SyncActions is a list of SyncAction
+type SyncActions []SyncAction
+
+// SyncPlan is what to do to synchronize the checklist of a pullrequest description with its tasks
+type SyncPlan struct {
+	Actions     SyncActions `json:"actions"               mapstructure:"actions"`
+	Description string      `json:"description,omitempty" mapstructure:"description"` // the new description, empty if unchanged
+}
+
+// pullRequestDescription is the part of a pullrequest needed to synchronize its checklist
+//
+// Bitbucket requires the title when updating a pullrequest
+type pullRequestDescription struct {
+	Title       string `json:"title"       mapstructure:"title"`
+	Description string `json:"description" mapstructure:"description"`
+}
+
+var syncCmd = &cobra.Command{
+	Use:   "sync [flags] <pullrequest-id>",
+	Short: "synchronize the checklist of a pullrequest description with its tasks",
+	Long: `Synchronize the checklist of a pullrequest description with its tasks.
+
+Each "- [ ] item" of the description gets a task, and each "- [x] item" gets its task resolved.
+Items and tasks are matched by their content, ignoring case and spaces, so the command can be run again safely.
+The tasks that match no item are listed.
+
+With --update-description, the description is rewritten to match the tasks instead: the items whose task is resolved are checked,
+the items whose task is unresolved are unchecked, and the tasks that match no item are added to the checklist.`,
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest:write"},
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: syncValidArgs,
+	RunE:              syncProcess,
+}
+
+var syncOptions struct {
+	UpdateDescription bool
+}
+
+// checklistItemPattern matches checklist items like "- [ ] item" or "* [x] item"
+var checklistItemPattern = regexp.MustCompile(`^(\s*[-*+]\s+\[)([ xX])(\]\s+)(.*\S)(\s*)$`)
+
+func init() {
+	Command.AddCommand(syncCmd)
+
+	syncCmd.Flags().BoolVar(&syncOptions.UpdateDescription, "update-description", false, "Rewrite the checklist of the description to match the state of the tasks")
+}
+
+func syncValidArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	ids, err := prcommon.GetPullRequestIDs(cmd.Context(), cmd, args, toComplete)
+	if err != nil {
+		cobra.CompErrorln(err.Error())
+		return []string{}, cobra.ShellCompDirectiveError
+	}
+	return common.FilterValidArgs(ids, args, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func syncProcess(cmd *cobra.Command, args []string) error {
+	log := logger.Must(logger.FromContext(cmd.Context())).Child(cmd.Parent().Name(), "sync")
+	ctx := log.ToContext(cmd.Context())
+
+	currentProfile, err := profile.GetProfileFromCommand(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	repository, err := repository.GetRepository(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	pullRequestID := args[0]
+
+	var pullrequest pullRequestDescription
+	if err = currentProfile.Get(ctx, cmd, repository.GetPath("pullrequests", pullRequestID), &pullrequest); err != nil {
+		return errors.Join(errors.Errorf("Failed to get pullrequest %s", pullRequestID), err)
+	}
+
+	tasks, err := profile.GetAll[Task](ctx, cmd, repository.GetPath("pullrequests", pullRequestID, "tasks"))
+	if err != nil {
+		return errors.Join(errors.Errorf("Failed to get the tasks of pullrequest %s", pullRequestID), err)
+	}
+
+	plan := PlanSync(pullrequest.Description, tasks, syncOptions.UpdateDescription)
+	log.Infof("Synchronizing %d checklist actions on pullrequest %s", len(plan.Actions), pullRequestID)
+	if len(plan.Actions) == 0 {
+		log.Infof("The checklist of pullrequest %s is already synchronized with its tasks", pullRequestID)
+		return nil
+	}
+
+	done := SyncActions{}
+	for _, action := range plan.Actions {
+		applied, err := applySyncAction(ctx, cmd, currentProfile, repository, pullRequestID, &action)
+		if err != nil {
+			return err
+		}
+		if applied {
+			done = append(done, action)
+		}
+	}
+
+	if len(plan.Description) > 0 {
+		if common.WhatIf(ctx, cmd, "Updating the description of pullrequest %s:\n%s", pullRequestID, DiffDescription(pullrequest.Description, plan.Description)) {
+			pullrequest.Description = plan.Description
+			if err = currentProfile.Put(ctx, cmd, repository.GetPath("pullrequests", pullRequestID), pullrequest, nil); err != nil {
+				return errors.Join(errors.Errorf("Failed to update the description of pullrequest %s", pullRequestID), err)
+			}
+			done = append(done, core.Filter(plan.Actions, func(action SyncAction) bool { return action.IsDescriptionChange() })...)
+		}
+	}
+	done = append(done, core.Filter(plan.Actions, func(action SyncAction) bool { return action.Action == "unmatched" })...)
+	if len(done) == 0 {
+		return nil
+	}
+	return currentProfile.Print(ctx, cmd, done)
+}
+
+// applySyncAction creates or resolves the task of a checklist item
+//
+// The actions that change the description are applied when the description is updated. Returns false if the action was not applied
+func applySyncAction(ctx context.Context, cmd *cobra.Command, currentProfile *profile.Profile, repository *repository.Repository, pullRequestID string, action *SyncAction) (applied bool, err error) {
+	switch action.Action {
+	case "create":
+		if !common.WhatIf(ctx, cmd, "Creating task %q on pullrequest %s", action.Content, pullRequestID) {
+			return false, nil
+		}
+		var created Task
+		err = currentProfile.Post(ctx, cmd, repository.GetPath("pullrequests", pullRequestID, "tasks"), TaskCreator{Content: ContentCreator{Raw: action.Content}}, &created)
+		if err != nil {
+			return false, errors.Join(errors.Errorf("Failed to create task %q on pullrequest %s", action.Content, pullRequestID), err)
+		}
+		action.TaskID = created.ID
+		return true, nil
+	case "resolve":
+		if !common.WhatIf(ctx, cmd, "Resolving task %d %q on pullrequest %s", action.TaskID, action.Content, pullRequestID) {
+			return false, nil
+		}
+		taskID := fmt.Sprintf("%d", action.TaskID)
+		if err = currentProfile.Put(ctx, cmd, repository.GetPath("pullrequests", pullRequestID, "tasks", taskID), TaskUpdator{State: "RESOLVED"}, nil); err != nil {
+			return false, errors.Join(errors.Errorf("Failed to resolve task %s on pullrequest %s", taskID, pullRequestID), err)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// ParseChecklist gets the checklist items of a pullrequest description
+//
+// The items in fenced code blocks are ignored
+func ParseChecklist(description string) []ChecklistItem {
+	items := []ChecklistItem{}
+	inCodeBlock := false
+	for index, line := range strings.Split(description, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCodeBlock = !inCodeBlock
+			continue
+		}
+		if inCodeBlock {
+			continue
+		}
+		if matches := checklistItemPattern.FindStringSubmatch(line); matches != nil {
+			items = append(items, ChecklistItem{
+				Line:    index + 1,
+				Content: matches[4],
+				Checked: matches[2] != " ",
+			})
+		}
+	}
+	return items
+}
+
+// NormalizeTaskContent normalizes the content of a task or of a checklist item so they can be matched
+func NormalizeTaskContent(content string) string {
+	return strings.ToLower(strings.Join(strings.Fields(content), " "))
+}
+
+// PlanSync plans the actions to synchronize the checklist of a description with the tasks of its pullrequest
+//
+// Unchecked items without a task get a task.
+// If updateDescription is false, checked items get their unresolved task resolved, and the tasks without an item are listed as unmatched.
+// If updateDescription is true, the tasks win: the items are checked or unchecked to match the state of their task,
+// and the tasks without an item are added at the end of the new description.
+// Each task matches at most one item, so duplicated items get their own task
+func PlanSync(description string, tasks []Task, updateDescription bool) SyncPlan {
+	plan := SyncPlan{Actions: SyncActions{}}
+	available := map[string][]Task{}
+	for _, task := range tasks {
+		key := NormalizeTaskContent(task.Content.Raw)
+		available[key] = append(available[key], task)
+	}
+
+	matched := map[int]bool{}
+	marks := map[int]string{} // the new check mark of the lines to change
+	for _, item := range ParseChecklist(description) {
+		key := NormalizeTaskContent(item.Content)
+		matches := available[key]
+		if len(matches) == 0 {
+			if !item.Checked {
+				plan.Actions = append(plan.Actions, SyncAction{Action: "create", Line: item.Line, Content: item.Content})
+			}
+			continue
+		}
+		task := matches[0]
+		available[key] = matches[1:]
+		matched[task.ID] = true
+		resolved := task.State == "RESOLVED"
+		switch {
+		case item.Checked && !resolved && updateDescription:
+			plan.Actions = append(plan.Actions, SyncAction{Action: "uncheck", TaskID: task.ID, Line: item.Line, Content: item.Content})
+			marks[item.Line] = " "
+		case item.Checked && !resolved:
+			plan.Actions = append(plan.Actions, SyncAction{Action: "resolve", TaskID: task.ID, Line: item.Line, Content: item.Content})
+		case !item.Checked && resolved && updateDescription:
+			plan.Actions = append(plan.Actions, SyncAction{Action: "check", TaskID: task.ID, Line: item.Line, Content: item.Content})
+			marks[item.Line] = "x"
+		}
+	}
+
+	// The trailing new lines are kept after the added items
+	body := strings.TrimRight(description, "\r\n")
+	lines := []string{}
+	if len(body) > 0 {
+		lines = strings.Split(body, "\n")
+	}
+	for line, mark := range marks {
+		lines[line-1] = checklistItemPattern.ReplaceAllString(lines[line-1], "${1}"+mark+"${3}${4}${5}")
+	}
+	added := 0
+	for _, task := range tasks {
+		if matched[task.ID] {
+			continue
+		}
+		content := strings.Join(strings.Fields(task.Content.Raw), " ")
+		if !updateDescription {
+			plan.Actions = append(plan.Actions, SyncAction{Action: "unmatched", TaskID: task.ID, Content: content})
+			continue
+		}
+		mark := " "
+		if task.State == "RESOLVED" {
+			mark = "x"
+		}
+		lines = append(lines, "- ["+mark+"] "+content)
+		plan.Actions = append(plan.Actions, SyncAction{Action: "add", TaskID: task.ID, Line: len(lines), Content: content})
+		added++
+	}
+
+	if len(marks) > 0 || added > 0 {
+		plan.Description = strings.Join(lines, "\n") + description[len(body):]
+	}
+	return plan
+}
+
+// IsDescriptionChange tells if the action is applied by updating the description
+func (action SyncAction) IsDescriptionChange() bool {
+	return action.Action == "check" || action.Action == "uncheck" || action.Action == "add"
+}
+
+// DiffDescription shows the lines that differ between two versions of a description
+//
+// The checklist items are changed in place or added at the end, so the lines are compared one by one
+func DiffDescription(before, after string) string {
+	var builder strings.Builder
+	beforeLines := strings.Split(strings.TrimRight(before, "\r\n"), "\n")
+	afterLines := strings.Split(strings.TrimRight(after, "\r\n"), "\n")
+	if len(strings.TrimSpace(before)) == 0 {
+		beforeLines = []string{}
+	}
+	for index := 0; index < len(afterLines); index++ {
+		switch {
+		case index >= len(beforeLines):
+			fmt.Fprintf(&builder, "@@ line %d @@\n+%s\n", index+1, strings.TrimSuffix(afterLines[index], "\r"))
+		case beforeLines[index] != afterLines[index]:
+			fmt.Fprintf(&builder, "@@ line %d @@\n-%s\n+%s\n", index+1, strings.TrimSuffix(beforeLines[index], "\r"), strings.TrimSuffix(afterLines[index], "\r"))
+		}
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+// GetHeaders gets the headers for the sync command
+//
+// implements common.Tableables
+func (actions SyncActions) GetHeaders(cmd *cobra.Command) []string {
+	return []string{"action", "task", "line", "content"}
+}
+
+// GetRowAt gets the row for the sync command
+//
+// implements common.Tableables
+func (actions SyncActions) GetRowAt(index int, headers []string) []string {
+	if index < 0 || index >= len(actions) {
+		return []string{}
+	}
+	var row []string
+	for _, header := range headers {
+		switch header {
+		case "action":
+			row = append(row, actions[index].Action)
+		case "task":
+			row = append(row, fmt.Sprintf("%d", actions[index].TaskID))
+		case "line":
+			if actions[index].Line > 0 {
+				row = append(row, fmt.Sprintf("%d", actions[index].Line))
+			} else {
+				row = append(row, "") // the unmatched tasks have no line
+			}
+		case "content":
+			row = append(row, actions[index].Content)
+		}
+	}
+	return row
+}
+
+// Size gets the number of elements
+//
+// implements common.Tableables
+func (actions SyncActions) Size() int {
+	return len(actions)
+}
@@ -0,0 +1,142 @@
+package task_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gildas/bitbucket-cli/cmd/common"
+	"github.com/gildas/bitbucket-cli/cmd/pullrequest/task"
+	"github.com/gildas/go-logger"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/suite"
+)
+
+type TaskSyncSuite struct {
+	suite.Suite
+	Name   string
+	Logger *logger.Logger
+	Start  time.Time
+}
+
+func TestTaskSyncSuite(t *testing.T) {
+	suite.Run(t, new(TaskSyncSuite))
+}
+
+// *****************************************************************************
+// Suite Tools
+
+func (suite *TaskSyncSuite) SetupSuite() {
+	_ = godotenv.Load()
+	suite.Name = strings.TrimSuffix(reflect.TypeOf(suite).Elem().Name(), "Suite")
+	suite.Logger = logger.Create("test",
+		&logger.FileStream{
+			Path:         fmt.Sprintf("./log/test-%s.log", strings.ToLower(suite.Name)),
+			Unbuffered:   true,
+			SourceInfo:   true,
+			FilterLevels: logger.NewLevelSet(logger.TRACE),
+		},
+	).Child("test", "test")
+	suite.Logger.Infof("Suite Start: %s %s", suite.Name, strings.Repeat("=", 80-14-len(suite.Name)))
+}
+
+func (suite *TaskSyncSuite) TearDownSuite() {
+	suite.Logger.Debugf("Tearing down")
+	if suite.T().Failed() {
+		suite.Logger.Warnf("At least one test failed, we are not cleaning")
+		suite.T().Log("At least one test failed, we are not cleaning")
+	} else {
+		suite.Logger.Infof("All tests succeeded, we are cleaning")
+	}
+	suite.Logger.Infof("Suite End: %s %s", suite.Name, strings.Repeat("=", 80-12-len(suite.Name)))
+}
+
+func (suite *TaskSyncSuite) BeforeTest(suiteName, testName string) {
+	suite.Logger.Infof("Test Start: %s %s", testName, strings.Repeat("-", 80-13-len(testName)))
+	suite.Start = time.Now()
+}
+
+func (suite *TaskSyncSuite) AfterTest(suiteName, testName string) {
+	duration := time.Since(suite.Start)
+	if suite.T().Failed() {
+		suite.Logger.Errorf("Test %s failed", testName)
+	}
+	suite.Logger.Record("duration", duration.String()).Infof("Test End: %s %s", testName, strings.Repeat("-", 80-11-len(testName)))
+}
+
+func (suite *TaskSyncSuite) TestCanParseChecklist() {
+	description := "Some changes\n\n- [ ] Update the docs\n* [x] Add tests  \r\n- not an item\n```\n- [ ] in a code block\n```\n  - [X] Nested item"
+	items := task.ParseChecklist(description)
+	suite.Require().Len(items, 3)
+	suite.Assert().Equal(task.ChecklistItem{Line: 3, Content: "Update the docs", Checked: false}, items[0])
+	suite.Assert().Equal(task.ChecklistItem{Line: 4, Content: "Add tests", Checked: true}, items[1])
+	suite.Assert().Equal(task.ChecklistItem{Line: 9, Content: "Nested item", Checked: true}, items[2])
+}
+
+func (suite *TaskSyncSuite) TestCanPlanSync() {
+	description := "- [ ] Update the docs\n- [x] Add tests\n- [ ] Fix  the BUILD\n- [ ] Already tracked"
+	tasks := []task.Task{
+		{ID: 1, State: "UNRESOLVED", Content: common.RenderedText{Raw: "add tests"}},
+		{ID: 2, State: "RESOLVED", Content: common.RenderedText{Raw: "Fix the build"}},
+		{ID: 3, State: "UNRESOLVED", Content: common.RenderedText{Raw: "Already tracked"}},
+	}
+
+	plan := task.PlanSync(description, tasks, false)
+	suite.Require().Len(plan.Actions, 2)
+	suite.Assert().Equal(task.SyncAction{Action: "create", Line: 1, Content: "Update the docs"}, plan.Actions[0])
+	suite.Assert().Equal(task.SyncAction{Action: "resolve", TaskID: 1, Line: 2, Content: "Add tests"}, plan.Actions[1])
+	suite.Assert().Empty(plan.Description)
+
+	plan = task.PlanSync(description, tasks, true)
+	suite.Require().Len(plan.Actions, 3)
+	suite.Assert().Equal(task.SyncAction{Action: "create", Line: 1, Content: "Update the docs"}, plan.Actions[0])
+	suite.Assert().Equal(task.SyncAction{Action: "uncheck", TaskID: 1, Line: 2, Content: "Add tests"}, plan.Actions[1], "The task was reopened in Bitbucket")
+	suite.Assert().Equal(task.SyncAction{Action: "check", TaskID: 2, Line: 3, Content: "Fix  the BUILD"}, plan.Actions[2])
+	suite.Assert().Equal("- [ ] Update the docs\n- [ ] Add tests\n- [x] Fix  the BUILD\n- [ ] Already tracked", plan.Description)
+	suite.Assert().Equal("@@ line 2 @@\n-- [x] Add tests\n+- [ ] Add tests\n@@ line 3 @@\n-- [ ] Fix  the BUILD\n+- [x] Fix  the BUILD", task.DiffDescription(description, plan.Description))
+}
+
+func (suite *TaskSyncSuite) TestCanPlanSyncWithUnmatchedTasks() {
+	description := "Some changes\n\n- [ ] Update the docs\n"
+	tasks := []task.Task{
+		{ID: 1, State: "UNRESOLVED", Content: common.RenderedText{Raw: "Update the docs"}},
+		{ID: 2, State: "UNRESOLVED", Content: common.RenderedText{Raw: "Rename  the flag"}},
+		{ID: 3, State: "RESOLVED", Content: common.RenderedText{Raw: "Fix the typo"}},
+	}
+
+	plan := task.PlanSync(description, tasks, false)
+	suite.Assert().Equal(task.SyncActions{
+		{Action: "unmatched", TaskID: 2, Content: "Rename the flag"},
+		{Action: "unmatched", TaskID: 3, Content: "Fix the typo"},
+	}, plan.Actions)
+	suite.Assert().Empty(plan.Description, "The unmatched tasks are only listed without --update-description")
+
+	plan = task.PlanSync(description, tasks, true)
+	suite.Assert().Equal(task.SyncActions{
+		{Action: "add", TaskID: 2, Line: 4, Content: "Rename the flag"},
+		{Action: "add", TaskID: 3, Line: 5, Content: "Fix the typo"},
+	}, plan.Actions)
+	suite.Assert().Equal("Some changes\n\n- [ ] Update the docs\n- [ ] Rename the flag\n- [x] Fix the typo\n", plan.Description)
+	suite.Assert().Equal("@@ line 4 @@\n+- [ ] Rename the flag\n@@ line 5 @@\n+- [x] Fix the typo", task.DiffDescription(description, plan.Description))
+	suite.Assert().True(plan.Actions[0].IsDescriptionChange())
+
+	plan = task.PlanSync("", tasks[1:2], true)
+	suite.Assert().Equal("- [ ] Rename the flag", plan.Description)
+	suite.Assert().Equal("@@ line 1 @@\n+- [ ] Rename the flag", task.DiffDescription("", plan.Description))
+
+	again := task.PlanSync("Some changes\n\n- [ ] Update the docs\n- [ ] Rename the flag\n- [x] Fix the typo\n", tasks, true)
+	suite.Assert().Empty(again.Actions, "The added items match their tasks the next time")
+}
+
+func (suite *TaskSyncSuite) TestCanPlanSyncIdempotently() {
+	description := "- [ ] Update the docs\n- [x] Add tests"
+	tasks := []task.Task{
+		{ID: 1, State: "UNRESOLVED", Content: common.RenderedText{Raw: "Update the docs"}},
+		{ID: 2, State: "RESOLVED", Content: common.RenderedText{Raw: "Add tests"}},
+	}
+	plan := task.PlanSync(description, tasks, true)
+	suite.Assert().Empty(plan.Actions)
+	suite.Assert().Empty(plan.Description)
+}
@@ -26,6 +26,7 @@ var updateCmd = &cobra.Command{
 	Use:               "update [flags] <task-id>",
 	Aliases:           []string{"edit"},
 	Short:             "update a pullrequest task by its <task-id>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest:write"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: updateValidArgs,
 	RunE:              updateProcess,
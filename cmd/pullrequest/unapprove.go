@@ -13,6 +13,7 @@ import (
 var unapproveCmd = &cobra.Command{
 	Use:               "unapprove [flags] <pullrequest-id>",
 	Short:             "unapprove a pullrequest by its <pullrequest-id>. If not provided, it will try to unapprove the only open pullrequest.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest:write"},
 	Args:              cobra.MaximumNArgs(1),
 	ValidArgsFunction: unapproveValidArgs,
 	RunE:              unapproveProcess,
@@ -1,9 +1,11 @@
 package pullrequest
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/gildas/bitbucket-cli/cmd/branch"
@@ -24,8 +26,9 @@ import (
 var updateCmd = &cobra.Command{
 	Use:               "update [flags] <pullrequest-id>",
 	Aliases:           []string{"edit"},
-	Short:             "update a pullrequest by its <pullrequest-id>.",
-	Args:              cobra.ExactArgs(1),
+	Short:             "update a pullrequest by its <pullrequest-id>, or all the open pullrequests matching the filters.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "pullrequest:write"},
+	Args:              cobra.MaximumNArgs(1),
 	ValidArgsFunction: updateValidArgs,
 	RunE:              updateProcess,
 }
@@ -37,6 +40,8 @@ var updateOptions struct {
 	AddReviewers      *flags.EnumSliceFlag
 	RemoveReviewers   *flags.EnumSliceFlag
 	CloseSourceBranch bool
+	CodeOwners        *flags.EnumFlag
+	Bulk              bulkOptions
 }
 
 func init() {
@@ -45,6 +50,7 @@ func init() {
 	updateOptions.Destination = flags.NewEnumFlagWithFunc(updateCmd, "", branch.GetBranchNames)
 	updateOptions.AddReviewers = flags.NewEnumSliceFlagWithAllAllowedAndFunc(updateCmd, GetReviewerNicknames)
 	updateOptions.RemoveReviewers = flags.NewEnumSliceFlagWithAllAllowedAndFunc(updateCmd, GetReviewerNicknames)
+	updateOptions.CodeOwners = flags.NewEnumFlag("+none", "suggest", "add")
 
 	updateCmd.Flags().StringVar(&updateOptions.Title, "title", "", "Title of the pullrequest")
 	updateCmd.Flags().StringVar(&updateOptions.Description, "description", "", "Description of the pullrequest")
@@ -52,10 +58,18 @@ func init() {
 	updateCmd.Flags().Var(updateOptions.AddReviewers, "add-reviewer", "Reviewer(s) to add to the pullrequest. Can be specified multiple times, or as a comma-separated list. Can be the user Account ID, UUID, name, or nickname.")
 	updateCmd.Flags().Var(updateOptions.RemoveReviewers, "remove-reviewer", "Reviewer(s) to remove from the pullrequest. Can be specified multiple times, or as a comma-separated list. Can be the user Account ID, UUID, name, or nickname.")
 	updateCmd.Flags().BoolVar(&updateOptions.CloseSourceBranch, "close-source-branch", false, "Close the source branch after merging")
+	updateCmd.Flags().Var(updateOptions.CodeOwners, "code-owners", "What to do with the code owners of the changed files (from the CODEOWNERS file of the destination branch): none, suggest, or add them as reviewers. Default is the code_owners setting of the .bb.yml file, or none")
+
+	updateOptions.Bulk.addFlags(updateCmd, "Update")
+	for _, filter := range updateOptions.Bulk.Filter.flags {
+		updateCmd.MarkFlagsMutuallyExclusive("title", filter)
+		updateCmd.MarkFlagsMutuallyExclusive("description", filter)
+	}
 
 	_ = updateCmd.RegisterFlagCompletionFunc(updateOptions.Destination.CompletionFunc("destination"))
 	_ = updateCmd.RegisterFlagCompletionFunc(updateOptions.AddReviewers.CompletionFunc("add-reviewer"))
 	_ = updateCmd.RegisterFlagCompletionFunc(updateOptions.RemoveReviewers.CompletionFunc("remove-reviewer"))
+	_ = updateCmd.RegisterFlagCompletionFunc(updateOptions.CodeOwners.CompletionFunc("code-owners"))
 }
 
 func updateValidArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -84,6 +98,37 @@ func updateProcess(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	codeOwnersMode, err := GetCodeOwnersMode(log.ToContext(cmd.Context()), cmd)
+	if err != nil {
+		return err
+	}
+
+	if updateOptions.Bulk.isBulk(cmd) {
+		if len(args) > 0 {
+			return errors.ArgumentInvalid.With("pullrequest-id", "cannot be used with filters")
+		}
+		pullrequests, err := updateOptions.Bulk.getPullRequests(log.ToContext(cmd.Context()), cmd, repository)
+		if err != nil {
+			return errors.Join(errors.Errorf("Cannot update Pull Requests"), err)
+		}
+		return updateOptions.Bulk.processBulk(log.ToContext(cmd.Context()), cmd, profile, "Updating", pullrequests, func(ctx context.Context, pullrequest PullRequest) error {
+			// The lists do not contain the reviewers, the full pullrequest is needed
+			var details PullRequest
+			if err := profile.Get(ctx, cmd, repository.GetPath("pullrequests", strconv.FormatUint(pullrequest.ID, 10)), &details); err != nil {
+				return errors.Join(errors.Errorf("Failed to get pullrequest %d", pullrequest.ID), err)
+			}
+			updateWanted, err := applyUpdateOptions(ctx, cmd, profile, repository, &details, codeOwnersMode)
+			if err != nil || !updateWanted {
+				return err
+			}
+			_, err = UpdatePullRequest(ctx, cmd, profile, repository, details)
+			return err
+		})
+	}
+	if len(args) == 0 {
+		return errors.ArgumentMissing.With("pullrequest-id")
+	}
+
 	var pullrequest PullRequest
 
 	log.Infof("Fetching pullrequest %s", args[0])
@@ -100,6 +145,34 @@ func updateProcess(cmd *cobra.Command, args []string) error {
 	log.Infof("Fetched pullrequest %s", args[0])
 	log.Record("pullrequest", pullrequest).Debugf("Pullrequest %s details", args[0])
 
+	updateWanted, err := applyUpdateOptions(log.ToContext(cmd.Context()), cmd, profile, repository, &pullrequest, codeOwnersMode)
+	if err != nil {
+		return err
+	}
+
+	if !updateWanted {
+		log.Infof("No update options were changed, exiting")
+		return nil
+	}
+
+	log.Record("update", pullrequest).Infof("Updating pullrequest %s", args[0])
+	if !common.WhatIf(log.ToContext(cmd.Context()), cmd, "Updating pullrequest %d", pullrequest.ID) {
+		return nil
+	}
+
+	updated, err := UpdatePullRequest(log.ToContext(cmd.Context()), cmd, profile, repository, pullrequest)
+	if err != nil {
+		return err
+	}
+	return profile.Print(cmd.Context(), cmd, updated)
+}
+
+// applyUpdateOptions applies the update flags to the given pullrequest
+//
+// codeOwnersMode tells what to do with the code owners, see GetCodeOwnersMode.
+// Returns true if the pullrequest was changed and should be sent to Bitbucket
+func applyUpdateOptions(ctx context.Context, cmd *cobra.Command, currentProfile *profile.Profile, repository *repository.Repository, pullrequest *PullRequest, codeOwnersMode string) (bool, error) {
+	log := logger.Must(logger.FromContext(ctx)).Child("pullrequest", "update", "pullrequest", pullrequest.ID)
 	updateWanted := false
 
 	if cmd.Flag("title").Changed {
@@ -124,17 +197,18 @@ func updateProcess(cmd *cobra.Command, args []string) error {
 	}
 
 	var pullrequestWorkspace *workspace.Workspace
+	var err error
 	if pullrequest.Destination.Repository != nil {
 		log.Infof("Getting workspace of pullrequest destination repository %s", pullrequest.Destination.Repository.FullName)
 		log.Record("repository", pullrequest.Destination.Repository).Debugf("Pullrequest destination repository details")
-		pullrequestWorkspace, err = pullrequest.Destination.Repository.GetWorkspace(cmd.Context(), cmd)
+		pullrequestWorkspace, err = pullrequest.Destination.Repository.GetWorkspace(ctx, cmd)
 	} else {
 		log.Infof("Getting current workspace")
-		pullrequestWorkspace, err = repository.GetWorkspace(cmd.Context(), cmd)
+		pullrequestWorkspace, err = repository.GetWorkspace(ctx, cmd)
 	}
 	if err != nil {
 		log.Errorf("Failed to get workspace of pullrequest destination repository", err)
-		return errors.Join(errors.Errorf("Failed to get workspace of pullrequest destination repository"), err)
+		return false, errors.Join(errors.Errorf("Failed to get workspace of pullrequest destination repository"), err)
 	}
 	log.Infof("Pullrequest workspace: %s", pullrequestWorkspace)
 
@@ -169,7 +243,7 @@ func updateProcess(cmd *cobra.Command, args []string) error {
 			if updateOptions.AddReviewers.Values[0] == "default" {
 				// Find me
 				log.Debugf("Finding current user")
-				me, err := user.GetMe(cmd.Context(), cmd)
+				me, err := user.GetMe(ctx, cmd)
 				if err != nil {
 					// RAT (repo scoped tokens) do not have access to that API endpoint usually
 					log.Warnf("Failed to get current user, this may be a RAT client. Error: %s", err.Error())
@@ -181,10 +255,10 @@ func updateProcess(cmd *cobra.Command, args []string) error {
 				var reviewers []reviewer.Reviewer
 
 				log.Debugf("No reviewers in the repository, trying to get effective default reviewers from the repository")
-				reviewers, err = pullrequest.Source.Repository.GetEffectiveDefaultReviewers(cmd.Context(), cmd)
+				reviewers, err = pullrequest.Source.Repository.GetEffectiveDefaultReviewers(ctx, cmd)
 				if err != nil {
 					log.Errorf("Failed to get default reviewers", err)
-					return err
+					return false, err
 				}
 				log.Debugf("Found %d default reviewers", len(reviewers))
 
@@ -202,7 +276,7 @@ func updateProcess(cmd *cobra.Command, args []string) error {
 			}
 
 			log.Debugf("Getting all members from workspace %s", pullrequestWorkspace)
-			members, _ := pullrequestWorkspace.GetMembers(cmd.Context(), cmd)
+			members, _ := pullrequestWorkspace.GetMembers(ctx, cmd)
 			log.Infof("Found %d members in workspace %s", len(members), pullrequestWorkspace)
 			for _, reviewer := range updateOptions.AddReviewers.Values {
 				log.Debugf("Processing reviewer to add: %s", reviewer)
@@ -222,33 +296,28 @@ func updateProcess(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	if !updateWanted {
-		log.Infof("No update options were changed, exiting")
-		return nil
-	}
+	added := applyCodeOwners(ctx, cmd, currentProfile, repository, pullrequest, codeOwnersMode)
+	return updateWanted || added, nil
+}
+
+// UpdatePullRequest sends the given pullrequest to Bitbucket to update it
+func UpdatePullRequest(ctx context.Context, cmd *cobra.Command, profile *profile.Profile, repository *repository.Repository, pullrequest PullRequest) (*PullRequest, error) {
+	var updated PullRequest
 
 	// Remove fields that should not be sent in update
 	pullrequest.Summary.Type = ""
 	pullrequest.Summary.Markup = ""
 	pullrequest.Summary.HTML = ""
 
-	log.Record("update", pullrequest).Infof("Updating pullrequest %s", args[0])
-	if !common.WhatIf(log.ToContext(cmd.Context()), cmd, "Updating pullrequest %d", pullrequest.ID) {
-		return nil
-	}
-
-	var updated PullRequest
-
-	err = profile.Put(
-		log.ToContext(cmd.Context()),
+	err := profile.Put(
+		ctx,
 		cmd,
-		repository.GetPath("pullrequests", args[0]),
+		repository.GetPath("pullrequests", fmt.Sprintf("%d", pullrequest.ID)),
 		pullrequest,
 		&updated,
 	)
 	if err != nil {
-		return errors.Join(errors.Errorf("Failed to update pullrequest %s", args[0]), err)
+		return nil, errors.Join(errors.Errorf("Failed to update pullrequest %d", pullrequest.ID), err)
 	}
-
-	return profile.Print(cmd.Context(), cmd, updated)
+	return &updated, nil
 }
@@ -20,6 +20,7 @@ import (
 var cloneCmd = &cobra.Command{
 	Use:               "clone [flags] <slug>",
 	Short:             "clone a repository by its <slug>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "repository"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: cloneValidArgs,
 	PreRunE:           disableUnsupportedFlags,
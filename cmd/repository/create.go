@@ -22,11 +22,12 @@ type RepositoryCreator struct {
 }
 
 var createCmd = &cobra.Command{
-	Use:     "create [flags] <slug>",
-	Short:   "create a repository in a project and a workspace. The repository <slug> must be unique in the workspace.",
-	Args:    cobra.ExactArgs(1),
-	PreRunE: disableUnsupportedFlags,
-	RunE:    createProcess,
+	Use:         "create [flags] <slug>",
+	Short:       "create a repository in a project and a workspace. The repository <slug> must be unique in the workspace.",
+	Annotations: map[string]string{profile.ScopesAnnotation: "repository:admin"},
+	Args:        cobra.ExactArgs(1),
+	PreRunE:     disableUnsupportedFlags,
+	RunE:        createProcess,
 }
 
 var createOptions struct {
@@ -15,6 +15,7 @@ var deleteCmd = &cobra.Command{
 	Use:               "delete [flags] <slug_or_uuid...>",
 	Aliases:           []string{"remove", "rm"},
 	Short:             "delete repositories by their <slug> or <uuid>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "repository:delete"},
 	Args:              cobra.MinimumNArgs(1),
 	ValidArgsFunction: deleteValidArgs,
 	PreRunE:           disableUnsupportedFlags,
@@ -23,6 +23,7 @@ type RepositoryForkCreator struct {
 var forkCmd = &cobra.Command{
 	Use:               "fork [flags] <slug_or_uuid>",
 	Short:             "fork a repository by its <slug> or <uuid>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "repository:admin"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: forkValidArgs,
 	PreRunE:           disableUnsupportedFlags,
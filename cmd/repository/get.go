@@ -15,6 +15,7 @@ var getCmd = &cobra.Command{
 	Use:               "get [flags] <slug_or_uuid>",
 	Aliases:           []string{"show", "info", "display"},
 	Short:             "get a repository by its <slug> or <uuid>. With the --forks flag, it will display the forks of the repository.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "repository"},
 	Args:              cobra.RangeArgs(0, 1),
 	ValidArgsFunction: getValidArgs,
 	PreRunE:           disableUnsupportedFlags,
@@ -24,6 +25,7 @@ var getCmd = &cobra.Command{
 var getOptions struct {
 	ShowForks bool
 	Columns   *flags.EnumSliceFlag
+	Web       bool
 }
 
 func init() {
@@ -32,6 +34,8 @@ func init() {
 	getOptions.Columns = flags.NewEnumSliceFlag(columns.Columns()...)
 	getCmd.Flags().BoolVar(&getOptions.ShowForks, "forks", false, "Show the forks of the repository")
 	getCmd.Flags().Var(getOptions.Columns, "columns", "Comma-separated list of columns to display")
+	getCmd.Flags().BoolVar(&getOptions.Web, "web", false, "Open the repository in the web browser instead of displaying it")
+	getCmd.MarkFlagsMutuallyExclusive("forks", "web")
 	_ = getCmd.RegisterFlagCompletionFunc(getOptions.Columns.CompletionFunc("columns"))
 	getCmd.SetHelpFunc(hideUnsupportedFlags)
 }
@@ -98,5 +102,8 @@ func getProcess(cmd *cobra.Command, args []string) error {
 	if !common.WhatIf(log.ToContext(cmd.Context()), cmd, fmt.Sprintf("Showing repository %s", repository.Slug)) {
 		return nil
 	}
+	if getOptions.Web {
+		return common.Browse(log.ToContext(cmd.Context()), cmd, repository.Links.HTML, false)
+	}
 	return profile.Print(cmd.Context(), cmd, repository)
 }
@@ -15,11 +15,12 @@ import (
 )
 
 var listCmd = &cobra.Command{
-	Use:     "list",
-	Short:   "list all public repositories",
-	Args:    cobra.NoArgs,
-	PreRunE: disableUnsupportedFlags,
-	RunE:    listProcess,
+	Use:         "list",
+	Short:       "list all public repositories",
+	Annotations: map[string]string{profile.ScopesAnnotation: "repository"},
+	Args:        cobra.NoArgs,
+	PreRunE:     disableUnsupportedFlags,
+	RunE:        listProcess,
 }
 
 var listOptions struct {
@@ -251,6 +251,9 @@ func GetRepositoryName(context context.Context, cmd *cobra.Command) (repositoryN
 			return
 		}
 	}
+	if repositoryName = common.GetPipelinesRepositoryFullName(); len(repositoryName) > 0 {
+		return
+	}
 	if remote, err := remote.GetRemote(context, cmd); err == nil {
 		return remote.RepositoryName(), nil
 	}
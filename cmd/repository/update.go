@@ -23,6 +23,7 @@ type RepositoryUpdator struct {
 var updateCmd = &cobra.Command{
 	Use:               "update [flags] <slug>",
 	Short:             "update a repository in a project and a workspace. The project <slug> must be unique in the workspace.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "repository:admin"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: updateValidArgs,
 	PreRunE:           disableUnsupportedFlags,
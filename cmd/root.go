@@ -8,6 +8,7 @@ import (
 
 	"github.com/gildas/bitbucket-cli/cmd/artifact"
 	"github.com/gildas/bitbucket-cli/cmd/branch"
+	"github.com/gildas/bitbucket-cli/cmd/browse"
 	"github.com/gildas/bitbucket-cli/cmd/cache"
 	"github.com/gildas/bitbucket-cli/cmd/commit"
 	"github.com/gildas/bitbucket-cli/cmd/common"
@@ -72,7 +73,7 @@ func init() {
 
 	// Global flags
 	CmdOptions.Workspace = flags.NewEnumFlagWithFunc(RootCmd, "", workspace.GetWorkspaceAllowedSlugs)
-	CmdOptions.OutputFormat = flags.EnumFlag{Allowed: []string{"csv", "json", "yaml", "table", "tsv"}, Value: core.GetEnvAsString("BB_OUTPUT_FORMAT", "")}
+	CmdOptions.OutputFormat = flags.EnumFlag{Allowed: []string{"csv", "json", "ndjson", "yaml", "table", "tsv"}, Value: core.GetEnvAsString("BB_OUTPUT_FORMAT", "")}
 	RootCmd.PersistentFlags().StringVar(&CmdOptions.ConfigFile, "config", core.GetEnvAsString("BB_CONFIG", ""), "config file (default is .env, "+filepath.Join(configDir, "bitbucket", "config-cli.yml"))
 	RootCmd.PersistentFlags().StringVarP(&CmdOptions.ProfileName, "profile", "p", core.GetEnvAsString("BB_PROFILE", ""), "Profile to use. Overrides the default profile")
 	RootCmd.PersistentFlags().Var(CmdOptions.Workspace, "workspace", "Workspace to use. Overrides the default workspace of the profile. \nBy default, the workspace is determined from the git or profile configuration")
@@ -110,6 +111,7 @@ func init() {
 	RootCmd.AddCommand(gpgkey.Command)
 	RootCmd.AddCommand(sshkey.Command)
 	RootCmd.AddCommand(cache.Command)
+	RootCmd.AddCommand(browse.Command)
 
 	RootCmd.SilenceUsage = true // Do not show usage when an error occurs
 	cobra.OnInitialize(func() {
@@ -16,6 +16,7 @@ var deleteCmd = &cobra.Command{
 	Use:               "delete [flags] <identifiers...>",
 	Aliases:           []string{"remove", "rm"},
 	Short:             "delete SSH keys by their <identifier>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "account:write"},
 	Args:              cobra.MinimumNArgs(1),
 	ValidArgsFunction: deleteValidArgs,
 	RunE:              deleteProcess,
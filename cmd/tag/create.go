@@ -14,10 +14,11 @@ import (
 )
 
 var createCmd = &cobra.Command{
-	Use:     "create [flags]",
-	Aliases: []string{"add", "new"},
-	Short:   "create a tag",
-	RunE:    createProcess,
+	Use:         "create [flags]",
+	Aliases:     []string{"add", "new"},
+	Short:       "create a tag",
+	Annotations: map[string]string{profile.ScopesAnnotation: "repository:write"},
+	RunE:        createProcess,
 }
 
 var createOptions struct {
@@ -16,6 +16,7 @@ var deleteCmd = &cobra.Command{
 	Use:               "delete [flags] <tag-name...>",
 	Aliases:           []string{"remove", "rm"},
 	Short:             "delete tags by their <tag-name>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "repository:write"},
 	Args:              cobra.MinimumNArgs(1),
 	ValidArgsFunction: deleteValidArgs,
 	RunE:              deleteProcess,
@@ -15,6 +15,7 @@ var getCmd = &cobra.Command{
 	Use:               "get [flags] <tag-name>",
 	Aliases:           []string{"show", "info", "display"},
 	Short:             "get a tag by its <tag-name>.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "repository"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: getValidArgs,
 	RunE:              getProcess,
@@ -11,11 +11,12 @@ import (
 )
 
 var getCmd = &cobra.Command{
-	Use:     "get",
-	Aliases: []string{"show", "info", "display"},
-	Short:   "get a user",
-	Args:    cobra.ExactArgs(1),
-	RunE:    getProcess,
+	Use:         "get",
+	Aliases:     []string{"show", "info", "display"},
+	Short:       "get a user",
+	Annotations: map[string]string{profile.ScopesAnnotation: "account"},
+	Args:        cobra.ExactArgs(1),
+	RunE:        getProcess,
 }
 
 var getOptions struct {
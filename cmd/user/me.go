@@ -8,11 +8,12 @@ import (
 )
 
 var meCmd = &cobra.Command{
-	Use:     "me",
-	Aliases: []string{"self"},
-	Short:   "get the current authenticated user",
-	Args:    cobra.NoArgs,
-	RunE:    meProcess,
+	Use:         "me",
+	Aliases:     []string{"self"},
+	Short:       "get the current authenticated user",
+	Annotations: map[string]string{profile.ScopesAnnotation: "account"},
+	Args:        cobra.NoArgs,
+	RunE:        meProcess,
 }
 
 var meOptions struct {
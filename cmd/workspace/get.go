@@ -15,6 +15,7 @@ var getCmd = &cobra.Command{
 	Use:               "get [flags] <workspace-slug-or-id>",
 	Aliases:           []string{"show", "info", "display"},
 	Short:             "get a workspace by its <workspace-slug-or-id> or the current workspace by default. With the --members flag, it ill display the members of the workspace. With the --member flag, it will display workspaces for the given user.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "account"},
 	Args:              cobra.RangeArgs(0, 1),
 	ValidArgsFunction: getValidArgs,
 	PreRunE:           disableUnsupportedFlags,
@@ -13,11 +13,12 @@ import (
 )
 
 var listCmd = &cobra.Command{
-	Use:     "list",
-	Short:   "list all workspaces for the current user",
-	Args:    cobra.NoArgs,
-	PreRunE: disableUnsupportedFlags,
-	RunE:    listProcess,
+	Use:         "list",
+	Short:       "list all workspaces for the current user",
+	Annotations: map[string]string{profile.ScopesAnnotation: "account"},
+	Args:        cobra.NoArgs,
+	PreRunE:     disableUnsupportedFlags,
+	RunE:        listProcess,
 }
 
 var listOptions struct {
@@ -16,6 +16,7 @@ var getCmd = &cobra.Command{
 	Use:               "get",
 	Aliases:           []string{"show", "info", "display"},
 	Short:             "get user permission on a workspace.",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "account"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: getValidArgs,
 	PreRunE:           disableUnsupportedFlags,
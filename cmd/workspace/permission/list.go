@@ -16,6 +16,7 @@ import (
 var listCmd = &cobra.Command{
 	Use:               "list",
 	Short:             "list all workspace permissions",
+	Annotations:       map[string]string{profile.ScopesAnnotation: "account"},
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: listValidArgs,
 	PreRunE:           disableUnsupportedFlags,
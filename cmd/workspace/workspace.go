@@ -132,6 +132,10 @@ func GetWorkspaceName(context context.Context, cmd *cobra.Command) (workspaceNam
 			return
 		}
 	}
+	if workspaceName = common.GetPipelinesWorkspace(); len(workspaceName) > 0 {
+		log.Debugf("Workspace name found in Bitbucket Pipelines: %s", workspaceName)
+		return
+	}
 	if remote, err := remote.GetRemote(context, cmd); err == nil {
 		log.Debugf("Workspace name found in git config: %s, from remote: %s", remote.WorkspaceName(), remote.URL)
 		return remote.WorkspaceName(), nil
@@ -147,8 +151,9 @@ func GetWorkspaceName(context context.Context, cmd *cobra.Command) (workspaceNam
 //
 // The workspace is determined by the following order:
 // 1. The workspace flag in the command
-// 2. The git config
-// 3. The default workspace in the profile
+// 2. The Bitbucket Pipelines environment
+// 3. The git config
+// 4. The default workspace in the profile
 func GetWorkspace(ctx context.Context, cmd *cobra.Command) (workspace *Workspace, err error) {
 	workspaceName, err := GetWorkspaceName(ctx, cmd)
 	if err != nil {
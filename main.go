@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/gildas/bitbucket-cli/cmd"
+	"github.com/gildas/bitbucket-cli/cmd/common"
 	"github.com/gildas/go-logger"
 	"github.com/joho/godotenv"
 )
@@ -21,6 +22,6 @@ func main() {
 	err := cmd.Execute(log.ToContext(context.Background()))
 	if err != nil {
 		log.Fatalf("Failed to execute command", err)
-		os.Exit(1)
+		os.Exit(common.GetExitCode(err))
 	}
 }